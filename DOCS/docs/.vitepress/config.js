@@ -48,7 +48,8 @@ export default defineConfig({
           items: [
             { text: 'Installation', link: '/getting-started/installation' },
             { text: 'Configuration', link: '/getting-started/configuration' },
-            { text: 'Directory Structure', link: '/getting-started/structure' }
+            { text: 'Directory Structure', link: '/getting-started/structure' },
+            { text: 'Generators', link: '/getting-started/generators' }
           ]
         },
         {
@@ -98,10 +99,18 @@ export default defineConfig({
           items: [
             { text: 'Realtime SSE', link: '/advanced/realtime-sse' },
             { text: 'Background Jobs & Queues', link: '/advanced/jobs-queues' },
+            { text: 'Task Scheduling', link: '/advanced/scheduling' },
             { text: 'Static Asset Hosting', link: '/advanced/gateway' },
             { text: 'Filesystem & Uploads', link: '/advanced/filesystem' }
           ]
         },
+        {
+          text: 'Testing',
+          collapsed: false,
+          items: [
+            { text: 'Getting Started', link: '/testing/getting-started' }
+          ]
+        },
         {
           text: 'Ecosystem',
           collapsed: false,
@@ -3,6 +3,7 @@ package app
 import (
 	"net/http"
 	"sync"
+	"github.com/nextcore/zenoengine/pkg/coverage"
 	"github.com/nextcore/zenoengine/pkg/dbmanager"
 	"github.com/nextcore/zenoengine/pkg/worker"
 
@@ -37,5 +38,16 @@ type AppContext struct {
 	Queue        worker.JobQueue
 	Env          string
 	Hot          *HotRouter
-	WorkerQueues []string
+
+	// Worker diisi oleh worker.config di main.zl
+	Worker worker.Options
+
+	// Jobs berisi job.define dari main.zl; isinya diganti setiap kali router dibangun ulang
+	Jobs *worker.Handlers
+
+	// Schedule berisi schedule.cron / schedule.every dari main.zl, diganti bersama Jobs
+	Schedule *worker.ScheduledTasks
+
+	// Coverage mencatat baris script yang dieksekusi (zeno test --coverage), nil jika tidak aktif
+	Coverage *coverage.Collector
 }
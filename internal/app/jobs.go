@@ -0,0 +1,82 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nextcore/zeno-go/pkg/engine"
+	"github.com/nextcore/zenoengine/pkg/worker"
+)
+
+// CheckJobDispatches mencari job.dispatch dengan nama handler literal di semua script dir
+// yang tidak didefinisikan lewat job.define, agar salah ketik ketahuan saat boot, bukan saat request.
+// Nama dinamis ($variabel) diperiksa saat job.dispatch dijalankan.
+func CheckJobDispatches(dir string, handlers *worker.Handlers) []engine.Diagnostic {
+	var diags []engine.Diagnostic
+	if _, err := os.Stat(dir); err != nil {
+		return nil
+	}
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || !strings.HasSuffix(path, ".zl") || strings.HasSuffix(path, ".blade.zl") {
+			return nil
+		}
+		root, err := engine.LoadScript(path)
+		if err != nil {
+			return nil // Error sintaks dilaporkan oleh validasi script
+		}
+		walkNodes(root, func(n *engine.Node) {
+			if n.Name != "job.dispatch" {
+				return
+			}
+			name, ok := literalString(n.Value)
+			if !ok {
+				return
+			}
+			if handlers == nil {
+				diags = append(diags, dispatchDiagnostic(n, path, fmt.Sprintf("job.dispatch: job handler '%s' is not defined (no job.define found)", name)))
+				return
+			}
+			if _, defined := handlers.Get(name); !defined {
+				diags = append(diags, dispatchDiagnostic(n, path, fmt.Sprintf("job.dispatch: job handler '%s' is not defined", name)))
+			}
+		})
+		return nil
+	})
+	return diags
+}
+
+func walkNodes(n *engine.Node, visit func(*engine.Node)) {
+	if n == nil {
+		return
+	}
+	visit(n)
+	for _, c := range n.Children {
+		walkNodes(c, visit)
+	}
+}
+
+// literalString mengembalikan isi string yang ditulis dengan kutip ('nama' atau "nama")
+func literalString(v interface{}) (string, bool) {
+	s, ok := v.(string)
+	if !ok {
+		return "", false
+	}
+	s = strings.TrimSpace(s)
+	if len(s) < 2 {
+		return "", false
+	}
+	if (s[0] == '\'' && s[len(s)-1] == '\'') || (s[0] == '"' && s[len(s)-1] == '"') {
+		return s[1 : len(s)-1], true
+	}
+	return "", false
+}
+
+func dispatchDiagnostic(n *engine.Node, path, message string) engine.Diagnostic {
+	filename := n.Filename
+	if filename == "" {
+		filename = path
+	}
+	return engine.Diagnostic{Type: "error", Message: message, Filename: filename, Line: n.Line, Col: n.Col}
+}
@@ -47,6 +47,7 @@ type registerConfig struct {
 	aspnet    bool
 	json      bool
 	dbHook    bool
+	factory   bool
 
 	// Extra Slots
 	mail            bool
@@ -55,6 +56,12 @@ type registerConfig struct {
 	containerBridge bool
 	queue           worker.JobQueue
 	setConfig       func([]string)
+	setOptions      func(worker.Options)
+	jobHandlers     *worker.Handlers
+	scheduledTasks  *worker.ScheduledTasks
+
+	// Testing Slots
+	test bool
 }
 
 // RegisterOption mendefinisikan tanda tangan fungsi opsi konfigurasi
@@ -105,6 +112,7 @@ func WithData(dbMgr *dbmanager.DBManager) RegisterOption {
 		c.aspnet = true
 		c.json = true
 		c.dbHook = true
+		c.factory = true
 	}
 }
 
@@ -145,6 +153,7 @@ func WithDB(dbMgr *dbmanager.DBManager) RegisterOption {
 		c.schema = true
 		c.orm = true
 		c.dbHook = true
+		c.factory = true
 	}
 }
 
@@ -188,6 +197,36 @@ func WithJob(queue worker.JobQueue, setConfig func([]string)) RegisterOption {
 	}
 }
 
+// WithJobHandlers menyimpan job.define ke registry yang dipakai bersama worker.
+// Tanpa opsi ini, handler hanya dikenal oleh engine yang mendefinisikannya.
+func WithJobHandlers(handlers *worker.Handlers) RegisterOption {
+	return func(c *registerConfig) {
+		c.jobHandlers = handlers
+	}
+}
+
+// WithScheduledTasks menyimpan schedule.cron / schedule.every ke registry yang dijalankan scheduler
+func WithScheduledTasks(tasks *worker.ScheduledTasks) RegisterOption {
+	return func(c *registerConfig) {
+		c.scheduledTasks = tasks
+	}
+}
+
+// WithWorkerConfig menerima seluruh opsi worker.config (pool, prioritas, limit per queue).
+// Bila diisi, callback setConfig dari WithExtra/WithJob tidak dipanggil.
+func WithWorkerConfig(setOptions func(worker.Options)) RegisterOption {
+	return func(c *registerConfig) {
+		c.setOptions = setOptions
+	}
+}
+
+// WithTest mengaktifkan pendaftaran slot testing (test, assert.*, call) untuk 'zeno test'
+func WithTest() RegisterOption {
+	return func(c *registerConfig) {
+		c.test = true
+	}
+}
+
 // RegisterSlots mendaftarkan slot ke Engine secara selektif berdasarkan opsi yang dipilih
 func RegisterSlots(eng *engine.Engine, opts ...RegisterOption) {
 	c := &registerConfig{}
@@ -281,6 +320,9 @@ func RegisterSlots(eng *engine.Engine, opts ...RegisterOption) {
 	if c.dbHook {
 		slots.RegisterDBHookSlots(eng)
 	}
+	if c.factory && c.dbMgr != nil {
+		slots.RegisterFactorySlots(eng, c.dbMgr)
+	}
 
 	// 4. Extra Slots
 	if c.mail {
@@ -290,11 +332,25 @@ func RegisterSlots(eng *engine.Engine, opts ...RegisterOption) {
 		slots.RegisterCacheSlots(eng, nil)
 	}
 	if c.job {
-		slots.RegisterJobSlots(eng, c.queue, c.setConfig)
+		setOptions := c.setOptions
+		if setOptions == nil && c.setConfig != nil {
+			setQueues := c.setConfig
+			setOptions = func(opts worker.Options) { setQueues(opts.Queues) }
+		}
+		slots.RegisterJobSlots(eng, c.queue, setOptions, c.jobHandlers)
+		slots.RegisterScheduleSlots(eng, c.scheduledTasks)
 	}
 	if c.containerBridge && c.routerMux != nil {
 		slots.RegisterContainerBridgeSlots(eng, c.routerMux)
 	}
+
+	// 5. Testing Slots
+	if c.test {
+		slots.RegisterTestSlots(eng)
+	}
+	if c.test && c.dbMgr != nil {
+		slots.RegisterTestDBSlots(eng, c.dbMgr)
+	}
 }
 
 // RegisterAllSlots membungkus pendaftaran seluruh slot yang tersedia di ZenoEngine (Backward Compatibility)
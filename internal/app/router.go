@@ -15,6 +15,7 @@ import (
 	"github.com/nextcore/zenoengine/pkg/logger"
 	"github.com/nextcore/zenoengine/pkg/metrics"
 	"github.com/nextcore/zenoengine/pkg/middleware"
+	"github.com/nextcore/zenoengine/pkg/worker"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/cors"
@@ -152,11 +153,26 @@ func BuildRouter(app *AppContext) (*chi.Mux, error) {
 	r.Handle("/metrics", promhttp.Handler())
 
 	// 4. Update signatures
+	// job.define dan tugas terjadwal dikumpulkan di registry baru; baru dipakai worker
+	// dan scheduler bila main.zl berhasil dijalankan
+	jobs := worker.NewHandlers()
+	schedule := worker.NewScheduledTasks()
 	eng := engine.NewEngine()
-	RegisterAllSlots(eng, r, app.DBMgr, app.Queue, func(queues []string) {
-		app.WorkerQueues = queues
-		slog.Info("🔧 Worker Configuration Updated", "queues", queues)
-	})
+	RegisterSlots(eng,
+		WithCore(),
+		WithWeb(r),
+		WithData(app.DBMgr),
+		WithExtra(app.Queue, nil),
+		WithWorkerConfig(func(opts worker.Options) {
+			app.Worker = opts
+			slog.Info("🔧 Worker Configuration Updated", "queues", opts.Queues, "concurrency", opts.Concurrency, "priority", opts.Priority)
+		}),
+		WithJobHandlers(jobs),
+		WithScheduledTasks(schedule),
+	)
+	if app.Coverage != nil {
+		app.Coverage.Instrument(eng)
+	}
 
 	// Static Files
 	workDir, _ := os.Getwd()
@@ -203,5 +219,16 @@ func BuildRouter(app *AppContext) (*chi.Mux, error) {
 		return nil, fmt.Errorf("execution error: %v", err)
 	}
 
+	if app.Jobs == nil {
+		app.Jobs = jobs
+	} else {
+		app.Jobs.Replace(jobs)
+	}
+	if app.Schedule == nil {
+		app.Schedule = schedule
+	} else {
+		app.Schedule.Replace(schedule)
+	}
+
 	return r, nil
 }
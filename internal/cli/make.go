@@ -0,0 +1,264 @@
+package cli
+
+import (
+	"bytes"
+	"embed"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+//go:embed stubs/*.stub
+var builtinStubs embed.FS
+
+// stubDir is the project directory whose stubs override the built-in ones
+const stubDir = "stubs"
+
+// stubData is passed to every generator template
+type stubData struct {
+	Name   string // Nama seperti diketik user, mis. "SendWelcomeEmail"
+	Snake  string // send_welcome_email
+	Title  string // Send Welcome Email
+	Table  string // Nama tabel (jamak), mis. "users"
+	Prefix string // URL prefix untuk make:route
+	Queue  string // Nama queue untuk make:job
+	Path   string // Lokasi file yang dibuat
+	// Fields adalah kolom yang ditulis oleh handler STORE/UPDATE make:route
+	Fields []string
+}
+
+// renderStub renders stubs/<name>.stub from the project, falling back to the built-in template.
+// Templates use [[ ]] delimiters so Blade's {{ }} can be written as-is.
+func renderStub(name string, data stubData) (string, error) {
+	file := name + ".stub"
+	content, err := os.ReadFile(filepath.Join(stubDir, file))
+	if err != nil {
+		if content, err = builtinStubs.ReadFile("stubs/" + file); err != nil {
+			return "", fmt.Errorf("stub '%s' not found", file)
+		}
+	}
+
+	tmpl, err := template.New(file).Delims("[[", "]]").Parse(string(content))
+	if err != nil {
+		return "", fmt.Errorf("invalid stub '%s': %w", file, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render stub '%s': %w", file, err)
+	}
+	return buf.String(), nil
+}
+
+// generateFile renders a stub into path, refusing to overwrite an existing file unless force is set
+func generateFile(stub, path string, data stubData, force bool) {
+	if _, err := os.Stat(path); err == nil && !force {
+		fmt.Printf("❌ %s already exists (use --force to overwrite)\n", path)
+		os.Exit(1)
+	}
+
+	data.Path = filepath.ToSlash(path)
+	content, err := renderStub(stub, data)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		fmt.Printf("❌ Failed to create directory: %v\n", err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		fmt.Printf("❌ Failed to write %s: %v\n", path, err)
+		os.Exit(1)
+	}
+	fmt.Printf("✅ Created: %s\n", path)
+}
+
+// parseMakeArgs parses the flags of a make:* command and returns the name argument,
+// which may be written before or after the flags
+func parseMakeArgs(fs *flag.FlagSet, args []string, usage string) string {
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		fmt.Printf("❌ Usage: zeno %s\n", usage)
+		os.Exit(1)
+	}
+	name := fs.Arg(0)
+	fs.Parse(fs.Args()[1:])
+	return name
+}
+
+func newStubData(name string) stubData {
+	snake := snakeCase(name)
+	return stubData{
+		Name:  name,
+		Snake: snake,
+		Title: titleCase(snake),
+		Table: pluralize(snake),
+	}
+}
+
+// snakeCase turns "SendWelcomeEmail", "send-welcome email" or "sendWelcomeEmail" into "send_welcome_email"
+func snakeCase(name string) string {
+	var sb strings.Builder
+	runes := []rune(strings.TrimSpace(name))
+	for i, r := range runes {
+		switch {
+		case unicode.IsUpper(r):
+			if i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+				(i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))) {
+				sb.WriteByte('_')
+			}
+			sb.WriteRune(unicode.ToLower(r))
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			sb.WriteRune(r)
+		default:
+			if sb.Len() > 0 && !strings.HasSuffix(sb.String(), "_") {
+				sb.WriteByte('_')
+			}
+		}
+	}
+	return strings.Trim(sb.String(), "_")
+}
+
+// titleCase turns "send_welcome_email" into "Send Welcome Email"
+func titleCase(snake string) string {
+	words := strings.Split(snake, "_")
+	for i, w := range words {
+		if w != "" {
+			words[i] = strings.ToUpper(w[:1]) + w[1:]
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// pluralize applies the common English plural rules to the last word of a snake_case name
+func pluralize(word string) string {
+	switch {
+	case word == "":
+		return word
+	case strings.HasSuffix(word, "s") || strings.HasSuffix(word, "x") || strings.HasSuffix(word, "z") ||
+		strings.HasSuffix(word, "ch") || strings.HasSuffix(word, "sh"):
+		return word + "es"
+	case strings.HasSuffix(word, "y") && len(word) > 1 && !strings.ContainsRune("aeiou", rune(word[len(word)-2])):
+		return word[:len(word)-1] + "ies"
+	}
+	return word + "s"
+}
+
+// HandleMakeModel creates an orm.model definition in src/models
+func HandleMakeModel(args []string) {
+	fs := flag.NewFlagSet("make:model", flag.ExitOnError)
+	dir := fs.String("dir", filepath.Join("src", "models"), "Directory for the model file")
+	table := fs.String("table", "", "Table name (Default: plural of the model name)")
+	migration := fs.Bool("migration", false, "Also create a create_<table>_table migration")
+	force := fs.Bool("force", false, "Overwrite an existing file")
+	name := parseMakeArgs(fs, args, "make:model <Name> [--table=] [--migration]")
+
+	data := newStubData(name)
+	if *table != "" {
+		data.Table = *table
+	}
+	generateFile("model", filepath.Join(*dir, data.Snake+".zl"), data, *force)
+
+	if *migration {
+		migrationDir := defaultMigrationDir()
+		generateFile("migration.create", newMigrationPath(migrationDir, "create_"+data.Table+"_table"), data, *force)
+	}
+}
+
+// HandleMakeRoute creates a resource route module in src/modules/<name>/routes.zl
+func HandleMakeRoute(args []string) {
+	fs := flag.NewFlagSet("make:route", flag.ExitOnError)
+	dir := fs.String("dir", filepath.Join("src", "modules"), "Directory containing the route modules")
+	prefix := fs.String("prefix", "", "URL prefix (Default: /<name>)")
+	table := fs.String("table", "", "Table used by the generated handlers (Default: <name>)")
+	fields := fs.String("fields", "name", "Comma separated columns the store and update handlers accept from the request body")
+	force := fs.Bool("force", false, "Overwrite an existing file")
+	name := parseMakeArgs(fs, args, "make:route <name> [--prefix=/api/<name>] [--table=] [--fields=title,body]")
+
+	data := newStubData(name)
+	data.Title = strings.ToUpper(data.Title)
+	data.Table = data.Snake
+	data.Prefix = "/" + data.Snake
+	if *table != "" {
+		data.Table = *table
+	}
+	if *prefix != "" {
+		data.Prefix = "/" + strings.Trim(*prefix, "/")
+	}
+	for _, f := range strings.Split(*fields, ",") {
+		if f = snakeCase(f); f != "" {
+			data.Fields = append(data.Fields, f)
+		}
+	}
+	if len(data.Fields) == 0 {
+		fmt.Println("❌ --fields must name at least one column")
+		os.Exit(1)
+	}
+
+	path := filepath.Join(*dir, data.Snake, "routes.zl")
+	generateFile("route", path, data, *force)
+	fmt.Printf("💡 Register it in your main script: include: %s\n", filepath.ToSlash(path))
+}
+
+// HandleMakeView creates a Blade view in views/; dots or slashes in the name create subdirectories
+func HandleMakeView(args []string) {
+	fs := flag.NewFlagSet("make:view", flag.ExitOnError)
+	dir := fs.String("dir", "views", "Views directory")
+	force := fs.Bool("force", false, "Overwrite an existing file")
+	name := parseMakeArgs(fs, args, "make:view <name> (e.g. teams.index)")
+
+	name = strings.TrimSuffix(strings.TrimSuffix(name, ".zl"), ".blade")
+	parts := strings.FieldsFunc(name, func(r rune) bool { return r == '.' || r == '/' || r == '\\' })
+	if len(parts) == 0 {
+		fmt.Println("❌ Invalid view name")
+		os.Exit(1)
+	}
+
+	data := newStubData(parts[len(parts)-1])
+	data.Name = strings.Join(parts, ".")
+	generateFile("view", filepath.Join(*dir, filepath.Join(parts...)+".blade.zl"), data, *force)
+}
+
+// HandleMakeJob creates a job script in src/jobs
+func HandleMakeJob(args []string) {
+	fs := flag.NewFlagSet("make:job", flag.ExitOnError)
+	dir := fs.String("dir", filepath.Join("src", "jobs"), "Directory for the job script")
+	queue := fs.String("queue", "default", "Queue the job is meant for")
+	force := fs.Bool("force", false, "Overwrite an existing file")
+	name := parseMakeArgs(fs, args, "make:job <Name> [--queue=]")
+
+	data := newStubData(name)
+	data.Queue = *queue
+	generateFile("job", filepath.Join(*dir, data.Snake+".zl"), data, *force)
+}
+
+// HandleStubPublish copies the built-in templates to stubs/ so the project can customize them
+func HandleStubPublish(args []string) {
+	fs := flag.NewFlagSet("stub:publish", flag.ExitOnError)
+	force := fs.Bool("force", false, "Overwrite stubs that were already published")
+	fs.Parse(args)
+
+	entries, _ := builtinStubs.ReadDir("stubs")
+	if err := os.MkdirAll(stubDir, 0755); err != nil {
+		fmt.Printf("❌ Failed to create %s: %v\n", stubDir, err)
+		os.Exit(1)
+	}
+	for _, e := range entries {
+		target := filepath.Join(stubDir, e.Name())
+		if _, err := os.Stat(target); err == nil && !*force {
+			fmt.Printf("⏭️  Skipped (exists): %s\n", target)
+			continue
+		}
+		content, _ := builtinStubs.ReadFile("stubs/" + e.Name())
+		if err := os.WriteFile(target, content, 0644); err != nil {
+			fmt.Printf("❌ Failed to write %s: %v\n", target, err)
+			os.Exit(1)
+		}
+		fmt.Printf("✅ Published: %s\n", target)
+	}
+}
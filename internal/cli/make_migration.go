@@ -0,0 +1,240 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nextcore/zeno-go/pkg/engine"
+	"github.com/nextcore/zenoengine/internal/app"
+	"github.com/nextcore/zenoengine/internal/slots"
+	"github.com/nextcore/zenoengine/pkg/dbmanager"
+	"github.com/nextcore/zenoengine/pkg/logger"
+
+	"github.com/joho/godotenv"
+)
+
+// modelSkipDirs are never scanned for orm.model definitions
+var modelSkipDirs = map[string]bool{
+	"node_modules": true,
+	"vendor":       true,
+	"public":       true,
+	"storage":      true,
+	"database":     true,
+	"migrations":   true,
+	"tests":        true,
+}
+
+// HandleMakeMigration creates a new migration file from the migration stubs. With --auto the up
+// and down sections are generated by diffing the orm.model column definitions against the database.
+func HandleMakeMigration(args []string) {
+	fs := flag.NewFlagSet("make:migration", flag.ExitOnError)
+	auto := fs.Bool("auto", false, "Generate the migration by comparing orm.model columns with the database")
+	conn := fs.String("db", "default", "Database connection name (used with --auto)")
+	dir := fs.String("path", "", "Migration directory (Default: migrations or database/migrations)")
+	models := fs.String("models", ".", "Comma separated directories scanned for orm.model definitions (used with --auto)")
+	dropColumns := fs.Bool("drop-columns", false, "Drop columns that exist in the database but not in the model (used with --auto)")
+	create := fs.String("create", "", "Table to create (Default: guessed from create_<table>_table)")
+	table := fs.String("table", "", "Table to alter (Default: guessed from add_<x>_to_<table>)")
+	fs.Parse(args)
+
+	// Nama migrasi boleh ditulis sebelum atau sesudah flag
+	name := ""
+	if fs.NArg() > 0 {
+		name = fs.Arg(0)
+		fs.Parse(fs.Args()[1:])
+	}
+	if name == "" {
+		if !*auto {
+			fmt.Println("❌ Usage: zeno make:migration <name> [--create=table|--table=table|--auto]")
+			os.Exit(1)
+		}
+		name = "auto_update_schema"
+	}
+	if *dir == "" {
+		*dir = defaultMigrationDir()
+	}
+	path := newMigrationPath(*dir, name)
+
+	if !*auto {
+		data := newStubData(name)
+		stub := "migration"
+		if *create == "" && *table == "" {
+			*create, *table = guessMigrationTable(data.Snake)
+		}
+		if *create != "" {
+			stub, data.Table = "migration.create", *create
+		} else if *table != "" {
+			stub, data.Table = "migration.update", *table
+		}
+		generateFile(stub, path, data, false)
+		return
+	}
+
+	content, changed := autoMigration(*conn, strings.Split(*models, ","), *dropColumns)
+	if changed == 0 {
+		fmt.Println("✨ Models and database are in sync, no migration created.")
+		return
+	}
+	if err := os.MkdirAll(*dir, 0755); err != nil {
+		fmt.Printf("❌ Failed to create migration directory: %v\n", err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		fmt.Printf("❌ Failed to write migration: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("✅ Created migration: %s\n", path)
+}
+
+var (
+	createTablePattern = regexp.MustCompile(`^create_(\w+?)_table$`)
+	alterTablePattern  = regexp.MustCompile(`_(?:to|from|in)_(\w+?)(?:_table)?$`)
+)
+
+// guessMigrationTable derives the table from conventional names:
+// create_posts_table creates "posts", add_phone_to_users alters "users"
+func guessMigrationTable(snake string) (create, alter string) {
+	if m := createTablePattern.FindStringSubmatch(snake); m != nil {
+		return m[1], ""
+	}
+	if m := alterTablePattern.FindStringSubmatch(snake); m != nil {
+		return "", m[1]
+	}
+	return "", ""
+}
+
+// autoMigration renders a migration for every model whose table differs from the database.
+// Columns missing from a model are reported, and only dropped with dropColumns.
+func autoMigration(conn string, roots []string, dropColumns bool) (string, int) {
+	godotenv.Load()
+	logger.Setup("development")
+
+	dbMgr, err := app.ConnectDatabases()
+	if err != nil {
+		fmt.Printf("❌ Fatal: DB Connection Failed: %v\n", err)
+		os.Exit(1)
+	}
+	db := dbMgr.GetConnection(conn)
+	if db == nil {
+		fmt.Printf("❌ Database connection '%s' not found\n", conn)
+		os.Exit(1)
+	}
+	dialect := dbMgr.GetDialect(conn)
+
+	eng := engine.NewEngine()
+	app.RegisterSlots(eng, app.WithCore(), app.WithData(dbMgr))
+
+	ctx := context.Background()
+	var models []*slots.SchemaState
+	for _, file := range modelFiles(roots) {
+		root, err := engine.LoadScript(file)
+		if err != nil {
+			fmt.Printf("⚠️  Skipping %s: %v\n", file, err)
+			continue
+		}
+		found, err := slots.ModelSchemas(ctx, eng, root, dialect)
+		if err != nil {
+			fmt.Printf("❌ %s: %v\n", file, err)
+			os.Exit(1)
+		}
+		for _, m := range found {
+			if m.DBName == conn {
+				models = append(models, m)
+			}
+		}
+	}
+	if len(models) == 0 {
+		fmt.Println("❌ No orm.model with a 'columns' block found")
+		os.Exit(1)
+	}
+
+	tables, err := dbmanager.ListTables(ctx, db, dialect)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	existing := make(map[string]bool, len(tables))
+	for _, t := range tables {
+		existing[t] = true
+	}
+
+	var diffs []slots.SchemaDiff
+	for _, m := range slots.SortByForeignKeys(models) {
+		var live *dbmanager.TableSchema
+		if existing[m.Table] {
+			if live, err = dbmanager.DescribeTable(ctx, db, dialect, m.Table); err != nil {
+				fmt.Printf("❌ %v\n", err)
+				os.Exit(1)
+			}
+		}
+		d := m.Diff(live, dropColumns)
+		for _, col := range d.Kept {
+			fmt.Printf("⚠️  %s.%s is not in the model and was kept; pass --drop-columns to drop it\n", m.Table, col)
+		}
+		if !d.Empty() {
+			fmt.Printf("📝 %s: %d change(s)\n", m.Table, len(d.Up))
+			diffs = append(diffs, d)
+		}
+	}
+	return slots.RenderMigration(diffs), len(diffs)
+}
+
+// modelFiles lists the .zl scripts (without Blade views) below the given directories
+func modelFiles(roots []string) []string {
+	var files []string
+	for _, root := range roots {
+		root = strings.TrimSpace(root)
+		filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return nil
+			}
+			if info.IsDir() {
+				base := info.Name()
+				if path != root && (strings.HasPrefix(base, ".") || modelSkipDirs[base]) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if strings.HasSuffix(path, ".zl") && !strings.HasSuffix(path, ".blade.zl") {
+				files = append(files, path)
+			}
+			return nil
+		})
+	}
+	return files
+}
+
+var migrationPrefixPattern = regexp.MustCompile(`^(\d+)_`)
+
+// newMigrationPath returns <dir>/<prefix>_<name>.zl. The prefix is a timestamp
+// (20240101120000), unless the directory already numbers its migrations
+// sequentially (001_, 002_), in which case the sequence is continued.
+func newMigrationPath(dir, name string) string {
+	entries, _ := os.ReadDir(dir)
+	prefix := time.Now().Format("20060102150405")
+	maxSeq := 0
+	for _, e := range entries {
+		m := migrationPrefixPattern.FindStringSubmatch(e.Name())
+		if m == nil {
+			continue
+		}
+		if len(m[1]) >= 8 {
+			maxSeq = -1 // Sudah memakai timestamp
+			break
+		}
+		if n, _ := strconv.Atoi(m[1]); n > maxSeq {
+			maxSeq = n
+		}
+	}
+	if maxSeq > 0 {
+		prefix = fmt.Sprintf("%03d", maxSeq+1)
+	}
+	return filepath.Join(dir, fmt.Sprintf("%s_%s.zl", prefix, snakeCase(strings.TrimSuffix(name, ".zl"))))
+}
@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnakeCase(t *testing.T) {
+	cases := map[string]string{
+		"SendWelcomeEmail":    "send_welcome_email",
+		"sendWelcomeEmail":    "send_welcome_email",
+		"send-welcome email":  "send_welcome_email",
+		"HTTPServer":          "http_server",
+		"user":                "user",
+		"  Blog Post  ":       "blog_post",
+		"create_posts_table":  "create_posts_table",
+		"add phone to--users": "add_phone_to_users",
+	}
+	for in, want := range cases {
+		assert.Equal(t, want, snakeCase(in), in)
+	}
+}
+
+func TestPluralize(t *testing.T) {
+	cases := map[string]string{
+		"post":      "posts",
+		"blog_post": "blog_posts",
+		"category":  "categories",
+		"day":       "days",
+		"box":       "boxes",
+		"status":    "statuses",
+		"branch":    "branches",
+		"wish":      "wishes",
+		"":          "",
+	}
+	for in, want := range cases {
+		assert.Equal(t, want, pluralize(in), in)
+	}
+}
+
+func TestGuessMigrationTable(t *testing.T) {
+	cases := []struct {
+		name, create, alter string
+	}{
+		{"create_posts_table", "posts", ""},
+		{"create_blog_posts_table", "blog_posts", ""},
+		{"add_phone_to_users", "", "users"},
+		{"add_phone_to_users_table", "", "users"},
+		{"remove_legacy_from_orders", "", "orders"},
+		{"backfill_slugs", "", ""},
+	}
+	for _, c := range cases {
+		create, alter := guessMigrationTable(c.name)
+		assert.Equal(t, c.create, create, c.name)
+		assert.Equal(t, c.alter, alter, c.name)
+	}
+}
+
+func TestNewMigrationPath(t *testing.T) {
+	// Direktori kosong (atau belum ada) memakai timestamp
+	dir := t.TempDir()
+	path := newMigrationPath(filepath.Join(dir, "missing"), "CreatePostsTable")
+	base := filepath.Base(path)
+	assert.Regexp(t, `^\d{14}_create_posts_table\.zl$`, base)
+
+	// Penomoran berurutan dilanjutkan
+	for _, f := range []string{"001_create_users.zl", "002_create_posts.zl", "README.md"} {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, f), nil, 0644))
+	}
+	assert.Equal(t, filepath.Join(dir, "003_add_phone_to_users.zl"), newMigrationPath(dir, "add_phone_to_users.zl"))
+
+	// Satu file bertimestamp membuat direktori memakai timestamp
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "20260101120000_create_tags.zl"), nil, 0644))
+	assert.Regexp(t, `^\d{14}_add_slug\.zl$`, filepath.Base(newMigrationPath(dir, "add_slug")))
+}
+
+func TestRouteStub(t *testing.T) {
+	// Tanpa stubs/ di direktori kerja, template bawaan yang dipakai
+	t.Chdir(t.TempDir())
+	data := newStubData("tasks")
+	data.Prefix = "/tasks"
+	data.Table = "tasks"
+	data.Fields = []string{"title", "status"}
+	out, err := renderStub("route", data)
+	assert.NoError(t, err)
+
+	// STORE dan UPDATE hanya menulis kolom yang disebutkan
+	assert.Contains(t, out, "db.insert: {\n            title: $input.title\n            status: $input.status\n        }")
+	assert.Contains(t, out, "db.update: {\n            title: $input.title\n            status: $input.status\n        }")
+	assert.False(t, strings.Contains(out, "db.insert: $input"))
+	assert.False(t, strings.Contains(out, "db.update: $input"))
+}
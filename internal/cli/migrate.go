@@ -0,0 +1,242 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/nextcore/zeno-go/pkg/engine"
+	"github.com/nextcore/zenoengine/internal/app"
+	"github.com/nextcore/zenoengine/pkg/dbmanager"
+	"github.com/nextcore/zenoengine/pkg/logger"
+	"github.com/nextcore/zenoengine/pkg/migrator"
+
+	"github.com/joho/godotenv"
+)
+
+// migrateOptions holds the flags shared by every migrate:* command
+type migrateOptions struct {
+	conn    string
+	dir     string
+	step    int
+	force   bool
+	pretend bool
+}
+
+func parseMigrateFlags(name string, args []string) migrateOptions {
+	var opts migrateOptions
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.StringVar(&opts.conn, "db", "default", "Database connection name")
+	fs.StringVar(&opts.dir, "path", "", "Migration directory (Default: migrations or database/migrations)")
+	if name == "migrate:rollback" {
+		fs.IntVar(&opts.step, "step", 1, "Number of batches to roll back")
+	}
+	if name == "migrate" || name == "migrate:rollback" {
+		fs.BoolVar(&opts.pretend, "pretend", false, "Print the SQL that would run without executing it")
+	}
+	if name == "migrate:fresh" {
+		fs.BoolVar(&opts.force, "force", false, "Allow running in production")
+	}
+	fs.Parse(args)
+
+	if opts.dir == "" {
+		opts.dir = defaultMigrationDir()
+	}
+	return opts
+}
+
+// defaultMigrationDir supports both the flat (migrations/) and the template (database/migrations/) layout
+func defaultMigrationDir() string {
+	if _, err := os.Stat("migrations"); err == nil {
+		return "migrations"
+	}
+	if _, err := os.Stat("database/migrations"); err == nil {
+		return "database/migrations"
+	}
+	return "migrations"
+}
+
+// newMigrator boots the databases and an engine with the data slots required by migration scripts
+func newMigrator(opts migrateOptions) *migrator.Migrator {
+	godotenv.Load()
+	logger.Setup("development")
+
+	dbMgr, err := app.ConnectDatabases()
+	if err != nil {
+		fmt.Printf("❌ Fatal: DB Connection Failed: %v\n", err)
+		os.Exit(1)
+	}
+
+	eng := engine.NewEngine()
+	app.RegisterSlots(eng, app.WithCore(), app.WithData(dbMgr))
+
+	m, err := migrator.NewForConnection(eng, dbMgr, opts.conn, opts.dir)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	m.Pretend = opts.pretend
+	m.SchemaFile = existingSchemaDump(opts.conn)
+	return m
+}
+
+// existingSchemaDump returns the schema dump written by schema:dump for the connection, if any
+func existingSchemaDump(conn string) string {
+	for _, format := range []string{"zl", "sql"} {
+		path := migrator.SchemaDumpFile(conn, format)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// printPretended prints the statements captured in pretend mode, grouped by migration file
+func printPretended(m *migrator.Migrator) {
+	if !m.Pretend {
+		return
+	}
+	if len(m.Statements) == 0 {
+		fmt.Println("-- No SQL statements would be executed")
+		return
+	}
+
+	lastFile := ""
+	for _, st := range m.Statements {
+		if st.File != lastFile {
+			fmt.Printf("\n-- %s [%s, %s]\n", st.File, st.Connection, m.Dialect.Name())
+			lastFile = st.File
+		}
+		query := strings.TrimSpace(st.SQL)
+		if !strings.HasSuffix(query, ";") {
+			query += ";"
+		}
+		fmt.Println(query)
+		if len(st.Args) > 0 {
+			fmt.Printf("-- bindings: %v\n", st.Args)
+		}
+	}
+}
+
+// HandleMigrate applies all pending migrations
+func HandleMigrate(args []string) {
+	opts := parseMigrateFlags("migrate", args)
+	m := newMigrator(opts)
+
+	if err := m.Run(); err != nil {
+		fmt.Printf("❌ Migration Failed: %v\n", err)
+		os.Exit(1)
+	}
+	printPretended(m)
+}
+
+// HandleMigrateRollback rolls back the last N migration batches
+func HandleMigrateRollback(args []string) {
+	opts := parseMigrateFlags("migrate:rollback", args)
+	m := newMigrator(opts)
+
+	if err := m.Rollback(opts.step); err != nil {
+		fmt.Printf("❌ Rollback Failed: %v\n", err)
+		os.Exit(1)
+	}
+	printPretended(m)
+}
+
+// HandleMigrateStatus prints applied and pending migrations as a table
+func HandleMigrateStatus(args []string) {
+	opts := parseMigrateFlags("migrate:status", args)
+	m := newMigrator(opts)
+
+	statuses, err := m.Status()
+	if err != nil {
+		fmt.Printf("❌ Failed to read migration status: %v\n", err)
+		os.Exit(1)
+	}
+
+	if len(statuses) == 0 {
+		fmt.Printf("No migrations found in %s\n", opts.dir)
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "STATUS\tBATCH\tMIGRATION\tAPPLIED AT\tDRIFT")
+	fmt.Fprintln(w, strings.Repeat("-", 7)+"\t"+strings.Repeat("-", 5)+"\t"+strings.Repeat("-", 9)+"\t"+strings.Repeat("-", 10)+"\t"+strings.Repeat("-", 5))
+	drifted := 0
+	for _, st := range statuses {
+		if st.Drifted() {
+			drifted++
+		}
+		switch {
+		case st.Missing:
+			fmt.Fprintf(w, "Missing\t%d\t%s\t%s\t%s\n", st.Batch, st.File, st.AppliedAt, driftLabel(st))
+		case st.Applied:
+			fmt.Fprintf(w, "Ran\t%d\t%s\t%s\t%s\n", st.Batch, st.File, st.AppliedAt, driftLabel(st))
+		default:
+			fmt.Fprintf(w, "Pending\t-\t%s\t-\t%s\n", st.File, driftLabel(st))
+		}
+	}
+	w.Flush()
+
+	if drifted > 0 {
+		fmt.Printf("\n⚠️  %d migration(s) drifted from what was applied\n", drifted)
+	}
+}
+
+// driftLabel describes why a migration is flagged in migrate:status
+func driftLabel(st migrator.MigrationStatus) string {
+	var labels []string
+	if st.Modified {
+		labels = append(labels, "modified")
+	}
+	if st.Missing {
+		labels = append(labels, "file missing")
+	}
+	if st.OutOfOrder {
+		labels = append(labels, "out of order")
+	}
+	if len(labels) == 0 {
+		return "-"
+	}
+	return strings.Join(labels, ", ")
+}
+
+// CheckMigrationDrift returns an error when applied migrations on the default
+// connection were modified, removed or applied out of order
+func CheckMigrationDrift(dbMgr *dbmanager.DBManager) error {
+	m := migrator.New(nil, dbMgr, defaultMigrationDir())
+	if _, err := os.Stat(m.Dir); err != nil {
+		return nil // Project tanpa migrasi
+	}
+
+	drifted, err := m.Drift()
+	if err != nil {
+		return err
+	}
+	if len(drifted) == 0 {
+		return nil
+	}
+
+	var files []string
+	for _, st := range drifted {
+		files = append(files, fmt.Sprintf("%s (%s)", st.File, driftLabel(st)))
+	}
+	return fmt.Errorf("migration drift detected: %s", strings.Join(files, "; "))
+}
+
+// HandleMigrateFresh drops every table on the connection and re-runs all migrations
+func HandleMigrateFresh(args []string) {
+	opts := parseMigrateFlags("migrate:fresh", args)
+	m := newMigrator(opts)
+
+	if os.Getenv("APP_ENV") == "production" && !opts.force {
+		fmt.Println("❌ migrate:fresh drops every table. Use --force to run it when APP_ENV=production")
+		os.Exit(1)
+	}
+
+	if err := m.Fresh(); err != nil {
+		fmt.Printf("❌ Fresh Migration Failed: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,205 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/nextcore/zenoengine/internal/app"
+	"github.com/nextcore/zenoengine/pkg/logger"
+	"github.com/nextcore/zenoengine/pkg/worker"
+
+	"github.com/joho/godotenv"
+)
+
+// openQueue connects the databases and returns the queue stored in the queue connection (QUEUE_CONNECTION, default internal)
+func openQueue() (*worker.DBQueue, func()) {
+	godotenv.Load()
+	logger.Setup("development")
+
+	dbMgr, err := app.ConnectDatabases()
+	if err != nil {
+		fmt.Printf("❌ Fatal: DB Connection Failed: %v\n", err)
+		os.Exit(1)
+	}
+	return worker.NewDBQueue(dbMgr, worker.QueueConnection()), func() { dbMgr.Close() }
+}
+
+// HandleQueueFailed lists the jobs in failed_jobs
+func HandleQueueFailed(args []string) {
+	fs := flag.NewFlagSet("queue:failed", flag.ExitOnError)
+	queueName := fs.String("queue", "", "Only show failed jobs of this queue")
+	asJSON := fs.Bool("json", false, "Print the failed jobs as JSON, including payload and stack")
+	fs.Parse(args)
+
+	queue, closeDB := openQueue()
+	defer closeDB()
+
+	failed, err := queue.FailedJobs(context.Background(), *queueName)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	if *asJSON {
+		if failed == nil {
+			failed = []worker.FailedJob{}
+		}
+		out, _ := json.MarshalIndent(failed, "", "  ")
+		fmt.Println(string(out))
+		return
+	}
+
+	if len(failed) == 0 {
+		fmt.Println("No failed jobs.")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tQUEUE\tJOB\tATTEMPTS\tFAILED AT\tERROR")
+	for _, f := range failed {
+		var payload worker.JobPayload
+		json.Unmarshal([]byte(f.Payload), &payload)
+		failedAt := ""
+		if !f.FailedAt.IsZero() {
+			failedAt = f.FailedAt.Local().Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%s\t%s\n", f.ID, f.Queue, payload.Name(), f.Attempts, failedAt, firstLine(f.Error, 80))
+	}
+	w.Flush()
+	fmt.Printf("\n%d failed job(s)\n", len(failed))
+}
+
+func firstLine(s string, max int) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[:i]
+	}
+	if len(s) > max {
+		s = s[:max-3] + "..."
+	}
+	return s
+}
+
+// parseJobIDs reads the positional job IDs (space or comma separated); "all" returns nil, meaning every job
+func parseJobIDs(usage string, args []string) []int64 {
+	if len(args) == 0 {
+		fmt.Println("Usage: zeno " + usage)
+		os.Exit(1)
+	}
+	if len(args) == 1 && args[0] == "all" {
+		return nil
+	}
+
+	ids := make([]int64, 0, len(args))
+	for _, a := range args {
+		for _, part := range strings.Split(a, ",") {
+			id, err := strconv.ParseInt(strings.TrimSpace(part), 10, 64)
+			if err != nil {
+				fmt.Printf("❌ Invalid job ID '%s'\n", part)
+				os.Exit(1)
+			}
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// HandleQueueRetry pushes failed jobs back onto their queue with fresh attempts
+func HandleQueueRetry(args []string) {
+	fs := flag.NewFlagSet("queue:retry", flag.ExitOnError)
+	fs.Parse(args)
+	ids := parseJobIDs("queue:retry <id>... | all", fs.Args())
+
+	queue, closeDB := openQueue()
+	defer closeDB()
+
+	n, err := queue.RetryFailed(context.Background(), ids)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	if n < len(ids) {
+		fmt.Printf("⚠️  %d of %d job(s) were not found in failed_jobs\n", len(ids)-n, len(ids))
+	}
+	fmt.Printf("✅ %d failed job(s) pushed back onto the queue\n", n)
+}
+
+// HandleQueueForget deletes one failed job
+func HandleQueueForget(args []string) {
+	fs := flag.NewFlagSet("queue:forget", flag.ExitOnError)
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fmt.Println("Usage: zeno queue:forget <id>")
+		os.Exit(1)
+	}
+	id, err := strconv.ParseInt(fs.Arg(0), 10, 64)
+	if err != nil {
+		fmt.Printf("❌ Invalid job ID '%s'\n", fs.Arg(0))
+		os.Exit(1)
+	}
+
+	queue, closeDB := openQueue()
+	defer closeDB()
+
+	found, err := queue.ForgetFailed(context.Background(), id)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	if !found {
+		fmt.Printf("❌ Failed job %d not found\n", id)
+		os.Exit(1)
+	}
+	fmt.Printf("✅ Failed job %d deleted\n", id)
+}
+
+// HandleQueueCancel cancels a queued or running job
+func HandleQueueCancel(args []string) {
+	fs := flag.NewFlagSet("queue:cancel", flag.ExitOnError)
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fmt.Println("Usage: zeno queue:cancel <id>")
+		os.Exit(1)
+	}
+	id, err := strconv.ParseInt(fs.Arg(0), 10, 64)
+	if err != nil {
+		fmt.Printf("❌ Invalid job ID '%s'\n", fs.Arg(0))
+		os.Exit(1)
+	}
+
+	queue, closeDB := openQueue()
+	defer closeDB()
+
+	found, err := queue.Cancel(context.Background(), id)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	if !found {
+		fmt.Printf("❌ Job %d not found (it may have finished already)\n", id)
+		os.Exit(1)
+	}
+	fmt.Printf("✅ Job %d cancelled (a running job stops within %s)\n", id, worker.CancelPollInterval)
+}
+
+// HandleQueueFlush deletes every failed job
+func HandleQueueFlush(args []string) {
+	fs := flag.NewFlagSet("queue:flush", flag.ExitOnError)
+	queueName := fs.String("queue", "", "Only delete failed jobs of this queue")
+	fs.Parse(args)
+
+	queue, closeDB := openQueue()
+	defer closeDB()
+
+	n, err := queue.FlushFailed(context.Background(), *queueName)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("✅ %d failed job(s) deleted\n", n)
+}
@@ -0,0 +1,145 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/nextcore/zeno-go/pkg/engine"
+	"github.com/nextcore/zenoengine/internal/app"
+	"github.com/nextcore/zenoengine/pkg/apidoc"
+	"github.com/nextcore/zenoengine/pkg/dbmanager"
+	"github.com/nextcore/zenoengine/pkg/logger"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/joho/godotenv"
+)
+
+// HandleRouteList executes the main script with a throwaway engine and router (no listener)
+// and prints the routes it registers
+func HandleRouteList(args []string) {
+	fs := flag.NewFlagSet("route:list", flag.ExitOnError)
+	script := fs.String("script", "src/main.zl", "Entry script that registers the routes")
+	method := fs.String("method", "", "Only show these methods (comma separated)")
+	path := fs.String("path", "", "Only show routes whose path starts with this prefix")
+	mw := fs.String("middleware", "", "Only show routes using this middleware")
+	asJSON := fs.Bool("json", false, "Print the routes as JSON")
+	fs.Parse(args)
+
+	godotenv.Load()
+	logger.Setup("development")
+
+	routes, err := collectRoutes(*script)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	routes = filterRoutes(routes, *method, *path, *mw)
+
+	if *asJSON {
+		if routes == nil {
+			routes = []*apidoc.RouteDoc{}
+		}
+		out, _ := json.MarshalIndent(routes, "", "  ")
+		fmt.Println(string(out))
+		return
+	}
+
+	if len(routes) == 0 {
+		fmt.Println("No routes found.")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "METHOD\tPATH\tMIDDLEWARE\tSUMMARY\tSOURCE")
+	for _, r := range routes {
+		source := r.File
+		if r.Line > 0 {
+			source = fmt.Sprintf("%s:%d", r.File, r.Line)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", r.Method, r.Path, strings.Join(r.Middleware, ","), r.Summary, source)
+	}
+	w.Flush()
+	fmt.Printf("\n%d route(s)\n", len(routes))
+}
+
+// collectRoutes runs the script the same way the server boots it and returns the documented routes
+func collectRoutes(script string) ([]*apidoc.RouteDoc, error) {
+	dbMgr, err := app.ConnectDatabases()
+	if err != nil {
+		// Route definitions usually don't need the database
+		fmt.Fprintf(os.Stderr, "⚠️  DB Connection Failed, continuing without database: %v\n", err)
+		dbMgr = dbmanager.NewDBManager()
+	}
+	defer dbMgr.Close()
+
+	root, err := engine.LoadScript(script)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load script: %v", err)
+	}
+
+	eng := engine.NewEngine()
+	app.RegisterAllSlots(eng, chi.NewRouter(), dbMgr, nil, nil)
+
+	scope := engine.NewScope(nil)
+	scope.Set("APP_ENV", os.Getenv("APP_ENV"))
+
+	// Router slots print every registration; keep stdout clean for the listing
+	stdout := os.Stdout
+	if devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0); err == nil {
+		os.Stdout = devNull
+		defer devNull.Close()
+	}
+	err = eng.Execute(context.Background(), root, scope)
+	os.Stdout = stdout
+	if err != nil {
+		return nil, fmt.Errorf("execution error: %v", err)
+	}
+
+	routes := apidoc.Registry.GetRoutes()
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	return routes, nil
+}
+
+func filterRoutes(routes []*apidoc.RouteDoc, methods, prefix, middleware string) []*apidoc.RouteDoc {
+	allowed := make(map[string]bool)
+	for _, m := range strings.Split(methods, ",") {
+		if m = strings.ToUpper(strings.TrimSpace(m)); m != "" {
+			allowed[m] = true
+		}
+	}
+
+	var result []*apidoc.RouteDoc
+	for _, r := range routes {
+		if len(allowed) > 0 && !allowed[r.Method] {
+			continue
+		}
+		if prefix != "" && !strings.HasPrefix(r.Path, prefix) {
+			continue
+		}
+		if middleware != "" && !containsString(r.Middleware, middleware) {
+			continue
+		}
+		result = append(result, r)
+	}
+	return result
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,161 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/nextcore/zeno-go/pkg/engine"
+	"github.com/nextcore/zenoengine/internal/app"
+	"github.com/nextcore/zenoengine/pkg/dbmanager"
+	"github.com/nextcore/zenoengine/pkg/logger"
+	"github.com/nextcore/zenoengine/pkg/worker"
+
+	"github.com/joho/godotenv"
+)
+
+// scheduleContext holds what the schedule commands need after running the main script
+type scheduleContext struct {
+	dbMgr    *dbmanager.DBManager
+	queue    *worker.DBQueue
+	handlers *worker.Handlers
+	tasks    *worker.ScheduledTasks
+	store    *worker.ScheduleStore
+}
+
+// openSchedule connects the databases and runs the main script to collect schedule.cron / schedule.every
+func openSchedule(script string) (*scheduleContext, func()) {
+	godotenv.Load()
+	logger.Setup("development")
+
+	dbMgr, err := app.ConnectDatabases()
+	if err != nil {
+		fmt.Printf("❌ Fatal: DB Connection Failed: %v\n", err)
+		os.Exit(1)
+	}
+	queue := worker.NewDBQueue(dbMgr, worker.QueueConnection())
+	closeAll := func() {
+		queue.Close()
+		dbMgr.Close()
+	}
+
+	_, handlers, tasks, err := configuredOptions(script, dbMgr, queue)
+	if err != nil {
+		closeAll()
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	return &scheduleContext{
+		dbMgr:    dbMgr,
+		queue:    queue,
+		handlers: handlers,
+		tasks:    tasks,
+		store:    worker.NewScheduleStore(dbMgr, worker.ScheduleConnection()),
+	}, closeAll
+}
+
+// HandleScheduleList lists the scheduled tasks with their next and last run
+func HandleScheduleList(args []string) {
+	if code := scheduleList(args); code != 0 {
+		os.Exit(code)
+	}
+}
+
+// scheduleList is HandleScheduleList returning the exit code, so the connections are closed before exiting
+func scheduleList(args []string) int {
+	fs := flag.NewFlagSet("schedule:list", flag.ExitOnError)
+	script := fs.String("script", "src/main.zl", "Entry script that declares schedule.cron / schedule.every")
+	fs.Parse(args)
+
+	sc, closeDB := openSchedule(*script)
+	defer closeDB()
+
+	tasks := sc.tasks.List()
+	if len(tasks) == 0 {
+		fmt.Println("No scheduled tasks.")
+		return 0
+	}
+	statuses, err := sc.store.Status(context.Background())
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return 1
+	}
+
+	now := time.Now()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tSCHEDULE\tNEXT RUN\tLAST RUN\tSTATUS")
+	for _, task := range tasks {
+		next := ""
+		if t := task.Schedule.Next(now); !t.IsZero() {
+			next = t.Local().Format("2006-01-02 15:04:05")
+		}
+		st := statuses[task.Name]
+		last, status := "-", "never run"
+		if !st.LastStartedAt.IsZero() {
+			last = st.LastStartedAt.Local().Format("2006-01-02 15:04:05")
+			status = "ok (" + st.LastDuration.Round(time.Millisecond).String() + ")"
+			if st.LastError != "" {
+				status = "failed: " + firstLine(st.LastError, 60)
+			}
+		}
+		if st.Running {
+			status = "running"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", task.Name, task.Spec, next, last, status)
+	}
+	w.Flush()
+	return 0
+}
+
+// HandleScheduleRun runs one scheduled task now. Without --force it is skipped while a previous run still holds the lock.
+func HandleScheduleRun(args []string) {
+	if code := scheduleRun(args); code != 0 {
+		os.Exit(code)
+	}
+}
+
+// scheduleRun is HandleScheduleRun returning the exit code, so the connections are closed before exiting
+func scheduleRun(args []string) int {
+	fs := flag.NewFlagSet("schedule:run", flag.ExitOnError)
+	script := fs.String("script", "src/main.zl", "Entry script that declares schedule.cron / schedule.every")
+	force := fs.Bool("force", false, "Run even when a previous run of the task is still going")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fmt.Println("Usage: zeno schedule:run [--force] <name>")
+		return 1
+	}
+	name := fs.Arg(0)
+
+	sc, closeDB := openSchedule(*script)
+	defer closeDB()
+
+	task, ok := sc.tasks.Get(name)
+	if !ok {
+		fmt.Printf("❌ Scheduled task '%s' not found (see zeno schedule:list)\n", name)
+		return 1
+	}
+
+	eng := engine.NewEngine()
+	app.RegisterSlots(eng,
+		app.WithCore(),
+		app.WithWeb(nil),
+		app.WithData(sc.dbMgr),
+		app.WithExtra(sc.queue, nil),
+		app.WithJobHandlers(sc.handlers),
+	)
+
+	ran, err := worker.RunTask(context.Background(), eng, sc.store, task, *force)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return 1
+	}
+	if !ran {
+		fmt.Printf("⏭️  Task '%s' skipped: the previous run is still going (use --force to run anyway)\n", name)
+		return 1
+	}
+	fmt.Printf("✅ Task '%s' finished\n", name)
+	return 0
+}
@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/nextcore/zenoengine/internal/app"
+	"github.com/nextcore/zenoengine/pkg/logger"
+	"github.com/nextcore/zenoengine/pkg/migrator"
+
+	"github.com/joho/godotenv"
+)
+
+// HandleSchemaDump writes the current database structure to a .zl migration or a plain SQL file
+func HandleSchemaDump(args []string) {
+	fs := flag.NewFlagSet("schema:dump", flag.ExitOnError)
+	conn := fs.String("db", "default", "Database connection name")
+	format := fs.String("format", "zl", "Output format: zl (Schema Builder) or sql")
+	output := fs.String("output", "", "Output file (Default: database/schema/<db>-schema.<format>)")
+	fs.Parse(args)
+
+	if *format != "zl" && *format != "sql" {
+		fmt.Printf("❌ Unsupported format '%s' (use zl or sql)\n", *format)
+		os.Exit(1)
+	}
+	if *output == "" {
+		*output = migrator.SchemaDumpFile(*conn, *format)
+	}
+
+	godotenv.Load()
+	logger.Setup("development")
+
+	dbMgr, err := app.ConnectDatabases()
+	if err != nil {
+		fmt.Printf("❌ Fatal: DB Connection Failed: %v\n", err)
+		os.Exit(1)
+	}
+
+	db := dbMgr.GetConnection(*conn)
+	if db == nil {
+		fmt.Printf("❌ Database connection '%s' not found\n", *conn)
+		os.Exit(1)
+	}
+
+	content, err := migrator.DumpSchema(context.Background(), db, dbMgr.GetDialect(*conn), *format)
+	if err != nil {
+		fmt.Printf("❌ Schema dump failed: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(*output), 0755); err != nil {
+		fmt.Printf("❌ Failed to create directory: %v\n", err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(*output, []byte(content), 0644); err != nil {
+		fmt.Printf("❌ Failed to write schema dump: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("✅ Schema dumped to %s\n", *output)
+	if *output == migrator.SchemaDumpFile(*conn, *format) {
+		fmt.Println("💡 'zeno migrate' loads this file automatically when the database has no migrations yet")
+	}
+}
@@ -0,0 +1,382 @@
+package cli
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/nextcore/zeno-go/pkg/engine"
+	"github.com/nextcore/zenoengine/internal/app"
+	"github.com/nextcore/zenoengine/internal/slots"
+	"github.com/nextcore/zenoengine/pkg/coverage"
+	"github.com/nextcore/zenoengine/pkg/dbmanager"
+	"github.com/nextcore/zenoengine/pkg/logger"
+	"github.com/nextcore/zenoengine/pkg/migrator"
+	"github.com/nextcore/zenoengine/pkg/worker"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/joho/godotenv"
+)
+
+// testFileSuffix marks the scripts picked up by 'zeno test'
+const testFileSuffix = "_test.zl"
+
+// HandleTest discovers tests/**/*_test.zl (or the given files/directories) and runs every
+// file in its own engine and scope. Exits with status 1 when a test or file fails.
+func HandleTest(args []string) {
+	fs := flag.NewFlagSet("test", flag.ExitOnError)
+	filter := fs.String("filter", "", "Only run tests whose name or file matches this regular expression")
+	junit := fs.String("junit", "", "Write a JUnit XML report to this path")
+	jsonOut := fs.String("json", "", "Write a JSON report to this path")
+	env := fs.String("env", "testing", "APP_ENV used while running the tests")
+	withCoverage := fs.Bool("coverage", false, "Record which lines of src/**/*.zl were executed")
+	coverageDir := fs.String("coverage-dir", "coverage", "Directory for lcov.info and the HTML coverage report")
+	minCoverage := fs.Float64("min-coverage", 0, "Fail when the line coverage is below this percentage (implies --coverage)")
+	updateSnapshots := fs.Bool("update-snapshots", false, "Overwrite the stored snapshots of assert.snapshot")
+	seed := fs.Int64("seed", 0, "Seed for fake.* data in factories (Default: random, printed so a run can be reproduced)")
+	database := fs.String("database", "memory", "Database used by the tests: 'memory' (fresh in-memory SQLite per file, migrated) or 'env' (connections from .env)")
+	fs.Parse(args)
+
+	// Path boleh ditulis sebelum atau sesudah flag
+	var paths []string
+	for fs.NArg() > 0 {
+		paths = append(paths, fs.Arg(0))
+		fs.Parse(fs.Args()[1:])
+	}
+	if len(paths) == 0 {
+		paths = []string{"tests"}
+	}
+
+	godotenv.Load()
+	os.Setenv("APP_ENV", *env)
+	// Form POST dari test.post tidak membawa token CSRF
+	os.Setenv("CSRF_ENABLED", "false")
+	logger.Setup("development")
+
+	// Setiap file memulai generator fake data dari seed yang sama
+	if *seed == 0 {
+		if s, err := strconv.ParseInt(os.Getenv(slots.FakerSeedEnv), 10, 64); err == nil {
+			*seed = s
+		} else {
+			*seed = time.Now().UnixNano() % 1000000
+		}
+	}
+	os.Setenv(slots.FakerSeedEnv, strconv.FormatInt(*seed, 10))
+	fmt.Printf("🎲 Faker seed: %d (rerun with --seed=%d)\n", *seed, *seed)
+
+	if *database != "memory" && *database != "env" {
+		fmt.Printf("❌ Invalid --database '%s' (use memory or env)\n", *database)
+		os.Exit(1)
+	}
+
+	var pattern *regexp.Regexp
+	if *filter != "" {
+		var err error
+		if pattern, err = regexp.Compile(*filter); err != nil {
+			fmt.Printf("❌ Invalid --filter: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	files, err := discoverTests(paths)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	if len(files) == 0 {
+		fmt.Printf("No *%s files found in %s\n", testFileSuffix, strings.Join(paths, ", "))
+		return
+	}
+
+	var cov *coverage.Collector
+	if *withCoverage || *minCoverage > 0 {
+		cov = coverage.NewCollector()
+	}
+
+	var envDB *dbmanager.DBManager
+	if *database == "env" {
+		if envDB, err = app.ConnectDatabases(); err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  DB Connection Failed, continuing without database: %v\n", err)
+			envDB = dbmanager.NewDBManager()
+		}
+		defer envDB.Close()
+	}
+
+	opts := testRunOptions{filter: pattern, coverage: cov, updateSnapshots: *updateSnapshots}
+	var suites []*slots.TestSuite
+	for _, file := range files {
+		fmt.Printf("\n📄 %s\n", file)
+		var suite *slots.TestSuite
+		if envDB != nil {
+			suite = runTestFile(file, envDB, nil, opts)
+		} else {
+			suite = runTestFileInMemory(file, opts)
+		}
+		if suite.Err != nil {
+			fmt.Printf("ERROR %v\n", suite.Err)
+		}
+		suites = append(suites, suite)
+	}
+
+	total, passed, failed, skipped := 0, 0, 0, 0
+	for _, s := range suites {
+		total += s.Stats.Total
+		passed += s.Stats.Passed
+		failed += s.Failures()
+		skipped += s.Stats.Skipped
+		for _, e := range s.Stats.Errors {
+			fmt.Printf("  %s (%s)\n", e, s.File)
+		}
+		if s.Err != nil {
+			fmt.Printf("  ERROR [%s]: %v\n", s.File, s.Err)
+		}
+	}
+
+	if *junit != "" {
+		writeTestReport(*junit, suites, slots.WriteJUnitReport)
+	}
+	if *jsonOut != "" {
+		writeTestReport(*jsonOut, suites, slots.WriteJSONReport)
+	}
+
+	fmt.Printf("\nTests: %d passed, %d failed", passed, failed)
+	if skipped > 0 {
+		fmt.Printf(", %d skipped", skipped)
+	}
+	fmt.Printf(" (%d total, %d file(s))\n", total, len(suites))
+
+	coverageOK := true
+	if cov != nil {
+		coverageOK = reportCoverage(cov, *coverageDir, *minCoverage)
+	}
+
+	if failed > 0 || !coverageOK {
+		os.Exit(1)
+	}
+	if total == 0 && pattern != nil {
+		fmt.Println("⚠️  No test matched the filter")
+	}
+}
+
+// discoverTests expands files and directories into a sorted list of *_test.zl scripts
+func discoverTests(paths []string) ([]string, error) {
+	seen := make(map[string]bool)
+	var files []string
+	for _, root := range paths {
+		info, err := os.Stat(root)
+		if err != nil {
+			return nil, fmt.Errorf("test path not found: %s", root)
+		}
+		if !info.IsDir() {
+			if !seen[root] {
+				seen[root] = true
+				files = append(files, root)
+			}
+			continue
+		}
+		filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return nil
+			}
+			if info.IsDir() {
+				if path != root && strings.HasPrefix(info.Name(), ".") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if strings.HasSuffix(path, testFileSuffix) && !seen[path] {
+				seen[path] = true
+				files = append(files, path)
+			}
+			return nil
+		})
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// testRunOptions are the settings shared by every test file of a run
+type testRunOptions struct {
+	filter          *regexp.Regexp
+	coverage        *coverage.Collector
+	updateSnapshots bool
+}
+
+// newTestDatabases opens in-memory SQLite databases as the default and internal connections
+// and migrates the default one. Each connection is limited to a single, never recycled
+// connection: closing it would drop the database, and the per-test savepoint only covers
+// queries running on the same connection.
+func newTestDatabases() (*dbmanager.DBManager, error) {
+	dbMgr := dbmanager.NewDBManager()
+	for _, name := range []string{"default", "internal"} {
+		if err := dbMgr.AddConnection(name, "sqlite", ":memory:", 1, 1); err != nil {
+			dbMgr.Close()
+			return nil, err
+		}
+		db := dbMgr.GetConnection(name)
+		db.SetConnMaxLifetime(0)
+		db.SetConnMaxIdleTime(0)
+	}
+
+	dir := defaultMigrationDir()
+	if _, err := os.Stat(dir); err != nil {
+		return dbMgr, nil
+	}
+	eng := engine.NewEngine()
+	app.RegisterSlots(eng, app.WithCore(), app.WithData(dbMgr))
+	m := migrator.New(eng, dbMgr, dir)
+	m.SchemaFile = existingSchemaDump("default")
+	if err := m.Run(); err != nil {
+		dbMgr.Close()
+		return nil, fmt.Errorf("failed to migrate test database: %v", err)
+	}
+	return dbMgr, nil
+}
+
+// runTestFileInMemory runs a test file against its own freshly migrated in-memory database.
+// Every test block is wrapped in a savepoint that is rolled back when the block ends.
+func runTestFileInMemory(file string, opts testRunOptions) *slots.TestSuite {
+	dbMgr, err := newTestDatabases()
+	if err != nil {
+		return &slots.TestSuite{File: filepath.ToSlash(file), Stats: &slots.TestStats{}, Err: err}
+	}
+	defer dbMgr.Close()
+	return runTestFile(file, dbMgr, dbMgr.GetConnection("default"), opts)
+}
+
+// runTestFile executes one test script with a fresh engine, router and scope so files can't
+// leak state into each other. A filter matching the file path runs all of its tests.
+// When txDB is set, every test block runs inside a transaction on it that is rolled back.
+func runTestFile(file string, dbMgr *dbmanager.DBManager, txDB *sql.DB, opts testRunOptions) *slots.TestSuite {
+	suite := &slots.TestSuite{File: filepath.ToSlash(file), Stats: &slots.TestStats{}}
+	start := time.Now()
+	defer func() { suite.Duration = time.Since(start) }()
+
+	root, err := engine.LoadScript(file)
+	if err != nil {
+		suite.Err = fmt.Errorf("failed to load script: %v", err)
+		return suite
+	}
+
+	eng := engine.NewEngine()
+	app.RegisterSlots(eng,
+		app.WithCore(),
+		app.WithWeb(chi.NewRouter()),
+		app.WithData(dbMgr),
+		app.WithExtra(worker.NewDBQueue(dbMgr, "internal"), nil),
+		app.WithTest(),
+	)
+	if opts.coverage != nil {
+		opts.coverage.Instrument(eng)
+	}
+
+	scope := engine.NewScope(nil)
+	scope.Set("APP_ENV", os.Getenv("APP_ENV"))
+
+	// Router aplikasi (src/main.zl) baru dibangun saat test pertama mengirim request
+	var (
+		once      sync.Once
+		router    http.Handler
+		routerErr error
+	)
+	ctx := slots.WithTestStats(context.Background(), suite.Stats)
+	ctx = slots.WithTestRouter(ctx, func() (http.Handler, error) {
+		once.Do(func() {
+			router, routerErr = app.BuildRouter(&app.AppContext{
+				DBMgr:    dbMgr,
+				Queue:    worker.NewDBQueue(dbMgr, "internal"),
+				Env:      os.Getenv("APP_ENV"),
+				Coverage: opts.coverage,
+			})
+		})
+		return router, routerErr
+	})
+	if txDB != nil {
+		ctx = slots.WithTestDatabase(ctx, txDB)
+	}
+	if opts.filter != nil && !opts.filter.MatchString(suite.File) {
+		ctx = slots.WithTestFilter(ctx, opts.filter)
+	}
+	ctx = slots.WithSnapshotUpdate(ctx, opts.updateSnapshots)
+
+	if err := eng.Execute(ctx, root, scope); err != nil {
+		suite.Err = err
+	}
+	return suite
+}
+
+func writeTestReport(path string, suites []*slots.TestSuite, write func(io.Writer, []*slots.TestSuite) error) {
+	if dir := filepath.Dir(path); dir != "." {
+		os.MkdirAll(dir, 0755)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		fmt.Printf("❌ Failed to write report: %v\n", err)
+		os.Exit(1)
+	}
+	defer f.Close()
+	if err := write(f, suites); err != nil {
+		fmt.Printf("❌ Failed to write report %s: %v\n", path, err)
+		os.Exit(1)
+	}
+	fmt.Printf("📝 Report written: %s\n", path)
+}
+
+// reportCoverage writes lcov.info and the HTML report for src/**/*.zl and prints the summary.
+// Returns false when the total is below min.
+func reportCoverage(cov *coverage.Collector, dir string, min float64) bool {
+	if err := cov.AddSources("src"); err != nil {
+		fmt.Printf("❌ Failed to read sources for coverage: %v\n", err)
+		return false
+	}
+	files := cov.Files()
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		fmt.Printf("❌ Failed to create %s: %v\n", dir, err)
+		return false
+	}
+	lcovPath := filepath.Join(dir, "lcov.info")
+	f, err := os.Create(lcovPath)
+	if err != nil {
+		fmt.Printf("❌ Failed to write coverage: %v\n", err)
+		return false
+	}
+	err = coverage.WriteLCOV(f, files)
+	f.Close()
+	if err != nil {
+		fmt.Printf("❌ Failed to write coverage: %v\n", err)
+		return false
+	}
+	htmlDir := filepath.Join(dir, "html")
+	if err := coverage.WriteHTML(htmlDir, files); err != nil {
+		fmt.Printf("❌ Failed to write HTML coverage: %v\n", err)
+		return false
+	}
+
+	fmt.Println("\nCoverage:")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, fc := range files {
+		fmt.Fprintf(w, "  %s\t%d/%d\t%.1f%%\n", fc.File, fc.Hit, fc.Found, fc.Percent())
+	}
+	w.Flush()
+
+	total := coverage.Total(files)
+	fmt.Printf("Total: %.1f%% (%s, %s)\n", total, lcovPath, filepath.Join(htmlDir, "index.html"))
+	if total < min {
+		fmt.Printf("❌ Coverage %.1f%% is below the minimum of %.1f%%\n", total, min)
+		return false
+	}
+	return true
+}
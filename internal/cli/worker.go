@@ -0,0 +1,226 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/nextcore/zeno-go/pkg/engine"
+	"github.com/nextcore/zenoengine/internal/app"
+	"github.com/nextcore/zenoengine/pkg/dbmanager"
+	"github.com/nextcore/zenoengine/pkg/logger"
+	"github.com/nextcore/zenoengine/pkg/worker"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/joho/godotenv"
+)
+
+// HandleWorker runs only the queue workers, without binding an HTTP port, until SIGINT/SIGTERM.
+// Options come from worker.config in the main script; flags that are set override them.
+func HandleWorker(args []string) {
+	if code := runWorker(args); code != 0 {
+		os.Exit(code)
+	}
+}
+
+// runWorker is HandleWorker returning the exit code, so the connections and the queue listener are closed before exiting
+func runWorker(args []string) int {
+	fs := flag.NewFlagSet("worker", flag.ExitOnError)
+	queuesFlag := fs.String("queues", "", "Comma separated queues to process, optionally weighted: high:3,default (Default: worker.config in --script, else 'default')")
+	concurrency := fs.Int("concurrency", worker.DefaultConcurrency, "Maximum number of jobs running at the same time")
+	priority := fs.String("priority", "", "How queues share the pool: fifo, strict or weighted (Default: weighted when --queues has weights, else fifo)")
+	limitsFlag := fs.String("limits", "", "Per-queue concurrency limits, e.g. sms:2,email:5")
+	rateLimitsFlag := fs.String("rate-limits", "", "Jobs claimed per period, by queue or rate_key, e.g. sms:10/s,mailgun:300/m")
+	timeout := fs.Duration("timeout", 0, "Maximum duration of one attempt for jobs without their own timeout (0 = no limit)")
+	timeoutsFlag := fs.String("timeouts", "", "Per-queue timeouts, e.g. sms:30s,reports:10m")
+	grace := fs.Duration("shutdown-grace", worker.DefaultShutdownGrace, "How long running jobs may finish after SIGTERM before they are released back to the queue")
+	script := fs.String("script", "src/main.zl", "Entry script that calls worker.config")
+	visibility := fs.Duration("visibility-timeout", worker.DefaultVisibilityTimeout, "How long a reserved job may go without a heartbeat before it is returned to the queue")
+	schedule := fs.Bool("schedule", true, "Also run the schedule.cron / schedule.every tasks from --script")
+	fs.Parse(args)
+
+	godotenv.Load()
+	logger.Setup(os.Getenv("APP_ENV"))
+
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	// The pools are opened with the DB_MAX_* sizes and grown below once worker.config and the flags give the final concurrency
+	dbMgr, err := app.ConnectDatabases()
+	if err != nil {
+		fmt.Printf("❌ Fatal: DB Connection Failed: %v\n", err)
+		return 1
+	}
+	defer dbMgr.Close()
+
+	queue := worker.NewDBQueue(dbMgr, worker.QueueConnection())
+	queue.VisibilityTimeout = *visibility
+	defer queue.Close()
+
+	// The script always runs so job.define handlers and scheduled tasks are registered; its worker.config is ignored when --queues is set
+	configured, handlers, tasks, err := configuredOptions(*script, dbMgr, queue)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return 1
+	}
+	var opts worker.Options
+	if !set["queues"] {
+		opts = configured
+	}
+	opts.Handlers = handlers
+	if set["queues"] {
+		queues, weights, err := worker.ParseQueueList(*queuesFlag)
+		if err != nil {
+			fmt.Printf("❌ --queues: %v\n", err)
+			return 1
+		}
+		opts.Queues, opts.Weights = queues, weights
+		if len(weights) > 0 && !set["priority"] {
+			opts.Priority = worker.PriorityWeighted
+		}
+	}
+	if set["concurrency"] || opts.Concurrency == 0 {
+		opts.Concurrency = *concurrency
+	}
+	if set["priority"] {
+		opts.Priority = strings.ToLower(*priority)
+	}
+	if set["limits"] {
+		if opts.Limits, err = worker.ParseQueueLimits(*limitsFlag); err != nil {
+			fmt.Printf("❌ --limits: %v\n", err)
+			return 1
+		}
+	}
+	if set["rate-limits"] {
+		if opts.RateLimits, err = worker.ParseRateLimits(*rateLimitsFlag); err != nil {
+			fmt.Printf("❌ --rate-limits: %v\n", err)
+			return 1
+		}
+	}
+	if set["timeout"] {
+		opts.Timeout = *timeout
+	}
+	if set["timeouts"] {
+		if opts.Timeouts, err = worker.ParseQueueTimeouts(*timeoutsFlag); err != nil {
+			fmt.Printf("❌ --timeouts: %v\n", err)
+			return 1
+		}
+	}
+	if set["shutdown-grace"] {
+		opts.ShutdownGrace = *grace
+	}
+	if len(opts.Queues) == 0 {
+		opts.Queues = []string{"default"}
+	}
+	if opts.Concurrency < 1 {
+		fmt.Println("❌ --concurrency must be at least 1")
+		return 1
+	}
+	if err := opts.Validate(); err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return 1
+	}
+
+	// Every running job may hold a connection, plus the reaper and the claim loop.
+	// SQLite pools stay as opened: one connection serialises writers, where more would fail with SQLITE_BUSY.
+	for _, name := range []string{"default", "internal"} {
+		db := dbMgr.GetConnection(name)
+		if db == nil || dbMgr.GetDialect(name).Name() == "sqlite" {
+			continue
+		}
+		if limit := db.Stats().MaxOpenConnections; limit > 0 && limit < opts.Concurrency+2 {
+			db.SetMaxOpenConns(opts.Concurrency + 2)
+		}
+	}
+
+	eng := engine.NewEngine()
+	app.RegisterSlots(eng,
+		app.WithCore(),
+		app.WithWeb(nil),
+		app.WithData(dbMgr),
+		app.WithExtra(queue, nil),
+		app.WithJobHandlers(handlers),
+	)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	mode := opts.Priority
+	if mode == "" {
+		mode = worker.PriorityFIFO
+	}
+	fmt.Printf("👷 Worker started (queues: %s, concurrency: %d, priority: %s). Press Ctrl+C to stop.\n", strings.Join(opts.Queues, ","), opts.Concurrency, mode)
+	if names := handlers.Names(); len(names) > 0 {
+		fmt.Printf("📋 Job handlers: %s\n", strings.Join(names, ", "))
+	}
+
+	var wg sync.WaitGroup
+	if *schedule && len(tasks.List()) > 0 {
+		fmt.Printf("⏰ Scheduled tasks: %s\n", strings.Join(taskNames(tasks), ", "))
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			worker.RunScheduler(ctx, eng, tasks, worker.NewScheduleStore(dbMgr, worker.ScheduleConnection()))
+		}()
+	}
+	worker.Run(ctx, eng, queue, opts)
+	wg.Wait()
+	return 0
+}
+
+func taskNames(tasks *worker.ScheduledTasks) []string {
+	var names []string
+	for _, task := range tasks.List() {
+		names = append(names, task.Name)
+	}
+	return names
+}
+
+// configuredOptions runs the main script with a throwaway router (no listener) to read worker.config,
+// the job handlers defined with job.define and the tasks defined with schedule.cron / schedule.every
+func configuredOptions(script string, dbMgr *dbmanager.DBManager, queue worker.JobQueue) (worker.Options, *worker.Handlers, *worker.ScheduledTasks, error) {
+	var opts worker.Options
+	handlers := worker.NewHandlers()
+	tasks := worker.NewScheduledTasks()
+	if _, err := os.Stat(script); os.IsNotExist(err) {
+		return opts, handlers, tasks, nil
+	}
+	root, err := engine.LoadScript(script)
+	if err != nil {
+		return opts, nil, nil, fmt.Errorf("failed to load script: %v", err)
+	}
+
+	eng := engine.NewEngine()
+	app.RegisterSlots(eng,
+		app.WithCore(),
+		app.WithWeb(chi.NewRouter()),
+		app.WithData(dbMgr),
+		app.WithExtra(queue, nil),
+		app.WithWorkerConfig(func(o worker.Options) {
+			opts = o
+		}),
+		app.WithJobHandlers(handlers),
+		app.WithScheduledTasks(tasks),
+	)
+
+	scope := engine.NewScope(nil)
+	scope.Set("APP_ENV", os.Getenv("APP_ENV"))
+
+	// Router slots print every registration; the worker only needs worker.config
+	stdout := os.Stdout
+	if devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0); err == nil {
+		os.Stdout = devNull
+		defer devNull.Close()
+	}
+	err = eng.Execute(context.Background(), root, scope)
+	os.Stdout = stdout
+	if err != nil {
+		return opts, nil, nil, fmt.Errorf("execution error: %v", err)
+	}
+	return opts, handlers, tasks, nil
+}
@@ -12,7 +12,7 @@ import (
 // Helper: Build query from Node
 func buildQuery(node *engine.Node, scope *engine.Scope) (string, string, []interface{}, error) {
 	query := ""
-	dbName := "default"
+	dbName := migrationDB(scope)
 	var args []interface{}
 
 	// Value utama: mysql.select: "SELECT * FROM..." (atau $query_var)
@@ -0,0 +1,354 @@
+package slots
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/nextcore/zeno-go/pkg/engine"
+	"github.com/nextcore/zeno-go/pkg/utils/coerce"
+	"github.com/nextcore/zenoengine/pkg/dbmanager"
+)
+
+// factoryDef is a model factory registered with factory.define
+type factoryDef struct {
+	Name   string
+	Table  string
+	DB     string
+	Attrs  []*engine.Node
+	States map[string][]*engine.Node
+	scope  *engine.Scope // Scope tempat factory didefinisikan, untuk $variabel di atribut
+}
+
+// factoryRequest describes one factory.make / factory.create call
+type factoryRequest struct {
+	Count     int
+	HasCount  bool
+	States    []string
+	Overrides map[string]interface{}
+	Has       map[string]interface{} // relasi hasMany: tabel anak -> jumlah atau { count, foreign_key, state }
+	Persist   bool
+}
+
+type factoryRegistry struct {
+	mu   sync.RWMutex
+	defs map[string]*factoryDef
+}
+
+func (r *factoryRegistry) get(name string) (*factoryDef, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	def, ok := r.defs[name]
+	if !ok {
+		return nil, fmt.Errorf("factory '%s' is not defined (use factory.define)", name)
+	}
+	return def, nil
+}
+
+// singularize is the counterpart of the simple plural rules used for table names
+func singularize(word string) string {
+	switch {
+	case strings.HasSuffix(word, "ies") && len(word) > 3:
+		return word[:len(word)-3] + "y"
+	case strings.HasSuffix(word, "sses"), strings.HasSuffix(word, "xes"), strings.HasSuffix(word, "ches"), strings.HasSuffix(word, "shes"):
+		return word[:len(word)-2]
+	case strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss"):
+		return word[:len(word)-1]
+	}
+	return word
+}
+
+// RegisterFactorySlots registers factory.define/make/create and the fake.* data generators.
+// Factories and the generator are shared by all scripts running on this engine.
+func RegisterFactorySlots(eng *engine.Engine, dbMgr *dbmanager.DBManager) {
+	faker := NewFakerFromEnv()
+	registry := &factoryRegistry{defs: make(map[string]*factoryDef)}
+	registerFakerSlots(eng, faker)
+
+	var build func(ctx context.Context, def *factoryDef, req factoryRequest, scope *engine.Scope) (map[string]interface{}, error)
+
+	// evalAttr menghitung satu atribut: fake.<type>, factory.<nama> (belongs-to) atau nilai biasa
+	evalAttr := func(ctx context.Context, def *factoryDef, c *engine.Node, persist bool) (interface{}, error) {
+		if raw, ok := c.Value.(string); ok && len(c.Children) == 0 {
+			raw = strings.TrimSpace(raw)
+			if strings.HasPrefix(raw, "fake.") {
+				return faker.Generate(strings.TrimPrefix(raw, "fake."))
+			}
+			if strings.HasPrefix(raw, "factory.") {
+				// make tidak menyentuh database, jadi relasi belongs-to dibiarkan kosong
+				if !persist {
+					return nil, nil
+				}
+				parent, err := registry.get(strings.TrimPrefix(raw, "factory."))
+				if err != nil {
+					return nil, err
+				}
+				row, err := build(ctx, parent, factoryRequest{Persist: true}, def.scope)
+				if err != nil {
+					return nil, err
+				}
+				return row["id"], nil
+			}
+		}
+		return parseNodeValue(c, def.scope), nil
+	}
+
+	// insertRow menyimpan satu baris lewat executor aktif (ikut transaksi) dan mengisi id-nya
+	insertRow := func(ctx context.Context, def *factoryDef, row map[string]interface{}, scope *engine.Scope) error {
+		if err := fireHook(ctx, eng, def.Table, HookBeforeSave, row, scope); err != nil {
+			return err
+		}
+		if err := fireHook(ctx, eng, def.Table, HookBeforeInsert, row, scope); err != nil {
+			return err
+		}
+
+		executor, dialect, err := getExecutor(scope, dbMgr, def.DB)
+		if err != nil {
+			return err
+		}
+		cols := make([]string, 0, len(row))
+		for col := range row {
+			cols = append(cols, col)
+		}
+		sort.Strings(cols)
+
+		quoted := make([]string, len(cols))
+		placeholders := make([]string, len(cols))
+		vals := make([]interface{}, len(cols))
+		for i, col := range cols {
+			quoted[i] = dialect.QuoteIdentifier(col)
+			placeholders[i] = dialect.Placeholder(i + 1)
+			vals[i] = row[col]
+		}
+		query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
+			dialect.QuoteIdentifier(def.Table), strings.Join(quoted, ", "), strings.Join(placeholders, ", "))
+
+		if _, hasID := row["id"]; !hasID {
+			// RETURNING needs a real query; in pretend mode the insert is only recorded
+			if dialect.Name() == "postgres" && !isPretend(executor) {
+				var id interface{}
+				if err := executor.QueryRowContext(ctx, query+" RETURNING id", vals...).Scan(&id); err != nil {
+					return fmt.Errorf("factory '%s': %v", def.Name, err)
+				}
+				row["id"] = id
+			} else {
+				res, err := executor.ExecContext(ctx, query, vals...)
+				if err != nil {
+					return fmt.Errorf("factory '%s': %v", def.Name, err)
+				}
+				if id, err := res.LastInsertId(); err == nil {
+					row["id"] = id
+				}
+			}
+		} else if _, err := executor.ExecContext(ctx, query, vals...); err != nil {
+			return fmt.Errorf("factory '%s': %v", def.Name, err)
+		}
+
+		if err := fireHook(ctx, eng, def.Table, HookAfterInsert, row, scope); err != nil {
+			return err
+		}
+		return fireHook(ctx, eng, def.Table, HookAfterSave, row, scope)
+	}
+
+	build = func(ctx context.Context, def *factoryDef, req factoryRequest, scope *engine.Scope) (map[string]interface{}, error) {
+		row := make(map[string]interface{})
+		apply := func(nodes []*engine.Node) error {
+			for _, c := range nodes {
+				if _, overridden := req.Overrides[c.Name]; overridden {
+					continue
+				}
+				val, err := evalAttr(ctx, def, c, req.Persist)
+				if err != nil {
+					return fmt.Errorf("factory '%s': %s: %v", def.Name, c.Name, err)
+				}
+				row[c.Name] = val
+			}
+			return nil
+		}
+
+		if err := apply(def.Attrs); err != nil {
+			return nil, err
+		}
+		for _, state := range req.States {
+			nodes, ok := def.States[state]
+			if !ok {
+				return nil, fmt.Errorf("factory '%s' has no state '%s'", def.Name, state)
+			}
+			if err := apply(nodes); err != nil {
+				return nil, err
+			}
+		}
+		for k, v := range req.Overrides {
+			row[k] = v
+		}
+
+		if req.Persist {
+			if err := insertRow(ctx, def, row, scope); err != nil {
+				return nil, err
+			}
+		}
+
+		// Relasi hasMany: anak dibuat setelah induk agar foreign key-nya tersedia
+		relations := make([]string, 0, len(req.Has))
+		for rel := range req.Has {
+			relations = append(relations, rel)
+		}
+		sort.Strings(relations)
+		for _, rel := range relations {
+			child, err := registry.get(rel)
+			if err != nil {
+				return nil, err
+			}
+			childReq := factoryRequest{Count: 1, Persist: req.Persist, Overrides: map[string]interface{}{}}
+			foreignKey := singularize(def.Table) + "_id"
+			switch spec := req.Has[rel].(type) {
+			case map[string]interface{}:
+				if n, err := coerce.ToInt(spec["count"]); err == nil && spec["count"] != nil {
+					childReq.Count = n
+				}
+				if fk, ok := spec["foreign_key"]; ok {
+					foreignKey = coerce.ToString(fk)
+				}
+				if st, ok := spec["state"]; ok {
+					childReq.States = []string{coerce.ToString(st)}
+				}
+			default:
+				if n, err := coerce.ToInt(spec); err == nil {
+					childReq.Count = n
+				}
+			}
+			childReq.Overrides[foreignKey] = row["id"]
+
+			children := make([]interface{}, 0, childReq.Count)
+			for i := 0; i < childReq.Count; i++ {
+				c, err := build(ctx, child, childReq, scope)
+				if err != nil {
+					return nil, err
+				}
+				children = append(children, c)
+			}
+			row[rel] = children
+		}
+		return row, nil
+	}
+
+	// SLOT: factory.define
+	eng.Register("factory.define", func(ctx context.Context, node *engine.Node, scope *engine.Scope) error {
+		name := coerce.ToString(resolveValue(node.Value, scope))
+		if name == "" {
+			return fmt.Errorf("factory.define: name is required")
+		}
+		def := &factoryDef{Name: name, Table: name, DB: "default", States: make(map[string][]*engine.Node), scope: scope}
+		for _, c := range node.Children {
+			switch c.Name {
+			case "table":
+				def.Table = coerce.ToString(parseNodeValue(&engine.Node{Value: c.Value}, scope))
+			case "db":
+				def.DB = coerce.ToString(parseNodeValue(&engine.Node{Value: c.Value}, scope))
+			case "state":
+				def.States[coerce.ToString(resolveValue(c.Value, scope))] = c.Children
+			default:
+				def.Attrs = append(def.Attrs, c)
+			}
+		}
+
+		registry.mu.Lock()
+		registry.defs[name] = def
+		registry.mu.Unlock()
+		return nil
+	}, engine.SlotMeta{
+		Description: "Defines a model factory. Attributes may use fake.<type>, factory.<name> (belongs-to) or literal values.",
+		Example:     "factory.define: 'users' {\n  name: fake.name\n  email: fake.unique_email\n  state: 'admin' {\n    role: 'admin'\n  }\n}",
+		Inputs: map[string]engine.InputMeta{
+			"table": {Description: "Table name (Default: factory name)", Required: false},
+			"db":    {Description: "Database connection name (Default: 'default')", Required: false},
+			"state": {Description: "Named set of attribute overrides", Required: false},
+		},
+	})
+
+	// SLOT: factory.make / factory.create
+	factorySlot := func(name string, persist bool) {
+		eng.Register(name, func(ctx context.Context, node *engine.Node, scope *engine.Scope) error {
+			factoryName := coerce.ToString(resolveValue(node.Value, scope))
+			target := ""
+			req := factoryRequest{Count: 1, Persist: persist, Overrides: map[string]interface{}{}}
+
+			for _, c := range node.Children {
+				val := parseNodeValue(c, scope)
+				switch c.Name {
+				case "count":
+					n, err := coerce.ToInt(val)
+					if err != nil || n < 0 {
+						return fmt.Errorf("%s: count must be a positive number", name)
+					}
+					req.Count, req.HasCount = n, true
+				case "state":
+					if list, ok := val.([]interface{}); ok {
+						for _, s := range list {
+							req.States = append(req.States, coerce.ToString(s))
+						}
+					} else {
+						req.States = append(req.States, coerce.ToString(val))
+					}
+				case "attributes", "with":
+					if m, ok := val.(map[string]interface{}); ok {
+						for k, v := range m {
+							req.Overrides[k] = v
+						}
+					}
+				case "has":
+					if m, ok := val.(map[string]interface{}); ok {
+						req.Has = m
+					}
+				case "as":
+					target = strings.TrimPrefix(coerce.ToString(c.Value), "$")
+				}
+			}
+
+			def, err := registry.get(factoryName)
+			if err != nil {
+				return fmt.Errorf("%s: %v", name, err)
+			}
+
+			rows := make([]interface{}, 0, req.Count)
+			for i := 0; i < req.Count; i++ {
+				row, err := build(ctx, def, req, scope)
+				if err != nil {
+					return fmt.Errorf("%s: %v", name, err)
+				}
+				rows = append(rows, row)
+			}
+
+			// Tanpa count hasilnya satu baris, dengan count selalu list
+			if target == "" {
+				target = factoryName
+				if !req.HasCount {
+					target = singularize(factoryName)
+				}
+			}
+			if req.HasCount {
+				scope.Set(target, rows)
+			} else if len(rows) == 1 {
+				scope.Set(target, rows[0])
+			}
+			return nil
+		}, engine.SlotMeta{
+			Description: map[bool]string{
+				false: "Builds rows from a factory without saving them.",
+				true:  "Builds rows from a factory and inserts them into the database.",
+			}[persist],
+			Example: name + ": 'users' {\n  count: 3\n  state: 'admin'\n  attributes: { company: 'Zeno' }\n  has: { posts: 2 }\n  as: $users\n}",
+			Inputs: map[string]engine.InputMeta{
+				"count":      {Description: "Number of rows; the result is a list when given", Required: false},
+				"state":      {Description: "State (or list of states) to apply", Required: false},
+				"attributes": {Description: "Attribute overrides", Required: false},
+				"has":        {Description: "Child factories to create per row, e.g. { posts: 3 }", Required: false},
+				"as":         {Description: "Variable for the result (Default: factory name, singular without count)", Required: false},
+			},
+		})
+	}
+	factorySlot("factory.make", false)
+	factorySlot("factory.create", true)
+}
@@ -0,0 +1,114 @@
+package slots
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nextcore/zeno-go/pkg/engine"
+	"github.com/nextcore/zenoengine/pkg/dbmanager"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakerDeterministic(t *testing.T) {
+	a, b := NewFaker(42), NewFaker(42)
+	for _, kind := range FakeTypes() {
+		if kind == "password" {
+			continue // bcrypt memakai salt acak
+		}
+		va, err := a.Generate(kind)
+		require.NoError(t, err)
+		vb, _ := b.Generate(kind)
+		assert.Equal(t, va, vb, kind)
+	}
+
+	a.Seed(7)
+	first, _ := a.Generate("name")
+	a.Seed(7)
+	again, _ := a.Generate("name")
+	assert.Equal(t, first, again)
+	assert.Equal(t, int64(7), a.CurrentSeed())
+
+	e1, _ := a.Generate("unique_email")
+	e2, _ := a.Generate("unique_email")
+	assert.NotEqual(t, e1, e2)
+
+	_, err := a.Generate("nope")
+	assert.Error(t, err)
+}
+
+func TestSingularize(t *testing.T) {
+	assert.Equal(t, "user", singularize("users"))
+	assert.Equal(t, "category", singularize("categories"))
+	assert.Equal(t, "box", singularize("boxes"))
+	assert.Equal(t, "address", singularize("address"))
+}
+
+func TestFactorySlots(t *testing.T) {
+	t.Setenv(FakerSeedEnv, "1")
+
+	dbMgr := dbmanager.NewDBManager()
+	require.NoError(t, dbMgr.AddConnection("default", "sqlite", ":memory:", 1, 1))
+	defer dbMgr.Close()
+
+	db := dbMgr.GetConnection("default")
+	_, err := db.Exec(`CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT, email TEXT, role TEXT)`)
+	require.NoError(t, err)
+	_, err = db.Exec(`CREATE TABLE posts (id INTEGER PRIMARY KEY, user_id INTEGER, title TEXT)`)
+	require.NoError(t, err)
+
+	eng := engine.NewEngine()
+	RegisterFactorySlots(eng, dbMgr)
+	ctx := context.Background()
+	scope := engine.NewScope(nil)
+
+	define := &engine.Node{Name: "factory.define", Value: "'users'", Children: []*engine.Node{
+		{Name: "name", Value: "fake.name"},
+		{Name: "email", Value: "fake.unique_email"},
+		{Name: "role", Value: "'member'"},
+		{Name: "state", Value: "'admin'", Children: []*engine.Node{{Name: "role", Value: "'admin'"}}},
+	}}
+	require.NoError(t, eng.Execute(ctx, define, scope))
+	require.NoError(t, eng.Execute(ctx, &engine.Node{Name: "factory.define", Value: "'posts'", Children: []*engine.Node{
+		{Name: "title", Value: "fake.sentence"},
+		{Name: "user_id", Value: "factory.users"},
+	}}, scope))
+
+	t.Run("make does not insert", func(t *testing.T) {
+		require.NoError(t, eng.Execute(ctx, &engine.Node{Name: "factory.make", Value: "'users'"}, scope))
+		user, _ := scope.Get("user")
+		row, _ := user.(map[string]interface{})
+		assert.Equal(t, "member", row["role"])
+
+		var n int
+		db.QueryRow("SELECT COUNT(*) FROM users").Scan(&n)
+		assert.Equal(t, 0, n)
+	})
+
+	t.Run("create with count, state and has", func(t *testing.T) {
+		require.NoError(t, eng.Execute(ctx, &engine.Node{Name: "factory.create", Value: "'users'", Children: []*engine.Node{
+			{Name: "count", Value: "2"},
+			{Name: "state", Value: "'admin'"},
+			{Name: "has", Children: []*engine.Node{{Name: "posts", Value: "3"}}},
+		}}, scope))
+
+		var users, admins, posts int
+		db.QueryRow("SELECT COUNT(*) FROM users").Scan(&users)
+		db.QueryRow("SELECT COUNT(*) FROM users WHERE role = 'admin'").Scan(&admins)
+		db.QueryRow("SELECT COUNT(*) FROM posts WHERE user_id IN (SELECT id FROM users)").Scan(&posts)
+		assert.Equal(t, 2, users)
+		assert.Equal(t, 2, admins)
+		assert.Equal(t, 6, posts)
+	})
+
+	t.Run("belongs-to creates parent", func(t *testing.T) {
+		require.NoError(t, eng.Execute(ctx, &engine.Node{Name: "factory.create", Value: "'posts'", Children: []*engine.Node{
+			{Name: "attributes", Children: []*engine.Node{{Name: "title", Value: "'Hello'"}}},
+		}}, scope))
+		post, _ := scope.Get("post")
+		row, _ := post.(map[string]interface{})
+		assert.Equal(t, "Hello", row["title"])
+		assert.NotNil(t, row["user_id"])
+	})
+}
@@ -0,0 +1,226 @@
+package slots
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nextcore/zeno-go/pkg/engine"
+	"github.com/nextcore/zeno-go/pkg/utils/coerce"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// FakerSeedEnv sets the seed of the fake data generator, so a failing run can be reproduced
+const FakerSeedEnv = "ZENO_FAKER_SEED"
+
+var (
+	fakeFirstNames = []string{"Budi", "Siti", "Agus", "Dewi", "Rina", "Andi", "Putri", "Joko", "Ayu", "Rizky",
+		"Maria", "John", "Emma", "Liam", "Olivia", "Noah", "Sophia", "Lucas", "Mia", "Ethan"}
+	fakeLastNames = []string{"Santoso", "Wijaya", "Pratama", "Saputra", "Hidayat", "Kusuma", "Lestari", "Nugroho",
+		"Halim", "Gunawan", "Smith", "Johnson", "Brown", "Garcia", "Miller", "Davis", "Martin", "Lee", "Walker", "Young"}
+	fakeDomains   = []string{"example.com", "example.org", "example.net", "mail.test"}
+	fakeCities    = []string{"Jakarta", "Bandung", "Surabaya", "Yogyakarta", "Medan", "Denpasar", "Makassar", "Singapore", "Amsterdam", "Berlin", "London", "New York"}
+	fakeCountries = []string{"Indonesia", "Malaysia", "Singapore", "Netherlands", "Germany", "United Kingdom", "United States", "Japan", "Australia"}
+	fakeStreets   = []string{"Jl. Merdeka", "Jl. Sudirman", "Jl. Diponegoro", "Jl. Gatot Subroto", "Main Street", "Oak Avenue", "Station Road", "Park Lane"}
+	fakeCompanyA  = []string{"Nusantara", "Garuda", "Sinar", "Mega", "Global", "Prima", "Blue", "Bright", "North", "Summit"}
+	fakeCompanyB  = []string{"Teknologi", "Digital", "Logistics", "Systems", "Media", "Solutions", "Labs", "Works", "Group", "Partners"}
+	fakeCompanyC  = []string{"PT", "CV", "Inc.", "Ltd.", "LLC"}
+	fakeWords     = []string{"lorem", "ipsum", "dolor", "sit", "amet", "consectetur", "adipiscing", "elit", "sed", "do",
+		"eiusmod", "tempor", "incididunt", "ut", "labore", "et", "dolore", "magna", "aliqua", "enim",
+		"minim", "veniam", "quis", "nostrud", "exercitation", "ullamco", "laboris", "nisi", "aliquip", "commodo"}
+	fakeColors = []string{"red", "green", "blue", "yellow", "purple", "orange", "black", "white", "gray", "teal"}
+
+	// fakeEpoch membuat tanggal acak tidak bergantung pada hari test dijalankan
+	fakeEpoch = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// Faker generates deterministic fake data: the same seed always yields the same sequence
+type Faker struct {
+	mu   sync.Mutex
+	rnd  *rand.Rand
+	seed int64
+	seq  int
+}
+
+// NewFaker creates a generator with the given seed
+func NewFaker(seed int64) *Faker {
+	return &Faker{rnd: rand.New(rand.NewSource(seed)), seed: seed}
+}
+
+// NewFakerFromEnv seeds the generator from ZENO_FAKER_SEED, or from the clock when it isn't set
+func NewFakerFromEnv() *Faker {
+	if s, err := strconv.ParseInt(os.Getenv(FakerSeedEnv), 10, 64); err == nil {
+		return NewFaker(s)
+	}
+	return NewFaker(time.Now().UnixNano())
+}
+
+// Seed restarts the sequence with a new seed
+func (f *Faker) Seed(seed int64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rnd = rand.New(rand.NewSource(seed))
+	f.seed = seed
+	f.seq = 0
+}
+
+// CurrentSeed returns the seed the generator was started with
+func (f *Faker) CurrentSeed() int64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.seed
+}
+
+func (f *Faker) pick(list []string) string {
+	return list[f.rnd.Intn(len(list))]
+}
+
+func (f *Faker) words(n int) []string {
+	out := make([]string, n)
+	for i := range out {
+		out[i] = f.pick(fakeWords)
+	}
+	return out
+}
+
+func (f *Faker) sentence() string {
+	s := strings.Join(f.words(6+f.rnd.Intn(7)), " ")
+	return strings.ToUpper(s[:1]) + s[1:] + "."
+}
+
+func (f *Faker) emailLocal(first, last string) string {
+	return strings.ToLower(first + "." + last)
+}
+
+// fakeGenerators maps the fake.* names to their generator. They run with f.mu held.
+var fakeGenerators = map[string]func(f *Faker) interface{}{
+	"first_name": func(f *Faker) interface{} { return f.pick(fakeFirstNames) },
+	"last_name":  func(f *Faker) interface{} { return f.pick(fakeLastNames) },
+	"name": func(f *Faker) interface{} {
+		return f.pick(fakeFirstNames) + " " + f.pick(fakeLastNames)
+	},
+	"username": func(f *Faker) interface{} {
+		return strings.ToLower(f.pick(fakeFirstNames)) + strconv.Itoa(f.rnd.Intn(1000))
+	},
+	"email": func(f *Faker) interface{} {
+		return fmt.Sprintf("%s%d@%s", f.emailLocal(f.pick(fakeFirstNames), f.pick(fakeLastNames)), f.rnd.Intn(100), f.pick(fakeDomains))
+	},
+	// unique_email memakai nomor urut sehingga tidak pernah bentrok dengan unique index
+	"unique_email": func(f *Faker) interface{} {
+		f.seq++
+		return fmt.Sprintf("%s.%d@%s", f.emailLocal(f.pick(fakeFirstNames), f.pick(fakeLastNames)), f.seq, f.pick(fakeDomains))
+	},
+	"phone": func(f *Faker) interface{} {
+		return fmt.Sprintf("+62 8%02d-%04d-%04d", 11+f.rnd.Intn(89), f.rnd.Intn(10000), f.rnd.Intn(10000))
+	},
+	"address": func(f *Faker) interface{} {
+		return fmt.Sprintf("%s No. %d, %s", f.pick(fakeStreets), 1+f.rnd.Intn(200), f.pick(fakeCities))
+	},
+	"city":    func(f *Faker) interface{} { return f.pick(fakeCities) },
+	"country": func(f *Faker) interface{} { return f.pick(fakeCountries) },
+	"company": func(f *Faker) interface{} {
+		return fmt.Sprintf("%s %s %s", f.pick(fakeCompanyC), f.pick(fakeCompanyA), f.pick(fakeCompanyB))
+	},
+	"word":     func(f *Faker) interface{} { return f.pick(fakeWords) },
+	"sentence": func(f *Faker) interface{} { return f.sentence() },
+	"paragraph": func(f *Faker) interface{} {
+		sentences := make([]string, 3+f.rnd.Intn(3))
+		for i := range sentences {
+			sentences[i] = f.sentence()
+		}
+		return strings.Join(sentences, " ")
+	},
+	"slug":    func(f *Faker) interface{} { return strings.Join(f.words(3), "-") },
+	"number":  func(f *Faker) interface{} { return 1 + f.rnd.Intn(1000) },
+	"price":   func(f *Faker) interface{} { return float64(100+f.rnd.Intn(99900)) / 100 },
+	"boolean": func(f *Faker) interface{} { return f.rnd.Intn(2) == 1 },
+	"uuid": func(f *Faker) interface{} {
+		b := make([]byte, 16)
+		f.rnd.Read(b)
+		b[6] = (b[6] & 0x0f) | 0x40
+		b[8] = (b[8] & 0x3f) | 0x80
+		return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+	},
+	"date": func(f *Faker) interface{} {
+		return fakeEpoch.AddDate(0, 0, f.rnd.Intn(5*365)).Format("2006-01-02")
+	},
+	"datetime": func(f *Faker) interface{} {
+		return fakeEpoch.Add(time.Duration(f.rnd.Int63n(int64(5 * 365 * 24 * time.Hour)))).Truncate(time.Second).Format("2006-01-02 15:04:05")
+	},
+	"url": func(f *Faker) interface{} {
+		return fmt.Sprintf("https://%s/%s", f.pick(fakeDomains), strings.Join(f.words(2), "-"))
+	},
+	"color": func(f *Faker) interface{} { return f.pick(fakeColors) },
+	"sequence": func(f *Faker) interface{} {
+		f.seq++
+		return f.seq
+	},
+	// password menghasilkan hash bcrypt dari "password", cocok dengan auth.login
+	"password": func(f *Faker) interface{} {
+		hash, _ := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+		return string(hash)
+	},
+}
+
+// Generate returns a fake value of the given kind (name, email, sentence, ...)
+func (f *Faker) Generate(kind string) (interface{}, error) {
+	gen, ok := fakeGenerators[kind]
+	if !ok {
+		return nil, fmt.Errorf("unknown fake data type '%s'", kind)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return gen(f), nil
+}
+
+// FakeTypes lists the supported fake data types
+func FakeTypes() []string {
+	names := make([]string, 0, len(fakeGenerators))
+	for name := range fakeGenerators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func registerFakerSlots(eng *engine.Engine, faker *Faker) {
+	for _, kind := range FakeTypes() {
+		kind := kind
+		eng.Register("fake."+kind, func(ctx context.Context, node *engine.Node, scope *engine.Scope) error {
+			target := "fake"
+			for _, c := range node.Children {
+				if c.Name == "as" {
+					target = strings.TrimPrefix(coerce.ToString(c.Value), "$")
+				}
+			}
+			val, err := faker.Generate(kind)
+			if err != nil {
+				return err
+			}
+			scope.Set(target, val)
+			return nil
+		}, engine.SlotMeta{
+			Description: fmt.Sprintf("Generates a fake %s.", strings.ReplaceAll(kind, "_", " ")),
+			Example:     fmt.Sprintf("fake.%s\n  as: $value", kind),
+		})
+	}
+
+	// SLOT: fake.seed
+	eng.Register("fake.seed", func(ctx context.Context, node *engine.Node, scope *engine.Scope) error {
+		seed, err := strconv.ParseInt(coerce.ToString(resolveValue(node.Value, scope)), 10, 64)
+		if err != nil {
+			return fmt.Errorf("fake.seed: seed must be a number")
+		}
+		faker.Seed(seed)
+		return nil
+	}, engine.SlotMeta{
+		Description: "Restarts the fake data generator with a fixed seed.",
+		Example:     "fake.seed: 42",
+	})
+}
@@ -3,21 +3,40 @@ package slots
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"strings"
+	"time"
 	"github.com/nextcore/zeno-go/pkg/engine"
 	"github.com/nextcore/zeno-go/pkg/utils/coerce"
 
 	"github.com/nextcore/zenoengine/pkg/worker"
 )
 
-func RegisterJobSlots(eng *engine.Engine, queue worker.JobQueue, setConfig func([]string)) {
+// RegisterJobSlots mendaftarkan slot worker.config dan job.*. handlers menampung job.define;
+// nil berarti registry milik engine ini saja.
+func RegisterJobSlots(eng *engine.Engine, queue worker.JobQueue, setConfig func(worker.Options), handlers *worker.Handlers) {
+	if handlers == nil {
+		handlers = worker.NewHandlers()
+	}
 
 	// WORKER.CONFIG
 	eng.Register("worker.config", func(ctx context.Context, node *engine.Node, scope *engine.Scope) error {
 		if setConfig == nil {
 			return nil
 		}
+
+		// Bentuk lengkap: worker.config: { workers: 10, queues: 'high:3,default', priority: 'weighted', limits: { sms: 2 } }
+		if isWorkerOptionsNode(node) {
+			opts, err := parseWorkerOptions(node, scope)
+			if err != nil {
+				return fmt.Errorf("worker.config: %v", err)
+			}
+			setConfig(opts)
+			return nil
+		}
+
 		var queues []string
 		if node.Value != nil {
 			val := coerce.ToString(node.Value)
@@ -42,14 +61,21 @@ func RegisterJobSlots(eng *engine.Engine, queue worker.JobQueue, setConfig func(
 		}
 
 		if len(queues) > 0 {
-			setConfig(queues)
+			setConfig(worker.Options{Queues: queues})
 		}
 		return nil
 	}, engine.SlotMeta{
-		Description: "Configure worker queues.",
-		Example: `worker.config
-  - "high_priority"
-  - "default"`,
+		Description: "Configure worker queues, pool size, queue priority, per-queue limits, rate limits and timeouts.",
+		Example: `worker.config: {
+  workers: 10
+  queues: 'high:3,default:1'
+  priority: 'weighted'
+  limits: { sms: 2 }
+  rate_limits: { sms: '10/s' }
+  timeout: '5m'
+  timeouts: { reports: '30m' }
+  shutdown_grace: '30s'
+}`,
 	})
 
 	// JOB.ENQUEUE
@@ -61,6 +87,9 @@ func RegisterJobSlots(eng *engine.Engine, queue worker.JobQueue, setConfig func(
 
 		var queueName string = "default"
 		var payload interface{}
+		var opts worker.JobOptions
+		hasOpts := false
+		target, idTarget := "", ""
 
 		// Support shorthand: job.enqueue: "email_queue"
 		if node.Value != nil && fmt.Sprintf("%v", node.Value) != "" {
@@ -75,11 +104,29 @@ func RegisterJobSlots(eng *engine.Engine, queue worker.JobQueue, setConfig func(
 				// Payload bisa map kompleks, gunakan parseNodeValue
 				payload = parseNodeValue(c, scope)
 			}
+			if jobOptionNames[c.Name] {
+				if err := parseJobOption(&opts, c.Name, parseNodeValue(c, scope)); err != nil {
+					return fmt.Errorf("job.enqueue: %v", err)
+				}
+				hasOpts = true
+			}
+			if c.Name == "as" {
+				target = strings.TrimPrefix(coerce.ToString(c.Value), "$")
+			}
+			if c.Name == "id_as" {
+				idTarget = strings.TrimPrefix(coerce.ToString(c.Value), "$")
+			}
 		}
 
 		if payload == nil {
 			return fmt.Errorf("job.enqueue: payload is required")
 		}
+		if hasChild(node, "delay") && hasChild(node, "at") {
+			return fmt.Errorf("job.enqueue: use either delay or at, not both")
+		}
+		if opts.UniqueKey == "" && (hasChild(node, "unique_for") || hasChild(node, "on_duplicate")) {
+			return fmt.Errorf("job.enqueue: unique_for and on_duplicate need a unique_key")
+		}
 
 		// Marshal payload ke JSON string untuk disimpan di Redis List
 		jsonBytes, err := json.Marshal(payload)
@@ -87,19 +134,719 @@ func RegisterJobSlots(eng *engine.Engine, queue worker.JobQueue, setConfig func(
 			return fmt.Errorf("job.enqueue: failed to marshal payload: %v", err)
 		}
 
-		// Push ke Queue
-		err = queue.Push(ctx, queueName, jsonBytes)
-		if err != nil {
-			return err
+		// Push ke Queue. Opsi retry hanya didukung queue yang melacak job (DBQueue)
+		var id int64
+		if reliable, ok := queue.(worker.ReliableQueue); ok {
+			id, err = reliable.PushJob(ctx, queueName, jsonBytes, opts)
+		} else if hasOpts {
+			return fmt.Errorf("job.enqueue: this queue does not support tries/backoff/timeout/unique_key/rate_key")
+		} else {
+			err = queue.Push(ctx, queueName, jsonBytes)
 		}
 
-		return nil
+		return setQueued(scope, target, idTarget, id, err)
 	}, engine.SlotMeta{
 		Description: "Add a job to the background queue (Redis/DB).",
 		Example: `job.enqueue
   queue: "emails"
+  tries: 3
+  backoff: 'exponential:10s'
+  timeout: '2m'
+  delay: '10m'
+  unique_key: 'welcome-' + $user.id
   payload:
     to: "budi@example.com"
     subject: "Welcome"`,
+		Inputs: map[string]engine.InputMeta{
+			"queue":        {Description: "Queue name (Default: 'default')", Required: false},
+			"payload":      {Description: "Job payload: { script_path, data }", Required: true},
+			"tries":        {Description: "Maximum attempts before the job moves to failed_jobs (Default: 1)", Required: false},
+			"backoff":      {Description: "Delay between attempts: seconds, '30s', 'exponential', 'exponential:10s' or { type, delay, max }", Required: false},
+			"timeout":      {Description: "Maximum duration of one attempt, e.g. 90 or '2m'", Required: false},
+			"delay":        {Description: "Run the job after this duration, e.g. '10m'", Required: false},
+			"at":           {Description: "Run the job at this date/time (datetime, date string or Unix timestamp)", Required: false},
+			"unique_key":   {Description: "Skip the job while another job with this key is queued or running", Required: false},
+			"unique_for":   {Description: "Keep the unique_key locked for this long after enqueue, also after the job finished, e.g. '10m'", Required: false},
+			"on_duplicate": {Description: "'skip' (Default) keeps the queued job; 'replace' swaps a pending job for this one", Required: false},
+			"rate_key":     {Description: "Count the job against the worker rate limit with this name, next to the limit of its queue", Required: false},
+			"as":           {Description: "Variable that receives true when the job was queued, false when it was skipped as a duplicate", Required: false},
+			"id_as":        {Description: "Variable that receives the job ID, for job.cancel (0 when skipped as a duplicate)", Required: false},
+		},
+	})
+
+	// JOB.DEFINE
+	eng.Register("job.define", func(ctx context.Context, node *engine.Node, scope *engine.Scope) error {
+		name := coerce.ToString(resolveValue(node.Value, scope))
+		handler := &worker.Handler{Name: name, Queue: "default", Source: fmt.Sprintf("%s:%d", node.Filename, node.Line)}
+
+		// Opsi default dikenali dari namanya; do: (atau sisa children) adalah body handler
+		for _, c := range node.Children {
+			switch c.Name {
+			case "queue":
+				handler.Queue = coerce.ToString(parseNodeValue(c, scope))
+			case "tries", "backoff", "timeout", "rate_key":
+				if err := parseJobOption(&handler.Options, c.Name, parseNodeValue(c, scope)); err != nil {
+					return fmt.Errorf("job.define '%s': %v", name, err)
+				}
+			case "do":
+				handler.Body = c.Children
+			default:
+				if !hasChild(node, "do") {
+					handler.Body = append(handler.Body, c)
+				}
+			}
+		}
+
+		if err := handlers.Define(handler); err != nil {
+			return fmt.Errorf("job.define: %v", err)
+		}
+		return nil
+	}, engine.SlotMeta{
+		Description: "Define a named job handler. Jobs dispatched by name keep working when script files move.",
+		Example: `job.define: 'send_welcome' {
+  queue: 'emails'
+  tries: 3
+  do: {
+    mail.send: { to: $email, subject: 'Welcome' }
+  }
+}`,
+		Inputs: map[string]engine.InputMeta{
+			"queue":    {Description: "Default queue (Default: 'default')", Required: false},
+			"tries":    {Description: "Default maximum attempts", Required: false},
+			"backoff":  {Description: "Default delay between attempts", Required: false},
+			"timeout":  {Description: "Default maximum duration of one attempt", Required: false},
+			"rate_key": {Description: "Default rate limit name", Required: false},
+			"do":       {Description: "Handler body. The dispatched data is available as variables, next to $job_id and $job_attempt", Required: true},
+		},
+	})
+
+	// JOB.DISPATCH
+	eng.Register("job.dispatch", func(ctx context.Context, node *engine.Node, scope *engine.Scope) error {
+		if queue == nil {
+			return fmt.Errorf("job.dispatch failed: Queue is not available")
+		}
+		name := coerce.ToString(resolveValue(node.Value, scope))
+		handler, ok := handlers.Get(name)
+		if !ok {
+			return fmt.Errorf("job.dispatch: job handler '%s' is not defined (job.define: '%s' { ... })", name, name)
+		}
+
+		// Opsi job.define menjadi default, opsi job.dispatch menimpanya
+		queueName := handler.Queue
+		opts := handler.Options
+		data := map[string]interface{}{}
+		target, idTarget := "", ""
+		for _, c := range node.Children {
+			switch {
+			case c.Name == "queue":
+				queueName = coerce.ToString(parseNodeValue(c, scope))
+			case c.Name == "data":
+				m, ok := parseNodeValue(c, scope).(map[string]interface{})
+				if !ok {
+					return fmt.Errorf("job.dispatch: data must be a map")
+				}
+				data = m
+			case c.Name == "as":
+				target = strings.TrimPrefix(coerce.ToString(c.Value), "$")
+			case c.Name == "id_as":
+				idTarget = strings.TrimPrefix(coerce.ToString(c.Value), "$")
+			case jobOptionNames[c.Name]:
+				if err := parseJobOption(&opts, c.Name, parseNodeValue(c, scope)); err != nil {
+					return fmt.Errorf("job.dispatch: %v", err)
+				}
+			}
+		}
+		if hasChild(node, "delay") && hasChild(node, "at") {
+			return fmt.Errorf("job.dispatch: use either delay or at, not both")
+		}
+		if !hasChild(node, "unique_key") && (hasChild(node, "unique_for") || hasChild(node, "on_duplicate")) {
+			return fmt.Errorf("job.dispatch: unique_for and on_duplicate need a unique_key")
+		}
+
+		id, err := worker.EnqueueID(ctx, queue, queueName, worker.JobPayload{Handler: name, Data: data}, opts)
+		if err != nil && !errors.Is(err, worker.ErrDuplicateJob) {
+			return fmt.Errorf("job.dispatch: %w", err)
+		}
+		return setQueued(scope, target, idTarget, id, err)
+	}, engine.SlotMeta{
+		Description: "Queue a job for a handler defined with job.define.",
+		Example: `job.dispatch: 'send_welcome' {
+  data: { email: $user.email }
+  delay: '10m'
+}`,
+		Inputs: map[string]engine.InputMeta{
+			"data":         {Description: "Variables for the handler", Required: false},
+			"queue":        {Description: "Queue name (Default: queue of job.define)", Required: false},
+			"tries":        {Description: "Maximum attempts (Default: tries of job.define)", Required: false},
+			"backoff":      {Description: "Delay between attempts (Default: backoff of job.define)", Required: false},
+			"timeout":      {Description: "Maximum duration of one attempt (Default: timeout of job.define)", Required: false},
+			"delay":        {Description: "Run the job after this duration, e.g. '10m'", Required: false},
+			"at":           {Description: "Run the job at this date/time", Required: false},
+			"unique_key":   {Description: "Skip the job while another job with this key is queued or running", Required: false},
+			"unique_for":   {Description: "Keep the unique_key locked for this long after dispatch", Required: false},
+			"on_duplicate": {Description: "'skip' (Default) or 'replace'", Required: false},
+			"rate_key":     {Description: "Rate limit name (Default: rate_key of job.define)", Required: false},
+			"as":           {Description: "Variable that receives true when the job was queued, false when it was skipped as a duplicate", Required: false},
+			"id_as":        {Description: "Variable that receives the job ID, for job.cancel (0 when skipped as a duplicate)", Required: false},
+		},
+	})
+
+	// JOB.CHAIN
+	eng.Register("job.chain", func(ctx context.Context, node *engine.Node, scope *engine.Scope) error {
+		if queue == nil {
+			return fmt.Errorf("job.chain failed: Queue is not available")
+		}
+		queueName := "default"
+		if node.Value != nil && fmt.Sprintf("%v", node.Value) != "" {
+			queueName = coerce.ToString(resolveValue(node.Value, scope))
+		}
+		var catch, catchHandler string
+		for _, c := range node.Children {
+			switch c.Name {
+			case "queue":
+				queueName = coerce.ToString(parseNodeValue(c, scope))
+			case "catch":
+				var err error
+				if catch, catchHandler, err = parseJobCallback(handlers, c.Name, parseNodeValue(c, scope)); err != nil {
+					return fmt.Errorf("job.chain: %v", err)
+				}
+			}
+		}
+
+		steps, err := parseJobSteps(node, scope, queueName, handlers)
+		if err != nil {
+			return fmt.Errorf("job.chain: %v", err)
+		}
+		if len(steps) == 0 {
+			return fmt.Errorf("job.chain: at least one job is required")
+		}
+
+		// Hanya job pertama yang di-push; sisanya ikut di payload dan di-push worker satu per satu
+		first := steps[0]
+		payload := first.Payload()
+		payload.Chain = steps[1:]
+		payload.ChainCatch, payload.ChainCatchHandler = catch, catchHandler
+		return worker.Enqueue(ctx, queue, first.Queue, payload, first.Options())
+	}, engine.SlotMeta{
+		Description: "Run jobs one after another. The next job starts only when the previous one succeeded; on a permanent failure the chain stops and 'catch' runs.",
+		Example: `job.chain: 'images' {
+  job: { script_path: 'src/jobs/resize.zl', data: { photo_id: $photo.id } }
+  job: { script_path: 'src/jobs/notify_user.zl', data: { user_id: $user.id } }
+  catch: 'src/jobs/alert_ops.zl'
+}`,
+		Inputs: map[string]engine.InputMeta{
+			"queue": {Description: "Default queue of the jobs (Default: 'default')", Required: false},
+			"job":   {Description: "One job: { script_path or handler, data, queue, tries, backoff, timeout }. Repeat for every step", Required: false},
+			"jobs":  {Description: "List of jobs, as an alternative to repeated 'job'", Required: false},
+			"catch": {Description: "Script (or { handler: 'name' }) pushed when a job fails permanently; receives the job data plus $error and $failed_script / $failed_handler", Required: false},
+		},
+	})
+
+	// JOB.BATCH
+	eng.Register("job.batch", func(ctx context.Context, node *engine.Node, scope *engine.Scope) error {
+		bq, ok := queue.(worker.BatchQueue)
+		if !ok {
+			return fmt.Errorf("job.batch failed: this queue does not support batches")
+		}
+		queueName := "default"
+		if node.Value != nil && fmt.Sprintf("%v", node.Value) != "" {
+			queueName = coerce.ToString(resolveValue(node.Value, scope))
+		}
+		name := ""
+		target := "batch_id"
+		var opts worker.BatchOptions
+		for _, c := range node.Children {
+			val := parseNodeValue(c, scope)
+			switch c.Name {
+			case "queue":
+				queueName = coerce.ToString(val)
+			case "name":
+				name = coerce.ToString(val)
+			case "then", "catch", "finally":
+				script, handler, err := parseJobCallback(handlers, c.Name, val)
+				if err != nil {
+					return fmt.Errorf("job.batch: %v", err)
+				}
+				switch c.Name {
+				case "then":
+					opts.Then, opts.ThenHandler = script, handler
+				case "catch":
+					opts.Catch, opts.CatchHandler = script, handler
+				default:
+					opts.Finally, opts.FinallyHandler = script, handler
+				}
+			case "data":
+				m, ok := val.(map[string]interface{})
+				if !ok {
+					return fmt.Errorf("job.batch: data must be a map")
+				}
+				opts.Data = m
+			case "as":
+				target = strings.TrimPrefix(coerce.ToString(c.Value), "$")
+			}
+		}
+
+		steps, err := parseJobSteps(node, scope, queueName, handlers)
+		if err != nil {
+			return fmt.Errorf("job.batch: %v", err)
+		}
+		if len(steps) == 0 {
+			return fmt.Errorf("job.batch: at least one job is required")
+		}
+
+		batches := bq.Batches()
+		id, err := batches.Create(ctx, name, queueName, len(steps), opts)
+		if err != nil {
+			return fmt.Errorf("job.batch: %v", err)
+		}
+		for i, step := range steps {
+			payload := step.Payload()
+			payload.BatchID = id
+			err := worker.Enqueue(ctx, queue, step.Queue, payload, step.Options())
+			if err != nil {
+				// Job yang tidak ter-push tidak akan pernah selesai; keluarkan dari hitungan batch
+				if errDiscard := worker.DiscardBatchJobs(ctx, bq, id, len(steps)-i); errDiscard != nil {
+					slog.Error("❌ Failed to update batch", "batch", id, "error", errDiscard)
+				}
+				return fmt.Errorf("job.batch: %v", err)
+			}
+		}
+
+		scope.Set(target, id)
+		return nil
+	}, engine.SlotMeta{
+		Description: "Run jobs in parallel and track their progress. 'then' runs when all jobs succeeded, 'catch' on the first failure, 'finally' when every job has finished.",
+		Example: `job.batch: 'imports' {
+  name: 'Import users'
+  jobs: $chunks
+  then: 'src/jobs/import_done.zl'
+  catch: 'src/jobs/import_failed.zl'
+  finally: 'src/jobs/import_cleanup.zl'
+  data: { user_id: $auth.id }
+  as: $batch_id
+}`,
+		Inputs: map[string]engine.InputMeta{
+			"queue":   {Description: "Default queue of the jobs and callbacks (Default: 'default')", Required: false},
+			"name":    {Description: "Name shown in the batch progress", Required: false},
+			"job":     {Description: "One job: { script_path or handler, data, queue, tries, backoff, timeout }. Repeat for every job", Required: false},
+			"jobs":    {Description: "List of jobs, as an alternative to repeated 'job'", Required: false},
+			"then":    {Description: "Script (or { handler: 'name' }) pushed when every job succeeded", Required: false},
+			"catch":   {Description: "Script (or { handler: 'name' }) pushed on the first permanent failure; receives $error and $failed_script / $failed_handler", Required: false},
+			"finally": {Description: "Script (or { handler: 'name' }) pushed when every job has finished, successfully or not", Required: false},
+			"data":    {Description: "Data passed to the callbacks, next to $batch", Required: false},
+			"as":      {Description: "Variable that receives the batch ID (Default: $batch_id)", Required: false},
+		},
+	})
+
+	// JOB.BATCH_STATUS
+	eng.Register("job.batch_status", func(ctx context.Context, node *engine.Node, scope *engine.Scope) error {
+		bq, ok := queue.(worker.BatchQueue)
+		if !ok {
+			return fmt.Errorf("job.batch_status failed: this queue does not support batches")
+		}
+		idVal := resolveValue(node.Value, scope)
+		target := "batch"
+		for _, c := range node.Children {
+			switch c.Name {
+			case "id":
+				idVal = parseNodeValue(c, scope)
+			case "as":
+				target = strings.TrimPrefix(coerce.ToString(c.Value), "$")
+			}
+		}
+		id, err := coerce.ToInt64(idVal)
+		if err != nil {
+			return fmt.Errorf("job.batch_status: invalid batch id '%v'", idVal)
+		}
+
+		batch, err := bq.Batches().Find(ctx, id)
+		if err != nil {
+			return fmt.Errorf("job.batch_status: %v", err)
+		}
+		if batch == nil {
+			scope.Set(target, nil)
+			return nil
+		}
+		scope.Set(target, batch.ToMap())
+		return nil
+	}, engine.SlotMeta{
+		Description: "Read the progress of a batch. The result is nil when the batch does not exist.",
+		Example: `job.batch_status: $params.id {
+  as: $batch
+}
+http.ok: $batch`,
+		Inputs: map[string]engine.InputMeta{
+			"id": {Description: "Batch ID (or pass it as the slot value)", Required: false},
+			"as": {Description: "Variable that receives { id, name, total_jobs, pending_jobs, failed_jobs, processed_jobs, progress, finished, ... } (Default: $batch)", Required: false},
+		},
 	})
+
+	// JOB.CANCEL
+	eng.Register("job.cancel", func(ctx context.Context, node *engine.Node, scope *engine.Scope) error {
+		cq, ok := queue.(worker.CancellableQueue)
+		if !ok {
+			return fmt.Errorf("job.cancel failed: this queue does not support cancelling jobs")
+		}
+		idVal := resolveValue(node.Value, scope)
+		target := ""
+		for _, c := range node.Children {
+			switch c.Name {
+			case "id":
+				idVal = parseNodeValue(c, scope)
+			case "as":
+				target = strings.TrimPrefix(coerce.ToString(c.Value), "$")
+			}
+		}
+		id, err := coerce.ToInt64(idVal)
+		if err != nil {
+			return fmt.Errorf("job.cancel: invalid job id '%v'", idVal)
+		}
+
+		found, err := cq.Cancel(ctx, id)
+		if err != nil {
+			return fmt.Errorf("job.cancel: %v", err)
+		}
+		if target != "" {
+			scope.Set(target, found)
+		}
+		return nil
+	}, engine.SlotMeta{
+		Description: "Cancel a queued or running job. A queued job moves to failed_jobs at once; a running job is stopped by its worker within a second. Cancelled jobs are not retried.",
+		Example: `job.cancel: $export.job_id {
+  as: $cancelled
+}`,
+		Inputs: map[string]engine.InputMeta{
+			"id": {Description: "Job ID from id_as of job.enqueue / job.dispatch, or $job_id (or pass it as the slot value)", Required: false},
+			"as": {Description: "Variable that receives true when the job was found, false when it already finished", Required: false},
+		},
+	})
+}
+
+// parseJobSteps membaca job dari child 'job' (boleh berulang) dan 'jobs' (list) milik job.chain / job.batch.
+// Job dengan handler memakai tries/backoff/timeout dari job.define sebagai default, seperti job.dispatch.
+func parseJobSteps(node *engine.Node, scope *engine.Scope, queueName string, handlers *worker.Handlers) ([]worker.ChainStep, error) {
+	var raw []interface{}
+	for _, c := range node.Children {
+		switch c.Name {
+		case "job":
+			raw = append(raw, parseNodeValue(c, scope))
+		case "jobs":
+			list, err := coerce.ToSlice(parseNodeValue(c, scope))
+			if err != nil {
+				return nil, fmt.Errorf("jobs must be a list")
+			}
+			raw = append(raw, list...)
+		}
+	}
+
+	steps := make([]worker.ChainStep, 0, len(raw))
+	for i, r := range raw {
+		m, ok := r.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("job #%d must be a map with script_path or handler", i+1)
+		}
+		script, handlerName, err := jobTarget(handlers, m)
+		if err != nil {
+			return nil, fmt.Errorf("job #%d: %v", i+1, err)
+		}
+		stepQueue := queueName
+		if q, ok := m["queue"]; ok && q != nil {
+			stepQueue = coerce.ToString(q)
+		}
+		var data map[string]interface{}
+		if d, ok := m["data"]; ok && d != nil {
+			if data, ok = d.(map[string]interface{}); !ok {
+				return nil, fmt.Errorf("job #%d: data must be a map", i+1)
+			}
+		}
+
+		var opts worker.JobOptions
+		if handler, ok := handlers.Get(handlerName); ok {
+			opts = handler.Options
+		}
+		for _, name := range []string{"tries", "backoff", "timeout"} {
+			if v, ok := m[name]; ok && v != nil {
+				if err := parseJobOption(&opts, name, v); err != nil {
+					return nil, fmt.Errorf("job #%d: %v", i+1, err)
+				}
+			}
+		}
+		step := worker.NewChainStep(stepQueue, script, data, opts)
+		step.Handler = handlerName
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+// jobTarget membaca script_path atau handler (nama job.define) dari satu job; tepat satu harus diisi
+func jobTarget(handlers *worker.Handlers, m map[string]interface{}) (script, handler string, err error) {
+	if v, ok := m["script_path"]; ok && v != nil {
+		script = coerce.ToString(v)
+	}
+	if v, ok := m["handler"]; ok && v != nil {
+		handler = coerce.ToString(v)
+	}
+	switch {
+	case script == "" && handler == "":
+		return "", "", fmt.Errorf("script_path or handler is required")
+	case script != "" && handler != "":
+		return "", "", fmt.Errorf("use either script_path or handler, not both")
+	case handler != "":
+		if _, ok := handlers.Get(handler); !ok {
+			return "", "", fmt.Errorf("job handler '%s' is not defined (job.define: '%s' { ... })", handler, handler)
+		}
+	}
+	return script, handler, nil
+}
+
+// parseJobCallback membaca callback chain / batch: path script, atau { handler: 'name' } / { script_path: '...' }
+func parseJobCallback(handlers *worker.Handlers, name string, val interface{}) (script, handler string, err error) {
+	if m, ok := val.(map[string]interface{}); ok {
+		if script, handler, err = jobTarget(handlers, m); err != nil {
+			return "", "", fmt.Errorf("%s: %v", name, err)
+		}
+		return script, handler, nil
+	}
+	return coerce.ToString(val), "", nil
+}
+
+// parseJobOption mengisi tries/backoff/timeout dari nilai slot
+func parseJobOption(opts *worker.JobOptions, name string, val interface{}) error {
+	switch name {
+	case "tries":
+		n, err := coerce.ToInt(val)
+		if err != nil || n < 1 {
+			return fmt.Errorf("tries must be a number of at least 1")
+		}
+		opts.Tries = n
+	case "timeout":
+		d, err := worker.ParseDelay(coerce.ToString(val))
+		if err != nil {
+			return fmt.Errorf("invalid timeout: %v", err)
+		}
+		opts.Timeout = d
+	case "delay":
+		d, err := worker.ParseDelay(coerce.ToString(val))
+		if err != nil {
+			return fmt.Errorf("invalid delay: %v", err)
+		}
+		opts.AvailableAt = time.Now().Add(d)
+	case "at":
+		t, err := jobTime(val)
+		if err != nil {
+			return fmt.Errorf("invalid at: %v", err)
+		}
+		opts.AvailableAt = t
+	case "backoff":
+		// Bentuk map: { type: 'exponential', delay: '10s', max: '1h' }
+		if m, ok := val.(map[string]interface{}); ok {
+			spec := coerce.ToString(m["type"])
+			if spec == "" || spec == "<nil>" {
+				spec = "fixed"
+			}
+			if d, ok := m["delay"]; ok {
+				spec += ":" + coerce.ToString(d)
+			}
+			if max, ok := m["max"]; ok {
+				if !strings.Contains(spec, ":") {
+					spec += ":1s"
+				}
+				spec += ":" + coerce.ToString(max)
+			}
+			val = spec
+		}
+		b, err := worker.ParseBackoff(coerce.ToString(val))
+		if err != nil {
+			return err
+		}
+		opts.Backoff = b
+	case "unique_key":
+		opts.UniqueKey = coerce.ToString(val)
+		if opts.UniqueKey == "" || opts.UniqueKey == "<nil>" {
+			return fmt.Errorf("unique_key must not be empty")
+		}
+	case "unique_for":
+		d, err := worker.ParseDelay(coerce.ToString(val))
+		if err != nil {
+			return fmt.Errorf("invalid unique_for: %v", err)
+		}
+		opts.UniqueFor = d
+	case "on_duplicate":
+		switch mode := strings.ToLower(coerce.ToString(val)); mode {
+		case "skip":
+			opts.ReplaceUnique = false
+		case "replace":
+			opts.ReplaceUnique = true
+		default:
+			return fmt.Errorf("on_duplicate must be 'skip' or 'replace', got '%s'", mode)
+		}
+	case "rate_key":
+		opts.RateKey = coerce.ToString(val)
+	}
+	return nil
+}
+
+// jobTime membaca waktu eksekusi dari time.Time, Unix timestamp atau string tanggal
+func jobTime(val interface{}) (time.Time, error) {
+	switch v := val.(type) {
+	case time.Time:
+		return v, nil
+	case int, int64, float64:
+		n, _ := coerce.ToInt64(v)
+		return time.Unix(n, 0), nil
+	}
+	return parseFlexDate(coerce.ToString(val))
+}
+
+// jobOptionNames adalah opsi job.enqueue / job.dispatch yang dibaca parseJobOption
+var jobOptionNames = map[string]bool{
+	"tries": true, "backoff": true, "timeout": true, "delay": true, "at": true,
+	"unique_key": true, "unique_for": true, "on_duplicate": true, "rate_key": true,
+}
+
+// setQueued mengisi variabel 'as' dengan true, atau false bila job dilewati sebagai duplikat,
+// dan variabel 'id_as' dengan ID job. Job kembar (double-click, webhook yang dikirim ulang) bukan error.
+func setQueued(scope *engine.Scope, target, idTarget string, id int64, err error) error {
+	queued := true
+	if errors.Is(err, worker.ErrDuplicateJob) {
+		queued, err = false, nil
+	}
+	if err != nil {
+		return err
+	}
+	if target != "" {
+		scope.Set(target, queued)
+	}
+	if idTarget != "" {
+		scope.Set(idTarget, id)
+	}
+	return nil
+}
+
+func hasChild(node *engine.Node, name string) bool {
+	for _, c := range node.Children {
+		if c.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+var workerOptionKeys = map[string]bool{
+	"workers": true, "concurrency": true, "queues": true, "priority": true, "weights": true, "limits": true, "rate_limits": true,
+	"timeout": true, "timeouts": true, "shutdown_grace": true,
+}
+
+func isWorkerOptionsNode(node *engine.Node) bool {
+	for _, c := range node.Children {
+		if workerOptionKeys[c.Name] {
+			return true
+		}
+	}
+	return false
+}
+
+// parseWorkerOptions membaca bentuk map dari worker.config
+func parseWorkerOptions(node *engine.Node, scope *engine.Scope) (worker.Options, error) {
+	opts := worker.Options{Weights: map[string]int{}}
+	for _, c := range node.Children {
+		val := parseNodeValue(c, scope)
+		switch c.Name {
+		case "workers", "concurrency":
+			n, err := coerce.ToInt(val)
+			if err != nil || n < 1 {
+				return opts, fmt.Errorf("%s must be a positive number", c.Name)
+			}
+			opts.Concurrency = n
+		case "queues":
+			// 'high:3,default' atau daftar queue
+			if list, ok := val.([]interface{}); ok {
+				for _, q := range list {
+					opts.Queues = append(opts.Queues, coerce.ToString(q))
+				}
+				continue
+			}
+			queues, weights, err := worker.ParseQueueList(coerce.ToString(val))
+			if err != nil {
+				return opts, err
+			}
+			opts.Queues = queues
+			for q, w := range weights {
+				opts.Weights[q] = w
+			}
+		case "priority":
+			opts.Priority = strings.ToLower(coerce.ToString(val))
+		case "weights", "limits":
+			m, ok := val.(map[string]interface{})
+			if !ok {
+				return opts, fmt.Errorf("%s must be a map of queue: number", c.Name)
+			}
+			target := opts.Weights
+			if c.Name == "limits" {
+				opts.Limits = map[string]int{}
+				target = opts.Limits
+			}
+			for q, v := range m {
+				n, err := coerce.ToInt(v)
+				if err != nil {
+					return opts, fmt.Errorf("%s.%s must be a number", c.Name, q)
+				}
+				target[q] = n
+			}
+		case "rate_limits":
+			// { sms: '10/s', mailgun: '300/m' } atau 'sms:10/s,mailgun:300/m'
+			m, ok := val.(map[string]interface{})
+			if !ok {
+				limits, err := worker.ParseRateLimits(coerce.ToString(val))
+				if err != nil {
+					return opts, err
+				}
+				opts.RateLimits = limits
+				continue
+			}
+			opts.RateLimits = map[string]worker.Rate{}
+			for name, v := range m {
+				r, err := worker.ParseRate(coerce.ToString(v))
+				if err != nil {
+					return opts, fmt.Errorf("rate_limits.%s: %v", name, err)
+				}
+				opts.RateLimits[name] = r
+			}
+		case "timeout", "shutdown_grace":
+			d, err := worker.ParseDelay(coerce.ToString(val))
+			if err != nil {
+				return opts, fmt.Errorf("invalid %s: %v", c.Name, err)
+			}
+			if c.Name == "timeout" {
+				opts.Timeout = d
+			} else {
+				opts.ShutdownGrace = d
+			}
+		case "timeouts":
+			// { reports: '10m', sms: 30 } atau 'reports:10m,sms:30s'
+			m, ok := val.(map[string]interface{})
+			if !ok {
+				timeouts, err := worker.ParseQueueTimeouts(coerce.ToString(val))
+				if err != nil {
+					return opts, err
+				}
+				opts.Timeouts = timeouts
+				continue
+			}
+			opts.Timeouts = map[string]time.Duration{}
+			for q, v := range m {
+				d, err := worker.ParseDelay(coerce.ToString(v))
+				if err != nil {
+					return opts, fmt.Errorf("timeouts.%s: %v", q, err)
+				}
+				opts.Timeouts[q] = d
+			}
+		}
+	}
+
+	// Bobot tanpa priority berarti weighted
+	if opts.Priority == "" && len(opts.Weights) > 0 {
+		opts.Priority = worker.PriorityWeighted
+	}
+	return opts, opts.Validate()
 }
@@ -2,9 +2,19 @@ package slots
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
 	"testing"
+	"time"
 	"github.com/nextcore/zeno-go/pkg/engine"
+	"github.com/nextcore/zeno-go/pkg/utils/coerce"
+	"github.com/nextcore/zenoengine/pkg/dbmanager"
+	"github.com/nextcore/zenoengine/pkg/worker"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -31,7 +41,7 @@ func (m *MockJobQueue) Close() error {
 func TestJobSlots(t *testing.T) {
 	eng := engine.NewEngine()
 	mockQueue := &MockJobQueue{}
-	RegisterJobSlots(eng, mockQueue, nil)
+	RegisterJobSlots(eng, mockQueue, nil, nil)
 
 	t.Run("job.enqueue", func(t *testing.T) {
 		scope := engine.NewScope(nil)
@@ -56,3 +66,595 @@ func TestJobSlots(t *testing.T) {
 		assert.Equal(t, "email", received["task"])
 	})
 }
+
+func newTestJobQueue(t *testing.T) (*worker.DBQueue, *sql.DB) {
+	dbMgr := dbmanager.NewDBManager()
+	if err := dbMgr.AddConnection("internal", "sqlite", ":memory:", 1, 1); err != nil {
+		t.Fatalf("Failed to create in-memory db: %v", err)
+	}
+	t.Cleanup(func() { dbMgr.Close() })
+	return worker.NewDBQueue(dbMgr, "internal"), dbMgr.GetConnection("internal")
+}
+
+func TestJobRetries(t *testing.T) {
+	queue, db := newTestJobQueue(t)
+	ctx := context.Background()
+
+	eng := engine.NewEngine()
+	RegisterJobSlots(eng, queue, nil, nil)
+
+	scope := engine.NewScope(nil)
+	err := eng.Execute(ctx, &engine.Node{
+		Name: "job.enqueue",
+		Children: []*engine.Node{
+			{Name: "queue", Value: "'mail'"},
+			{Name: "tries", Value: "2"},
+			{Name: "backoff", Value: "'exponential:1h'"},
+			{Name: "timeout", Value: "'90s'"},
+			{Name: "payload", Children: []*engine.Node{{Name: "script_path", Value: "'src/jobs/mail.zl'"}}},
+		},
+	}, scope)
+	assert.NoError(t, err)
+
+	job, err := queue.Reserve(ctx, []string{"mail"}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, job.Attempts)
+	assert.Equal(t, 2, job.Options.Tries)
+	assert.Equal(t, 90*time.Second, job.Options.Timeout)
+	assert.Equal(t, worker.Backoff{Exponential: true, Delay: time.Hour}, job.Options.Backoff)
+	assert.True(t, job.CanRetry())
+
+	// Setelah di-release, job baru tersedia lagi setelah backoff
+	assert.NoError(t, queue.Release(ctx, job, job.Options.Backoff.After(job.Attempts), errors.New("smtp down")))
+	short, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	_, err = queue.Reserve(short, []string{"mail"}, false)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// Job yang sudah dilepas tidak bisa di-ack lagi oleh percobaan yang sama
+	assert.ErrorIs(t, queue.Release(ctx, job, 0, errors.New("smtp down")), worker.ErrLeaseLost)
+	_, err = db.Exec("UPDATE jobs SET available_at = 0")
+	assert.NoError(t, err)
+	job, err = queue.Reserve(ctx, []string{"mail"}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, job.Attempts)
+	assert.False(t, job.CanRetry())
+
+	assert.NoError(t, queue.Fail(ctx, job, errors.New("smtp down"), "script: src/jobs/mail.zl"))
+	failed, err := queue.FailedJobs(ctx, "")
+	assert.NoError(t, err)
+	if assert.Len(t, failed, 1) {
+		assert.Equal(t, "mail", failed[0].Queue)
+		assert.Equal(t, 2, failed[0].Attempts)
+		assert.Equal(t, "smtp down", failed[0].Error)
+		assert.False(t, failed[0].FailedAt.IsZero())
+	}
+
+	// queue:retry mengembalikan job dengan percobaan dari nol dan opsi aslinya
+	n, err := queue.RetryFailed(ctx, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	job, err = queue.Reserve(ctx, nil, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, job.Attempts)
+	assert.Equal(t, 2, job.Options.Tries)
+	assert.NoError(t, queue.Complete(ctx, job))
+
+	_, err = queue.PushJob(ctx, "mail", []byte("{}"), worker.JobOptions{Tries: 1})
+	assert.NoError(t, err)
+	job, err = queue.Reserve(ctx, nil, false)
+	assert.NoError(t, err)
+	assert.NoError(t, queue.Fail(ctx, job, errors.New("x"), ""))
+	flushed, err := queue.FlushFailed(ctx, "")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), flushed)
+}
+
+func TestJobBackoffOption(t *testing.T) {
+	opts := worker.JobOptions{}
+	assert.NoError(t, parseJobOption(&opts, "backoff", "exponential:10s:1m"))
+	assert.Equal(t, worker.Backoff{Exponential: true, Delay: 10 * time.Second, Max: time.Minute}, opts.Backoff)
+
+	assert.NoError(t, parseJobOption(&opts, "backoff", map[string]interface{}{"type": "exponential", "delay": "5s"}))
+	assert.Equal(t, worker.Backoff{Exponential: true, Delay: 5 * time.Second}, opts.Backoff)
+
+	assert.Error(t, parseJobOption(&opts, "backoff", "sometimes"))
+}
+
+func TestJobDelay(t *testing.T) {
+	queue, _ := newTestJobQueue(t)
+	ctx := context.Background()
+
+	eng := engine.NewEngine()
+	RegisterJobSlots(eng, queue, nil, nil)
+
+	payload := &engine.Node{Name: "payload", Children: []*engine.Node{{Name: "script_path", Value: "'src/jobs/remind.zl'"}}}
+	scope := engine.NewScope(nil)
+	scope.Set("trial_end", time.Now().Add(-time.Minute))
+
+	// Belum tersedia: delay 10 menit
+	assert.NoError(t, eng.Execute(ctx, &engine.Node{Name: "job.enqueue", Value: "later", Children: []*engine.Node{
+		{Name: "delay", Value: "'10m'"}, payload,
+	}}, scope))
+	short, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	_, err := queue.Reserve(short, []string{"later"}, false)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// at di masa lalu langsung tersedia
+	assert.NoError(t, eng.Execute(ctx, &engine.Node{Name: "job.enqueue", Value: "due", Children: []*engine.Node{
+		{Name: "at", Value: "$trial_end"}, payload,
+	}}, scope))
+	job, err := queue.Reserve(ctx, []string{"due"}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "due", job.Queue)
+
+	err = eng.Execute(ctx, &engine.Node{Name: "job.enqueue", Children: []*engine.Node{
+		{Name: "delay", Value: "'1m'"}, {Name: "at", Value: "'2030-01-01'"}, payload,
+	}}, scope)
+	assert.Error(t, err)
+
+	at, err := jobTime("2030-01-02 08:00:00")
+	assert.NoError(t, err)
+	assert.Equal(t, 2030, at.Year())
+}
+
+func TestWorkerConfig(t *testing.T) {
+	var got worker.Options
+	eng := engine.NewEngine()
+	RegisterJobSlots(eng, &MockJobQueue{}, func(opts worker.Options) { got = opts }, nil)
+	ctx := context.Background()
+
+	t.Run("queue list", func(t *testing.T) {
+		err := eng.Execute(ctx, &engine.Node{Name: "worker.config", Value: "high,default"}, engine.NewScope(nil))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"high", "default"}, got.Queues)
+		assert.Equal(t, "", got.Priority)
+	})
+
+	t.Run("options", func(t *testing.T) {
+		err := eng.Execute(ctx, &engine.Node{
+			Name: "worker.config",
+			Children: []*engine.Node{
+				{Name: "workers", Value: "10"},
+				{Name: "queues", Value: "'high:3,default,sms'"},
+				{Name: "limits", Children: []*engine.Node{{Name: "sms", Value: "2"}}},
+			},
+		}, engine.NewScope(nil))
+		assert.NoError(t, err)
+		assert.Equal(t, 10, got.Concurrency)
+		assert.Equal(t, []string{"high", "default", "sms"}, got.Queues)
+		assert.Equal(t, map[string]int{"high": 3}, got.Weights)
+		assert.Equal(t, worker.PriorityWeighted, got.Priority)
+		assert.Equal(t, map[string]int{"sms": 2}, got.Limits)
+	})
+
+	t.Run("invalid priority", func(t *testing.T) {
+		err := eng.Execute(ctx, &engine.Node{
+			Name: "worker.config",
+			Children: []*engine.Node{
+				{Name: "queues", Value: "'high,default'"},
+				{Name: "priority", Value: "'random'"},
+			},
+		}, engine.NewScope(nil))
+		assert.Error(t, err)
+	})
+}
+
+// newTestWorker menjalankan worker dengan slot test.record (mencatat nilai) dan test.fail (selalu error)
+func newTestWorker(t *testing.T, queue worker.JobQueue) (*engine.Engine, func() []string, func(string) string) {
+	eng := engine.NewEngine()
+	RegisterJobSlots(eng, queue, nil, nil)
+
+	var mu sync.Mutex
+	var records []string
+	eng.Register("test.record", func(ctx context.Context, node *engine.Node, scope *engine.Scope) error {
+		mu.Lock()
+		defer mu.Unlock()
+		records = append(records, coerce.ToString(parseNodeValue(node, scope)))
+		return nil
+	}, engine.SlotMeta{})
+	eng.Register("test.fail", func(ctx context.Context, node *engine.Node, scope *engine.Scope) error {
+		return errors.New(coerce.ToString(parseNodeValue(node, scope)))
+	}, engine.SlotMeta{})
+
+	dir := t.TempDir()
+	script := func(body string) string {
+		path := filepath.Join(dir, fmt.Sprintf("job_%d.zl", time.Now().UnixNano()))
+		if err := os.WriteFile(path, []byte(body+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	get := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), records...)
+	}
+	return eng, get, script
+}
+
+// runWorkerUntil menjalankan worker sampai done terpenuhi (maksimal 5 detik)
+func runWorkerUntil(t *testing.T, eng *engine.Engine, queue worker.JobQueue, opts worker.Options, done func() bool) {
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		worker.Run(ctx, eng, queue, opts)
+		close(stopped)
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for !done() && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+	}
+	// Beri kesempatan job yang tidak diharapkan (mis. langkah setelah kegagalan) untuk muncul
+	time.Sleep(200 * time.Millisecond)
+	cancel()
+	<-stopped
+}
+
+func TestJobChain(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("runs in order", func(t *testing.T) {
+		queue, _ := newTestJobQueue(t)
+		eng, records, script := newTestWorker(t, queue)
+		err := eng.Execute(ctx, &engine.Node{
+			Name: "job.chain",
+			Children: []*engine.Node{
+				{Name: "job", Children: []*engine.Node{{Name: "script_path", Value: "'" + script("test.record: 'resize'") + "'"}}},
+				{Name: "job", Children: []*engine.Node{{Name: "script_path", Value: "'" + script("test.record: 'notify'") + "'"}}},
+			},
+		}, engine.NewScope(nil))
+		assert.NoError(t, err)
+
+		runWorkerUntil(t, eng, queue, worker.Options{Queues: []string{"default"}, Concurrency: 2}, func() bool { return len(records()) == 2 })
+		assert.Equal(t, []string{"resize", "notify"}, records())
+	})
+
+	t.Run("stops on failure", func(t *testing.T) {
+		queue, _ := newTestJobQueue(t)
+		eng, records, script := newTestWorker(t, queue)
+		err := eng.Execute(ctx, &engine.Node{
+			Name: "job.chain",
+			Children: []*engine.Node{
+				{Name: "job", Children: []*engine.Node{{Name: "script_path", Value: "'" + script("test.record: 'resize'") + "'"}}},
+				{Name: "job", Children: []*engine.Node{{Name: "script_path", Value: "'" + script("test.fail: 'disk full'") + "'"}}},
+				{Name: "job", Children: []*engine.Node{{Name: "script_path", Value: "'" + script("test.record: 'notify'") + "'"}}},
+				{Name: "catch", Value: "'" + script("test.record: $error") + "'"},
+			},
+		}, engine.NewScope(nil))
+		assert.NoError(t, err)
+
+		runWorkerUntil(t, eng, queue, worker.Options{Queues: []string{"default"}, Concurrency: 2}, func() bool { return len(records()) == 2 })
+		assert.Equal(t, []string{"resize", "disk full"}, records())
+
+		failed, err := queue.FailedJobs(ctx, "")
+		assert.NoError(t, err)
+		assert.Len(t, failed, 1)
+	})
+}
+
+func TestJobBatch(t *testing.T) {
+	ctx := context.Background()
+	queue, _ := newTestJobQueue(t)
+	eng, records, script := newTestWorker(t, queue)
+
+	scope := engine.NewScope(nil)
+	scope.Set("jobs", []interface{}{
+		map[string]interface{}{"script_path": script("test.record: 'chunk'")},
+		map[string]interface{}{"script_path": script("test.fail: 'bad row'")},
+		map[string]interface{}{"script_path": script("test.record: 'chunk'")},
+	})
+	err := eng.Execute(ctx, &engine.Node{
+		Name: "job.batch",
+		Children: []*engine.Node{
+			{Name: "name", Value: "'Import users'"},
+			{Name: "jobs", Value: "$jobs"},
+			{Name: "then", Value: "'" + script("test.record: 'then'") + "'"},
+			{Name: "catch", Value: "'" + script("test.record: 'catch'") + "'"},
+			{Name: "finally", Value: "'" + script("test.record: $batch.failed_jobs") + "'"},
+			{Name: "as", Value: "$import"},
+		},
+	}, scope)
+	assert.NoError(t, err)
+	id, _ := scope.Get("import")
+	assert.NotNil(t, id)
+
+	// Progress sebelum worker berjalan
+	err = eng.Execute(ctx, &engine.Node{Name: "job.batch_status", Value: "$import"}, scope)
+	assert.NoError(t, err)
+	status, _ := scope.Get("batch")
+	assert.Equal(t, 3, status.(map[string]interface{})["pending_jobs"])
+	assert.Equal(t, 0, status.(map[string]interface{})["progress"])
+
+	runWorkerUntil(t, eng, queue, worker.Options{Queues: []string{"default"}, Concurrency: 2}, func() bool { return len(records()) == 4 })
+	assert.ElementsMatch(t, []string{"chunk", "chunk", "catch", "1"}, records())
+
+	err = eng.Execute(ctx, &engine.Node{Name: "job.batch_status", Value: "$import", Children: []*engine.Node{{Name: "as", Value: "$done"}}}, scope)
+	assert.NoError(t, err)
+	done, _ := scope.Get("done")
+	progress := done.(map[string]interface{})
+	assert.Equal(t, "Import users", progress["name"])
+	assert.Equal(t, 0, progress["pending_jobs"])
+	assert.Equal(t, 1, progress["failed_jobs"])
+	assert.Equal(t, 100, progress["progress"])
+	assert.Equal(t, true, progress["finished"])
+}
+
+func TestJobUnique(t *testing.T) {
+	ctx := context.Background()
+
+	enqueue := func(eng *engine.Engine, scope *engine.Scope, script string, options ...*engine.Node) bool {
+		children := append([]*engine.Node{
+			{Name: "payload", Children: []*engine.Node{{Name: "script_path", Value: "'" + script + "'"}}},
+			{Name: "as", Value: "$queued"},
+		}, options...)
+		err := eng.Execute(ctx, &engine.Node{Name: "job.enqueue", Children: children}, scope)
+		assert.NoError(t, err)
+		queued, _ := scope.Get("queued")
+		return queued.(bool)
+	}
+	pendingScripts := func(db *sql.DB) []string {
+		rows, err := db.Query("SELECT payload FROM jobs WHERE status = 'pending' ORDER BY id")
+		assert.NoError(t, err)
+		defer rows.Close()
+		var scripts []string
+		for rows.Next() {
+			var payload string
+			rows.Scan(&payload)
+			var p worker.JobPayload
+			json.Unmarshal([]byte(payload), &p)
+			scripts = append(scripts, p.ScriptPath)
+		}
+		return scripts
+	}
+
+	t.Run("skip", func(t *testing.T) {
+		queue, db := newTestJobQueue(t)
+		eng := engine.NewEngine()
+		RegisterJobSlots(eng, queue, nil, nil)
+		scope := engine.NewScope(nil)
+
+		key := &engine.Node{Name: "unique_key", Value: "'order-7'"}
+		assert.True(t, enqueue(eng, scope, "first.zl", key))
+		assert.False(t, enqueue(eng, scope, "second.zl", key), "double click must be skipped")
+		assert.True(t, enqueue(eng, scope, "other.zl", &engine.Node{Name: "unique_key", Value: "'order-8'"}))
+		assert.Equal(t, []string{"first.zl", "other.zl"}, pendingScripts(db))
+
+		// Lock dilepas setelah job selesai
+		job, err := queue.Reserve(ctx, []string{"default"}, false)
+		assert.NoError(t, err)
+		assert.False(t, enqueue(eng, scope, "while_running.zl", key), "a running job still holds the key")
+		assert.NoError(t, queue.Complete(ctx, job))
+		assert.True(t, enqueue(eng, scope, "after.zl", key))
+	})
+
+	t.Run("replace", func(t *testing.T) {
+		queue, db := newTestJobQueue(t)
+		eng := engine.NewEngine()
+		RegisterJobSlots(eng, queue, nil, nil)
+		scope := engine.NewScope(nil)
+
+		key := &engine.Node{Name: "unique_key", Value: "'reindex-3'"}
+		replace := &engine.Node{Name: "on_duplicate", Value: "'replace'"}
+		assert.True(t, enqueue(eng, scope, "old.zl", key, replace))
+		assert.True(t, enqueue(eng, scope, "new.zl", key, replace))
+		assert.Equal(t, []string{"new.zl"}, pendingScripts(db))
+	})
+
+	t.Run("unique_for outlives the job", func(t *testing.T) {
+		queue, db := newTestJobQueue(t)
+		eng := engine.NewEngine()
+		RegisterJobSlots(eng, queue, nil, nil)
+		scope := engine.NewScope(nil)
+
+		key := &engine.Node{Name: "unique_key", Value: "'webhook-evt_1'"}
+		window := &engine.Node{Name: "unique_for", Value: "'1h'"}
+		assert.True(t, enqueue(eng, scope, "webhook.zl", key, window))
+		job, err := queue.Reserve(ctx, []string{"default"}, false)
+		assert.NoError(t, err)
+		assert.NoError(t, queue.Complete(ctx, job))
+
+		assert.False(t, enqueue(eng, scope, "webhook.zl", key, window), "redelivered webhook must be skipped")
+		assert.Empty(t, pendingScripts(db))
+
+		// Window yang sudah lewat tidak lagi mengunci
+		_, err = db.Exec("UPDATE job_locks SET expires_at = ?", time.Now().Add(-time.Second).Unix())
+		assert.NoError(t, err)
+		assert.True(t, enqueue(eng, scope, "webhook.zl", key, window))
+	})
+
+	t.Run("needs a key", func(t *testing.T) {
+		queue, _ := newTestJobQueue(t)
+		eng := engine.NewEngine()
+		RegisterJobSlots(eng, queue, nil, nil)
+		err := eng.Execute(ctx, &engine.Node{Name: "job.enqueue", Children: []*engine.Node{
+			{Name: "payload", Children: []*engine.Node{{Name: "script_path", Value: "'a.zl'"}}},
+			{Name: "unique_for", Value: "'1h'"},
+		}}, engine.NewScope(nil))
+		assert.Error(t, err)
+	})
+}
+
+func TestJobDefine(t *testing.T) {
+	ctx := context.Background()
+	define := func(name, source string, children ...*engine.Node) *engine.Node {
+		return &engine.Node{Name: "job.define", Value: "'" + name + "'", Filename: source, Line: 1, Children: children}
+	}
+	record := &engine.Node{Name: "do", Children: []*engine.Node{{Name: "test.record", Value: "$email"}}}
+
+	t.Run("dispatch by name", func(t *testing.T) {
+		queue, db := newTestJobQueue(t)
+		eng, records, _ := newTestWorker(t, queue)
+		handlers := worker.NewHandlers()
+		RegisterJobSlots(eng, queue, nil, handlers)
+
+		scope := engine.NewScope(nil)
+		assert.NoError(t, eng.Execute(ctx, define("send_welcome", "src/jobs.zl", &engine.Node{Name: "queue", Value: "'emails'"}, record), scope))
+		assert.NoError(t, eng.Execute(ctx, &engine.Node{
+			Name:  "job.dispatch",
+			Value: "'send_welcome'",
+			Children: []*engine.Node{
+				{Name: "data", Children: []*engine.Node{{Name: "email", Value: "'budi@example.com'"}}},
+			},
+		}, scope))
+
+		// Payload hanya menyimpan nama handler, bukan path script
+		var queueName, payload string
+		assert.NoError(t, db.QueryRow("SELECT queue, payload FROM jobs").Scan(&queueName, &payload))
+		assert.Equal(t, "emails", queueName)
+		var p worker.JobPayload
+		assert.NoError(t, json.Unmarshal([]byte(payload), &p))
+		assert.Equal(t, "send_welcome", p.Handler)
+		assert.Empty(t, p.ScriptPath)
+
+		runWorkerUntil(t, eng, queue, worker.Options{Queues: []string{"emails"}, Concurrency: 1, Handlers: handlers}, func() bool { return len(records()) == 1 })
+		assert.Equal(t, []string{"budi@example.com"}, records())
+	})
+
+	t.Run("unknown handler", func(t *testing.T) {
+		queue, _ := newTestJobQueue(t)
+		eng := engine.NewEngine()
+		RegisterJobSlots(eng, queue, nil, worker.NewHandlers())
+		err := eng.Execute(ctx, &engine.Node{Name: "job.dispatch", Value: "'send_welcom'"}, engine.NewScope(nil))
+		assert.ErrorContains(t, err, "'send_welcom' is not defined")
+	})
+
+	t.Run("chain of handlers", func(t *testing.T) {
+		queue, _ := newTestJobQueue(t)
+		eng, records, script := newTestWorker(t, queue)
+		handlers := worker.NewHandlers()
+		RegisterJobSlots(eng, queue, nil, handlers)
+
+		scope := engine.NewScope(nil)
+		handler := func(name string) *engine.Node { return &engine.Node{Name: "handler", Value: "'" + name + "'"} }
+		body := func(slot, value string) *engine.Node {
+			return &engine.Node{Name: "do", Children: []*engine.Node{{Name: slot, Value: value}}}
+		}
+		assert.NoError(t, eng.Execute(ctx, define("resize", "src/jobs.zl", body("test.record", "'resize'")), scope))
+		assert.NoError(t, eng.Execute(ctx, define("upload", "src/jobs.zl", body("test.fail", "'disk full'")), scope))
+		assert.NoError(t, eng.Execute(ctx, define("alert", "src/jobs.zl", body("test.record", "$failed_handler")), scope))
+
+		err := eng.Execute(ctx, &engine.Node{
+			Name: "job.chain",
+			Children: []*engine.Node{
+				{Name: "job", Children: []*engine.Node{handler("resize")}},
+				{Name: "job", Children: []*engine.Node{handler("upload")}},
+				{Name: "job", Children: []*engine.Node{{Name: "script_path", Value: "'" + script("test.record: 'notify'") + "'"}}},
+				{Name: "catch", Children: []*engine.Node{handler("alert")}},
+			},
+		}, scope)
+		assert.NoError(t, err)
+
+		runWorkerUntil(t, eng, queue, worker.Options{Queues: []string{"default"}, Concurrency: 2, Handlers: handlers}, func() bool { return len(records()) == 2 })
+		assert.Equal(t, []string{"resize", "upload"}, records())
+
+		err = eng.Execute(ctx, &engine.Node{
+			Name:     "job.chain",
+			Children: []*engine.Node{{Name: "job", Children: []*engine.Node{handler("resise")}}},
+		}, scope)
+		assert.ErrorContains(t, err, "job #1: job handler 'resise' is not defined")
+		err = eng.Execute(ctx, &engine.Node{
+			Name: "job.chain",
+			Children: []*engine.Node{{Name: "job", Children: []*engine.Node{
+				handler("resize"), {Name: "script_path", Value: "'src/jobs/resize.zl'"},
+			}}},
+		}, scope)
+		assert.ErrorContains(t, err, "either script_path or handler")
+	})
+
+	t.Run("batch with handler callbacks", func(t *testing.T) {
+		queue, _ := newTestJobQueue(t)
+		eng, records, _ := newTestWorker(t, queue)
+		handlers := worker.NewHandlers()
+		RegisterJobSlots(eng, queue, nil, handlers)
+
+		scope := engine.NewScope(nil)
+		body := func(slot, value string) *engine.Node {
+			return &engine.Node{Name: "do", Children: []*engine.Node{{Name: slot, Value: value}}}
+		}
+		assert.NoError(t, eng.Execute(ctx, define("import_chunk", "src/jobs.zl", body("test.record", "'chunk'")), scope))
+		assert.NoError(t, eng.Execute(ctx, define("import_bad", "src/jobs.zl", body("test.fail", "'bad row'")), scope))
+		assert.NoError(t, eng.Execute(ctx, define("import_done", "src/jobs.zl", body("test.record", "'then'")), scope))
+		assert.NoError(t, eng.Execute(ctx, define("import_failed", "src/jobs.zl", body("test.record", "$failed_handler")), scope))
+		assert.NoError(t, eng.Execute(ctx, define("import_cleanup", "src/jobs.zl", body("test.record", "$batch.failed_jobs")), scope))
+
+		scope.Set("jobs", []interface{}{
+			map[string]interface{}{"handler": "import_chunk"},
+			map[string]interface{}{"handler": "import_bad"},
+		})
+		err := eng.Execute(ctx, &engine.Node{
+			Name: "job.batch",
+			Children: []*engine.Node{
+				{Name: "jobs", Value: "$jobs"},
+				{Name: "then", Children: []*engine.Node{{Name: "handler", Value: "'import_done'"}}},
+				{Name: "catch", Children: []*engine.Node{{Name: "handler", Value: "'import_failed'"}}},
+				{Name: "finally", Children: []*engine.Node{{Name: "handler", Value: "'import_cleanup'"}}},
+			},
+		}, scope)
+		assert.NoError(t, err)
+
+		runWorkerUntil(t, eng, queue, worker.Options{Queues: []string{"default"}, Concurrency: 2, Handlers: handlers}, func() bool { return len(records()) == 3 })
+		assert.ElementsMatch(t, []string{"chunk", "import_bad", "1"}, records())
+	})
+
+	t.Run("invalid definitions", func(t *testing.T) {
+		eng := engine.NewEngine()
+		RegisterJobSlots(eng, &MockJobQueue{}, nil, worker.NewHandlers())
+		scope := engine.NewScope(nil)
+
+		assert.NoError(t, eng.Execute(ctx, define("reindex", "src/jobs.zl", record), scope))
+		// Main script yang dijalankan ulang (hot reload) boleh mendefinisikan ulang di lokasi yang sama
+		assert.NoError(t, eng.Execute(ctx, define("reindex", "src/jobs.zl", record), scope))
+		err := eng.Execute(ctx, define("reindex", "src/other.zl", record), scope)
+		assert.ErrorContains(t, err, "already defined at src/jobs.zl:1")
+
+		err = eng.Execute(ctx, define("empty", "src/jobs.zl", &engine.Node{Name: "queue", Value: "'emails'"}), scope)
+		assert.ErrorContains(t, err, "empty body")
+		err = eng.Execute(ctx, define("send welcome", "src/jobs.zl", record), scope)
+		assert.ErrorContains(t, err, "invalid job handler name")
+	})
+}
+
+func failedErrors(t *testing.T, db *sql.DB) []string {
+	rows, err := db.Query("SELECT error FROM failed_jobs ORDER BY id")
+	assert.NoError(t, err)
+	defer rows.Close()
+	var errs []string
+	for rows.Next() {
+		var e string
+		rows.Scan(&e)
+		errs = append(errs, e)
+	}
+	return errs
+}
+
+func TestJobCancel(t *testing.T) {
+	ctx := context.Background()
+	queue, db := newTestJobQueue(t)
+	eng := engine.NewEngine()
+	RegisterJobSlots(eng, queue, nil, nil)
+	scope := engine.NewScope(nil)
+
+	assert.NoError(t, eng.Execute(ctx, &engine.Node{Name: "job.enqueue", Children: []*engine.Node{
+		{Name: "payload", Children: []*engine.Node{{Name: "script_path", Value: "'report.zl'"}}},
+		{Name: "delay", Value: "'1h'"},
+		{Name: "id_as", Value: "$report_job"},
+	}}, scope))
+	id, _ := scope.Get("report_job")
+	assert.NotZero(t, id)
+
+	cancel := &engine.Node{Name: "job.cancel", Value: "$report_job", Children: []*engine.Node{{Name: "as", Value: "$cancelled"}}}
+	assert.NoError(t, eng.Execute(ctx, cancel, scope))
+	cancelled, _ := scope.Get("cancelled")
+	assert.Equal(t, true, cancelled)
+
+	var count int
+	assert.NoError(t, db.QueryRow("SELECT COUNT(*) FROM jobs").Scan(&count))
+	assert.Equal(t, 0, count)
+	assert.Equal(t, []string{"job cancelled"}, failedErrors(t, db))
+
+	assert.NoError(t, eng.Execute(ctx, cancel, scope))
+	cancelled, _ = scope.Get("cancelled")
+	assert.Equal(t, false, cancelled, "a cancelled job is gone")
+}
@@ -18,8 +18,27 @@ type SQLExecutor interface {
 	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
 }
 
+// getExecutor returns the transaction or connection pool for dbName. In migration
+// pretend mode writes go to the migrator's recorder instead (see pretendExecutor).
 func getExecutor(scope *engine.Scope, dbMgr *dbmanager.DBManager, dbName string) (SQLExecutor, dbmanager.Dialect, error) {
+	executor, dialect, err := resolveExecutor(scope, dbMgr, dbName)
+	if err != nil {
+		return nil, nil, err
+	}
+	if rec, ok := scope.Get("_migration_pretend"); ok {
+		if record, ok := rec.(func(string, string, []interface{})); ok {
+			return pretendExecutor{SQLExecutor: executor, conn: dbName, record: record}, dialect, nil
+		}
+	}
+	return executor, dialect, nil
+}
+
+func resolveExecutor(scope *engine.Scope, dbMgr *dbmanager.DBManager, dbName string) (SQLExecutor, dbmanager.Dialect, error) {
 	if val, ok := scope.Get("_active_tx"); ok && val != nil {
+		// Migration transactions are bound to one connection, other connections use their own pool
+		if txDB, ok := scope.Get("_active_tx_db"); ok && txDB != nil && coerce.ToString(txDB) != dbName {
+			val = nil
+		}
 		if tx, ok := val.(*sql.Tx); ok {
 			// [IMPORTANT] Transaction also needs dialect.
 			// For now, we assume it's the default database dialect if not specified.
@@ -34,6 +53,32 @@ func getExecutor(scope *engine.Scope, dbMgr *dbmanager.DBManager, dbName string)
 	return db, dialect, nil
 }
 
+// pretendExecutor records statements sent through ExecContext (zeno migrate --pretend)
+// instead of running them. Reads still reach the database, so slots that look up
+// existing rows or tables keep working.
+type pretendExecutor struct {
+	SQLExecutor
+	conn   string
+	record func(conn, query string, args []interface{})
+}
+
+func (p pretendExecutor) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	p.record(p.conn, query, args)
+	return pretendResult{}, nil
+}
+
+// pretendResult is the result of a recorded statement: nothing was inserted or changed
+type pretendResult struct{}
+
+func (pretendResult) LastInsertId() (int64, error) { return 0, nil }
+func (pretendResult) RowsAffected() (int64, error) { return 0, nil }
+
+// isPretend reports whether executor only records writes
+func isPretend(executor SQLExecutor) bool {
+	_, ok := executor.(pretendExecutor)
+	return ok
+}
+
 type WhereCond struct {
 	Logical string // "AND" or "OR"
 	Column  string
@@ -320,3 +320,70 @@ func TestDBQuery(t *testing.T) {
 		})
 	}
 }
+
+func TestPretendModeRecordsWrites(t *testing.T) {
+	dbMgr := dbmanager.NewDBManager()
+	if err := dbMgr.AddConnection("default", "sqlite", ":memory:", 1, 1); err != nil {
+		t.Fatalf("Failed to create in-memory db: %v", err)
+	}
+	defer dbMgr.Close()
+	db := dbMgr.GetConnection("default")
+	_, err := db.Exec("CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)")
+	assert.NoError(t, err)
+	_, err = db.Exec("INSERT INTO users (name) VALUES ('alice')")
+	assert.NoError(t, err)
+
+	eng := engine.NewEngine()
+	RegisterRawDBSlots(eng, dbMgr)
+	RegisterDBSlots(eng, dbMgr)
+	RegisterORMSlots(eng, dbMgr)
+
+	var captured []string
+	scope := engine.NewScope(nil)
+	scope.Set("_migration_pretend", func(conn, query string, args []interface{}) {
+		assert.Equal(t, "default", conn)
+		captured = append(captured, query)
+	})
+
+	table := &engine.Node{Name: "db.table", Value: "users"}
+	nodes := []*engine.Node{
+		{Name: "db.execute", Value: "UPDATE users SET name = ?", Children: []*engine.Node{
+			{Name: "bind", Children: []*engine.Node{{Name: "val", Value: "bob"}}},
+		}},
+		table,
+		{Name: "db.insert", Children: []*engine.Node{{Name: "name", Value: "carol"}}},
+		{Name: "db.update", Children: []*engine.Node{{Name: "name", Value: "dave"}}},
+		{Name: "db.delete"},
+		{Name: "db.seed", Children: []*engine.Node{
+			table,
+			{Name: "db.insert", Children: []*engine.Node{{Name: "name", Value: "erin"}}},
+		}},
+		// Reads still reach the database
+		{Name: "db.select", Value: "SELECT name FROM users", Children: []*engine.Node{{Name: "as", Value: "$rows"}}},
+	}
+	for _, node := range nodes {
+		assert.NoError(t, eng.Execute(context.Background(), node, scope), node.Name)
+	}
+
+	assert.Equal(t, []string{
+		"UPDATE users SET name = ?",
+		`INSERT INTO "users" ("name") VALUES (?)`,
+		`UPDATE "users" SET "name" = ?`,
+		`DELETE FROM "users"`,
+		`INSERT INTO "users" ("name") VALUES (?)`,
+	}, captured)
+	rows, _ := scope.Get("rows")
+	assert.Equal(t, []map[string]interface{}{{"name": "alice"}}, rows)
+
+	// Nothing must reach the database
+	var names []string
+	result, err := db.Query("SELECT name FROM users")
+	assert.NoError(t, err)
+	defer result.Close()
+	for result.Next() {
+		var name string
+		result.Scan(&name)
+		names = append(names, name)
+	}
+	assert.Equal(t, []string{"alice"}, names)
+}
@@ -25,6 +25,24 @@ type routerKey struct{}
 // [NEW] Registry for ZenoLang-defined custom middlewares
 var customMiddlewares = make(map[string]*engine.Node)
 
+// middlewareNames normalizes a middleware attribute ('auth', 'auth, admin' or ['auth', 'admin'])
+// into a list of names
+func middlewareNames(v interface{}) []string {
+	var names []string
+	if list, ok := v.([]interface{}); ok {
+		for _, item := range list {
+			names = append(names, middlewareNames(item)...)
+		}
+		return names
+	}
+	for _, part := range strings.Split(coerce.ToString(v), ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			names = append(names, part)
+		}
+	}
+	return names
+}
+
 func RegisterRouterSlots(eng *engine.Engine, rootRouter *chi.Mux) {
 
 	// Helper: Ambil router aktif (Root atau Group)
@@ -202,6 +220,16 @@ func RegisterRouterSlots(eng *engine.Engine, rootRouter *chi.Mux) {
 	// Helper context for path tracking
 	type pathPrefixKey struct{}
 
+	// Middleware applied by the enclosing http.group blocks (documentation only)
+	type groupMiddlewareKey struct{}
+
+	getGroupMiddleware := func(ctx context.Context) []string {
+		if mw, ok := ctx.Value(groupMiddlewareKey{}).([]string); ok {
+			return mw
+		}
+		return nil
+	}
+
 	getCurrentPath := func(ctx context.Context) string {
 		if p, ok := ctx.Value(pathPrefixKey{}).(string); ok {
 			return p
@@ -234,9 +262,12 @@ func RegisterRouterSlots(eng *engine.Engine, rootRouter *chi.Mux) {
 
 		// Check if group has middleware
 		middlewareName := ""
+		groupMiddleware := getGroupMiddleware(ctx)
 		for _, c := range node.Children {
 			if c.Name == "middleware" {
-				middlewareName = coerce.ToString(resolveValue(c.Value, scope))
+				val := resolveValue(c.Value, scope)
+				middlewareName = coerce.ToString(val)
+				groupMiddleware = append(append([]string{}, groupMiddleware...), middlewareNames(val)...)
 			}
 		}
 
@@ -283,6 +314,8 @@ func RegisterRouterSlots(eng *engine.Engine, rootRouter *chi.Mux) {
 
 		// Create new context with sub-router
 		groupCtx := context.WithValue(ctx, routerKey{}, subRouter)
+		groupCtx = context.WithValue(groupCtx, pathPrefixKey{}, joinPath(getCurrentPath(ctx), path))
+		groupCtx = context.WithValue(groupCtx, groupMiddlewareKey{}, groupMiddleware)
 
 		// Execute children in group context
 		for _, child := range childrenToExec {
@@ -307,9 +340,12 @@ func RegisterRouterSlots(eng *engine.Engine, rootRouter *chi.Mux) {
 			fullDocPath := joinPath(getCurrentPath(ctx), path)
 
 			routeDoc := &apidoc.RouteDoc{
-				Method:    m,
-				Path:      fullDocPath,
-				Responses: make(map[string]apidoc.ResponseDoc),
+				Method:     m,
+				Path:       fullDocPath,
+				Responses:  make(map[string]apidoc.ResponseDoc),
+				Middleware: append([]string{}, getGroupMiddleware(ctx)...),
+				File:       node.Filename,
+				Line:       node.Line,
 			}
 
 			var doNode *engine.Node
@@ -343,7 +379,9 @@ func RegisterRouterSlots(eng *engine.Engine, rootRouter *chi.Mux) {
 				// Support both: middleware: "auth" AND middleware with parameters as route attributes
 				if c.Name == "middleware" {
 					if c.Value != nil {
-						middlewareName = coerce.ToString(resolveValue(c.Value, scope))
+						val := resolveValue(c.Value, scope)
+						middlewareName = coerce.ToString(val)
+						routeDoc.Middleware = append(routeDoc.Middleware, middlewareNames(val)...)
 					}
 				}
 
@@ -6,6 +6,7 @@ import (
 	"net/http/httptest"
 	"testing"
 	"github.com/nextcore/zeno-go/pkg/engine"
+	"github.com/nextcore/zenoengine/pkg/apidoc"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/assert"
@@ -115,3 +116,38 @@ func TestRouterSlots(t *testing.T) {
 		assert.NotNil(t, receivedBody)
 	})
 }
+
+func TestRouteDocMetadata(t *testing.T) {
+	eng := engine.NewEngine()
+	RegisterRouterSlots(eng, chi.NewRouter())
+
+	node := &engine.Node{
+		Name:  "http.group",
+		Value: "/admin",
+		Children: []*engine.Node{
+			{Name: "middleware", Value: "audit"},
+			{Name: "http.get", Value: "/reports", Filename: "src/routes/admin.zl", Line: 7, Children: []*engine.Node{
+				{Name: "middleware", Value: []interface{}{"cache", "throttle"}},
+				{Name: "summary", Value: "List reports"},
+				{Name: "do"},
+			}},
+		},
+	}
+	assert.NoError(t, eng.Execute(context.Background(), node, engine.NewScope(nil)))
+
+	doc, ok := apidoc.Registry.Routes["GET:/admin/reports"]
+	if !assert.True(t, ok, "grouped route must be documented with its full path") {
+		return
+	}
+	assert.Equal(t, []string{"audit", "cache", "throttle"}, doc.Middleware)
+	assert.Equal(t, "List reports", doc.Summary)
+	assert.Equal(t, "src/routes/admin.zl", doc.File)
+	assert.Equal(t, 7, doc.Line)
+}
+
+func TestMiddlewareNames(t *testing.T) {
+	assert.Equal(t, []string{"auth"}, middlewareNames("auth"))
+	assert.Equal(t, []string{"auth", "admin"}, middlewareNames("auth, admin"))
+	assert.Equal(t, []string{"auth", "admin"}, middlewareNames([]interface{}{"auth", "admin"}))
+	assert.Nil(t, middlewareNames(""))
+}
@@ -0,0 +1,130 @@
+package slots
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nextcore/zeno-go/pkg/engine"
+	"github.com/nextcore/zeno-go/pkg/utils/coerce"
+	"github.com/nextcore/zenoengine/pkg/worker"
+)
+
+// RegisterScheduleSlots mendaftarkan slot schedule.cron dan schedule.every. tasks menampung
+// tugas yang dijalankan scheduler; nil berarti registry milik engine ini saja.
+func RegisterScheduleSlots(eng *engine.Engine, tasks *worker.ScheduledTasks) {
+	if tasks == nil {
+		tasks = worker.NewScheduledTasks()
+	}
+
+	// SCHEDULE.CRON
+	eng.Register("schedule.cron", func(ctx context.Context, node *engine.Node, scope *engine.Scope) error {
+		spec := strings.TrimSpace(coerce.ToString(resolveValue(node.Value, scope)))
+		loc := time.Local
+		if c := childNode(node, "timezone"); c != nil {
+			name := coerce.ToString(parseNodeValue(c, scope))
+			l, err := time.LoadLocation(name)
+			if err != nil {
+				return fmt.Errorf("schedule.cron '%s': invalid timezone '%s'", spec, name)
+			}
+			loc = l
+		}
+		schedule, err := worker.ParseCron(spec, loc)
+		if err != nil {
+			return fmt.Errorf("schedule.cron: %v", err)
+		}
+		return defineScheduledTask(tasks, "schedule.cron", "cron "+spec, spec, schedule, node, scope)
+	}, engine.SlotMeta{
+		Description: "Run a block periodically on a cron schedule. Only one instance runs each tick.",
+		Example: `schedule.cron: '0 2 * * *' {
+  name: 'nightly_report'
+  timeout: '30m'
+  do: {
+    job.dispatch: 'build_report'
+  }
+}`,
+		Inputs: map[string]engine.InputMeta{
+			"name":     {Description: "Task name for the lock and 'zeno schedule:run' (Default: 'cron <expression>')", Required: false},
+			"timezone": {Description: "Time zone of the expression, e.g. 'Asia/Jakarta' (Default: server time zone)", Required: false},
+			"overlap":  {Description: "Start a new run while the previous one is still going (Default: false)", Required: false},
+			"timeout":  {Description: "Maximum duration of one run, e.g. '10m'", Required: false},
+			"do":       {Description: "Task body. $schedule_name and $schedule_started_at are available", Required: true},
+		},
+	})
+
+	// SCHEDULE.EVERY
+	eng.Register("schedule.every", func(ctx context.Context, node *engine.Node, scope *engine.Scope) error {
+		spec := strings.TrimSpace(coerce.ToString(resolveValue(node.Value, scope)))
+		every, err := worker.ParseDelay(spec)
+		if err != nil || every < time.Second {
+			return fmt.Errorf("schedule.every: invalid interval '%s' (use e.g. '30s', '5m' or '1h')", spec)
+		}
+		return defineScheduledTask(tasks, "schedule.every", "every "+spec, spec, worker.Every(every), node, scope)
+	}, engine.SlotMeta{
+		Description: "Run a block every interval, aligned to the clock ('5m' runs at :00, :05, :10, ...). Only one instance runs each tick.",
+		Example: `schedule.every: '1h' {
+  name: 'prune_tokens'
+  do: {
+    job.dispatch: 'prune_tokens'
+  }
+}`,
+		Inputs: map[string]engine.InputMeta{
+			"name":    {Description: "Task name for the lock and 'zeno schedule:run' (Default: 'every <interval>')", Required: false},
+			"overlap": {Description: "Start a new run while the previous one is still going (Default: false)", Required: false},
+			"timeout": {Description: "Maximum duration of one run, e.g. '10m'", Required: false},
+			"do":      {Description: "Task body. $schedule_name and $schedule_started_at are available", Required: true},
+		},
+	})
+}
+
+// defineScheduledTask membaca opsi name/overlap/timeout; do: (atau sisa children) adalah body tugas
+func defineScheduledTask(tasks *worker.ScheduledTasks, slot, defaultName, spec string, schedule worker.Schedule, node *engine.Node, scope *engine.Scope) error {
+	task := &worker.ScheduledTask{
+		Name:     defaultName,
+		Spec:     spec,
+		Schedule: schedule,
+		Source:   fmt.Sprintf("%s:%d", node.Filename, node.Line),
+	}
+
+	for _, c := range node.Children {
+		switch c.Name {
+		case "name":
+			task.Name = strings.TrimSpace(coerce.ToString(parseNodeValue(c, scope)))
+		case "overlap":
+			overlap, err := coerce.ToBool(parseNodeValue(c, scope))
+			if err != nil {
+				return fmt.Errorf("%s '%s': overlap must be true or false", slot, spec)
+			}
+			task.Overlap = overlap
+		case "timeout":
+			d, err := worker.ParseDelay(coerce.ToString(parseNodeValue(c, scope)))
+			if err != nil {
+				return fmt.Errorf("%s '%s': invalid timeout: %v", slot, spec, err)
+			}
+			task.Timeout = d
+		case "timezone":
+			// Dibaca oleh schedule.cron
+		case "do":
+			task.Body = c.Children
+		default:
+			if !hasChild(node, "do") {
+				task.Body = append(task.Body, c)
+			}
+		}
+	}
+
+	if err := tasks.Define(task); err != nil {
+		return fmt.Errorf("%s: %v", slot, err)
+	}
+	return nil
+}
+
+func childNode(node *engine.Node, name string) *engine.Node {
+	for _, c := range node.Children {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
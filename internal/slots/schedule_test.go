@@ -0,0 +1,80 @@
+package slots
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nextcore/zeno-go/pkg/engine"
+	"github.com/nextcore/zenoengine/pkg/worker"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduleSlots(t *testing.T) {
+	ctx := context.Background()
+	body := &engine.Node{Name: "do", Children: []*engine.Node{{Name: "test.record", Value: "$schedule_name"}}}
+
+	t.Run("define tasks", func(t *testing.T) {
+		eng := engine.NewEngine()
+		tasks := worker.NewScheduledTasks()
+		RegisterScheduleSlots(eng, tasks)
+
+		scope := engine.NewScope(nil)
+		assert.NoError(t, eng.Execute(ctx, &engine.Node{
+			Name: "schedule.cron", Value: "'0 2 * * *'", Filename: "src/main.zl", Line: 3,
+			Children: []*engine.Node{
+				{Name: "name", Value: "'nightly_report'"},
+				{Name: "timeout", Value: "'30m'"},
+				body,
+			},
+		}, scope))
+		assert.NoError(t, eng.Execute(ctx, &engine.Node{
+			Name: "schedule.every", Value: "'5m'", Filename: "src/main.zl", Line: 10,
+			Children: []*engine.Node{
+				{Name: "overlap", Value: "true"},
+				{Name: "test.record", Value: "'prune'"},
+			},
+		}, scope))
+
+		list := tasks.List()
+		if assert.Len(t, list, 2) {
+			assert.Equal(t, "every 5m", list[0].Name)
+			assert.True(t, list[0].Overlap)
+			assert.Len(t, list[0].Body, 1)
+			assert.Equal(t, "nightly_report", list[1].Name)
+			assert.Equal(t, "0 2 * * *", list[1].Spec)
+			assert.Equal(t, 30*time.Minute, list[1].Timeout)
+			assert.Equal(t, "src/main.zl:3", list[1].Source)
+		}
+
+		// Script yang sama dijalankan ulang (reload) tidak dianggap duplikat
+		assert.NoError(t, eng.Execute(ctx, &engine.Node{
+			Name: "schedule.cron", Value: "'0 2 * * *'", Filename: "src/main.zl", Line: 3,
+			Children: []*engine.Node{{Name: "name", Value: "'nightly_report'"}, body},
+		}, scope))
+	})
+
+	t.Run("invalid definitions", func(t *testing.T) {
+		eng := engine.NewEngine()
+		tasks := worker.NewScheduledTasks()
+		RegisterScheduleSlots(eng, tasks)
+		scope := engine.NewScope(nil)
+
+		assert.NoError(t, eng.Execute(ctx, &engine.Node{Name: "schedule.every", Value: "'1h'", Filename: "src/a.zl", Line: 1, Children: []*engine.Node{body}}, scope))
+		err := eng.Execute(ctx, &engine.Node{Name: "schedule.every", Value: "'1h'", Filename: "src/b.zl", Line: 1, Children: []*engine.Node{body}}, scope)
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "already defined at src/a.zl:1")
+		}
+
+		for _, node := range []*engine.Node{
+			{Name: "schedule.cron", Value: "'0 2 * *'", Children: []*engine.Node{body}},
+			{Name: "schedule.cron", Value: "'0 2 * * *'", Children: []*engine.Node{{Name: "timezone", Value: "'Mars/Olympus'"}, body}},
+			{Name: "schedule.every", Value: "'soon'", Children: []*engine.Node{body}},
+			{Name: "schedule.every", Value: "'500ms'", Children: []*engine.Node{body}},
+			{Name: "schedule.every", Value: "'5m'", Children: []*engine.Node{{Name: "name", Value: "'empty'"}}},
+		} {
+			assert.Error(t, eng.Execute(ctx, node, scope), "%s %v", node.Name, node.Value)
+		}
+	})
+}
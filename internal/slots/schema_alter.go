@@ -0,0 +1,769 @@
+package slots
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/nextcore/zeno-go/pkg/engine"
+	"github.com/nextcore/zeno-go/pkg/utils/coerce"
+	"github.com/nextcore/zenoengine/pkg/dbmanager"
+)
+
+// Alter operation kinds recorded inside db.alter_table
+const (
+	OpAddColumn    = "add_column"
+	OpModifyColumn = "modify_column"
+	OpDropColumn   = "drop_column"
+	OpRenameColumn = "rename_column"
+	OpAddIndex     = "add_index"
+	OpDropIndex    = "drop_index"
+	OpAddUnique    = "add_unique"
+	OpDropUnique   = "drop_unique"
+	OpAddForeign   = "add_foreign"
+	OpDropForeign  = "drop_foreign"
+)
+
+// AlterOp is a single change to an existing table
+type AlterOp struct {
+	Kind    string
+	Column  ColumnDef     // add_column, modify_column, drop_column, rename_column (old name)
+	To      string        // rename_column
+	Name    string        // index / constraint name
+	Columns []string      // add_index, add_unique
+	Foreign ForeignKeyDef // add_foreign, drop_foreign
+}
+
+// indexName builds the conventional name of an index or constraint: <table>_<col1>_<col2>_<suffix>
+func indexName(table string, columns []string, suffix string) string {
+	parts := append([]string{table}, columns...)
+	parts = append(parts, suffix)
+	return strings.ToLower(strings.Join(parts, "_"))
+}
+
+// schemaColumns accepts a list, or a single / comma separated column string
+func schemaColumns(v interface{}) []string {
+	var cols []string
+	if list, ok := v.([]interface{}); ok {
+		for _, item := range list {
+			cols = append(cols, strings.TrimSpace(coerce.ToString(item)))
+		}
+		return cols
+	}
+	if list, ok := v.([]string); ok {
+		return list
+	}
+	for _, c := range strings.Split(coerce.ToString(v), ",") {
+		if c = strings.TrimSpace(c); c != "" {
+			cols = append(cols, c)
+		}
+	}
+	return cols
+}
+
+func (s *SchemaState) quoteColumns(columns []string) string {
+	quoted := make([]string, len(columns))
+	for i, c := range columns {
+		quoted[i] = s.Dialect.QuoteIdentifier(c)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// AlterSQL generates the statements for one operation in the state's dialect.
+// SQLite operations that need a table rebuild are handled by SQLiteRebuildSQL.
+func (s *SchemaState) AlterSQL(op AlterOp) ([]string, error) {
+	d := s.Dialect.Name()
+	table := s.Dialect.QuoteIdentifier(s.Table)
+
+	switch op.Kind {
+	case OpAddColumn:
+		col := op.Column
+		// SQLite cannot add a UNIQUE column, add it as a separate index
+		if d == "sqlite" && col.Unique {
+			col.Unique = false
+			return []string{
+				fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s;", table, s.columnSQL(col)),
+				fmt.Sprintf("CREATE UNIQUE INDEX %s ON %s (%s);",
+					s.Dialect.QuoteIdentifier(indexName(s.Table, []string{col.Name}, "unique")), table, s.quoteColumns([]string{col.Name})),
+			}, nil
+		}
+		if d == "sqlserver" {
+			return []string{fmt.Sprintf("ALTER TABLE %s ADD %s;", table, s.columnSQL(col))}, nil
+		}
+		return []string{fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s;", table, s.columnSQL(col))}, nil
+
+	case OpModifyColumn:
+		col := op.Column
+		if col.Type == "id" {
+			return nil, fmt.Errorf("db.alter_table: cannot change column '%s' to an auto-increment primary key", col.Name)
+		}
+		name := s.Dialect.QuoteIdentifier(col.Name)
+		colType := s.translateType(col)
+
+		// The new definition replaces the old one completely: without default, the column loses its default
+		var stmts []string
+		switch d {
+		case "mysql":
+			def := colType
+			if col.Default != "" {
+				def += " DEFAULT " + col.Default
+			}
+			if !col.Nullable {
+				def += " NOT NULL"
+			}
+			stmts = append(stmts, fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s %s;", table, name, def))
+		case "postgres":
+			nullability := "DROP NOT NULL"
+			if !col.Nullable {
+				nullability = "SET NOT NULL"
+			}
+			dflt := "DROP DEFAULT"
+			if col.Default != "" {
+				dflt = "SET DEFAULT " + col.Default
+			}
+			stmts = append(stmts, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s TYPE %s USING %s::%s, ALTER COLUMN %s %s, ALTER COLUMN %s %s;",
+				table, name, colType, name, colType, name, nullability, name, dflt))
+		case "sqlserver":
+			nullability := "NULL"
+			if !col.Nullable {
+				nullability = "NOT NULL"
+			}
+			// A default is a constraint, usually with a generated name, and blocks ALTER COLUMN:
+			// drop it first and add the new one afterwards
+			stmts = append(stmts,
+				fmt.Sprintf(`DECLARE @df NVARCHAR(256) = (SELECT d.name FROM sys.default_constraints d
+	JOIN sys.columns c ON c.object_id = d.parent_object_id AND c.column_id = d.parent_column_id
+	WHERE d.parent_object_id = OBJECT_ID(N'%s') AND c.name = N'%s');
+IF @df IS NOT NULL EXEC(N'ALTER TABLE %s DROP CONSTRAINT ' + QUOTENAME(@df));`,
+					escapeSQLString(s.Table), escapeSQLString(col.Name), escapeSQLString(table)),
+				fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s %s %s;", table, name, colType, nullability))
+			if col.Default != "" {
+				stmts = append(stmts, fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s DEFAULT %s FOR %s;",
+					table, s.Dialect.QuoteIdentifier(indexName(s.Table, []string{col.Name}, "default")), col.Default, name))
+			}
+		default:
+			return nil, fmt.Errorf("db.alter_table: modifying columns requires a table rebuild on %s", d)
+		}
+
+		if col.Unique {
+			more, err := s.AlterSQL(AlterOp{Kind: OpAddUnique, Name: indexName(s.Table, []string{col.Name}, "unique"), Columns: []string{col.Name}})
+			if err != nil {
+				return nil, err
+			}
+			stmts = append(stmts, more...)
+		}
+		return stmts, nil
+
+	case OpDropColumn:
+		if d == "sqlite" {
+			return nil, fmt.Errorf("db.alter_table: dropping columns requires a table rebuild on sqlite")
+		}
+		return []string{fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s;", table, s.Dialect.QuoteIdentifier(op.Column.Name))}, nil
+
+	case OpRenameColumn:
+		if d == "sqlserver" {
+			// sp_rename expects unquoted 'table.column' and the bare new name
+			return []string{fmt.Sprintf("EXEC sp_rename '%s.%s', '%s', 'COLUMN';",
+				escapeSQLString(s.Table), escapeSQLString(op.Column.Name), escapeSQLString(op.To))}, nil
+		}
+		return []string{fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s;",
+			table, s.Dialect.QuoteIdentifier(op.Column.Name), s.Dialect.QuoteIdentifier(op.To))}, nil
+
+	case OpAddIndex:
+		return []string{fmt.Sprintf("CREATE INDEX %s ON %s (%s);",
+			s.Dialect.QuoteIdentifier(op.Name), table, s.quoteColumns(op.Columns))}, nil
+
+	case OpDropIndex:
+		switch d {
+		case "mysql", "sqlserver":
+			return []string{fmt.Sprintf("DROP INDEX %s ON %s;", s.Dialect.QuoteIdentifier(op.Name), table)}, nil
+		default:
+			return []string{fmt.Sprintf("DROP INDEX %s;", s.Dialect.QuoteIdentifier(op.Name))}, nil
+		}
+
+	case OpAddUnique:
+		if d == "sqlite" {
+			return []string{fmt.Sprintf("CREATE UNIQUE INDEX %s ON %s (%s);",
+				s.Dialect.QuoteIdentifier(op.Name), table, s.quoteColumns(op.Columns))}, nil
+		}
+		return []string{fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s UNIQUE (%s);",
+			table, s.Dialect.QuoteIdentifier(op.Name), s.quoteColumns(op.Columns))}, nil
+
+	case OpDropUnique:
+		switch d {
+		case "sqlite":
+			return []string{fmt.Sprintf("DROP INDEX %s;", s.Dialect.QuoteIdentifier(op.Name))}, nil
+		case "mysql":
+			return []string{fmt.Sprintf("ALTER TABLE %s DROP INDEX %s;", table, s.Dialect.QuoteIdentifier(op.Name))}, nil
+		default:
+			return []string{fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s;", table, s.Dialect.QuoteIdentifier(op.Name))}, nil
+		}
+
+	case OpAddForeign:
+		if d == "sqlite" {
+			return nil, fmt.Errorf("db.alter_table: adding foreign keys requires a table rebuild on sqlite")
+		}
+		return []string{fmt.Sprintf("ALTER TABLE %s ADD %s;", table, s.foreignKeySQL(op.Foreign))}, nil
+
+	case OpDropForeign:
+		switch d {
+		case "sqlite":
+			return nil, fmt.Errorf("db.alter_table: dropping foreign keys requires a table rebuild on sqlite")
+		case "mysql":
+			return []string{fmt.Sprintf("ALTER TABLE %s DROP FOREIGN KEY %s;", table, s.Dialect.QuoteIdentifier(op.Name))}, nil
+		default:
+			return []string{fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s;", table, s.Dialect.QuoteIdentifier(op.Name))}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("db.alter_table: unknown operation '%s'", op.Kind)
+}
+
+func escapeSQLString(s string) string {
+	return strings.ReplaceAll(s, "'", "''")
+}
+
+// sqliteNeedsRebuild reports whether SQLite's ALTER TABLE cannot express the operation
+func sqliteNeedsRebuild(op AlterOp) bool {
+	switch op.Kind {
+	case OpModifyColumn, OpDropColumn, OpAddForeign, OpDropForeign:
+		return true
+	case OpAddColumn:
+		// ADD COLUMN cannot add a primary key or a NOT NULL column without a default
+		return op.Column.Type == "id" || !op.Column.Nullable
+	}
+	return false
+}
+
+// --- SQLite table rebuild ---
+
+type sqliteColumn struct {
+	Name    string
+	Type    string
+	NotNull bool
+	Default *string
+	PK      bool
+	Unique  bool
+	Def     string // Full definition override for added / modified columns
+}
+
+type sqliteForeignKey struct {
+	Name       string
+	Columns    []string
+	Table      string
+	RefColumns []string
+	OnUpdate   string
+	OnDelete   string
+}
+
+type sqliteIndex struct {
+	SQL     string
+	Columns []string
+}
+
+type sqliteTable struct {
+	Columns       []sqliteColumn
+	ForeignKeys   []sqliteForeignKey
+	Uniques       [][]string // Multi-column UNIQUE constraints
+	Indexes       []sqliteIndex
+	Autoincrement bool
+}
+
+// readSQLiteTable reads the current structure of a table with dbmanager.DescribeTable, plus
+// what only the rebuild needs: the AUTOINCREMENT keyword and the SQL of each index
+func (s *SchemaState) readSQLiteTable(ctx context.Context, exec SQLExecutor) (*sqliteTable, error) {
+	var createSQL string
+	if err := exec.QueryRowContext(ctx, "SELECT sql FROM sqlite_master WHERE type = 'table' AND name = ?", s.Table).Scan(&createSQL); err != nil {
+		return nil, fmt.Errorf("db.alter_table: table '%s' not found: %w", s.Table, err)
+	}
+	ts, err := dbmanager.DescribeTable(ctx, exec, dbmanager.SQLiteDialect{}, s.Table)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &sqliteTable{Autoincrement: strings.Contains(strings.ToUpper(createSQL), "AUTOINCREMENT")}
+	for _, c := range ts.Columns {
+		info.Columns = append(info.Columns, sqliteColumn{
+			Name: c.Name,
+			Type: c.Type,
+			// INTEGER PRIMARY KEY is the rowid alias, NOT NULL is implied
+			NotNull: !c.Nullable && !c.AutoIncrement,
+			Default: c.Default,
+			PK:      c.PrimaryKey,
+		})
+	}
+
+	// SQLite does not store constraint names, the rebuilt foreign keys stay unnamed
+	for _, fk := range ts.ForeignKeys {
+		info.ForeignKeys = append(info.ForeignKeys, sqliteForeignKey{
+			Columns:    fk.Columns,
+			Table:      fk.RefTable,
+			RefColumns: fk.RefColumns,
+			OnUpdate:   fk.OnUpdate,
+			OnDelete:   fk.OnDelete,
+		})
+	}
+
+	for _, idx := range ts.Indexes {
+		if idx.Primary {
+			continue
+		}
+		// Indexes created with CREATE INDEX are recreated from their SQL; UNIQUE
+		// constraints have no SQL and become part of the new table definition
+		var indexSQL sql.NullString
+		exec.QueryRowContext(ctx, "SELECT sql FROM sqlite_master WHERE type = 'index' AND name = ?", idx.Name).Scan(&indexSQL)
+		switch {
+		case indexSQL.Valid:
+			info.Indexes = append(info.Indexes, sqliteIndex{SQL: indexSQL.String, Columns: idx.Columns})
+		case !idx.Unique:
+		case len(idx.Columns) == 1:
+			for i := range info.Columns {
+				if info.Columns[i].Name == idx.Columns[0] {
+					info.Columns[i].Unique = true
+				}
+			}
+		default:
+			info.Uniques = append(info.Uniques, idx.Columns)
+		}
+	}
+
+	return info, nil
+}
+
+func containsColumn(columns []string, name string) bool {
+	for _, c := range columns {
+		if strings.EqualFold(c, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// SQLiteRebuildSQL implements alterations SQLite's ALTER TABLE does not support by
+// creating a new table, copying the rows, dropping the old table and renaming the
+// new one, then recreating the remaining indexes. Foreign key enforcement is off
+// by default on SQLite connections, so the drop does not cascade to child rows.
+func (s *SchemaState) SQLiteRebuildSQL(ctx context.Context, exec SQLExecutor, ops []AlterOp) ([]string, error) {
+	info, err := s.readSQLiteTable(ctx, exec)
+	if err != nil {
+		return nil, err
+	}
+
+	// Columns copied from the old table (added columns are not)
+	copied := make(map[string]bool)
+	for _, c := range info.Columns {
+		copied[c.Name] = true
+	}
+
+	for _, op := range ops {
+		switch op.Kind {
+		case OpAddColumn:
+			info.Columns = append(info.Columns, sqliteColumn{Name: op.Column.Name, Def: s.columnSQL(op.Column)})
+
+		case OpModifyColumn:
+			if op.Column.Type == "id" {
+				return nil, fmt.Errorf("db.alter_table: cannot change column '%s' to an auto-increment primary key", op.Column.Name)
+			}
+			found := false
+			for i := range info.Columns {
+				if strings.EqualFold(info.Columns[i].Name, op.Column.Name) {
+					info.Columns[i].Def = s.columnSQL(op.Column)
+					found = true
+				}
+			}
+			if !found {
+				return nil, fmt.Errorf("db.alter_table: column '%s' not found in '%s'", op.Column.Name, s.Table)
+			}
+
+		case OpDropColumn:
+			name := op.Column.Name
+			var cols []sqliteColumn
+			for _, c := range info.Columns {
+				if !strings.EqualFold(c.Name, name) {
+					cols = append(cols, c)
+				}
+			}
+			if len(cols) == len(info.Columns) {
+				return nil, fmt.Errorf("db.alter_table: column '%s' not found in '%s'", name, s.Table)
+			}
+			info.Columns = cols
+			delete(copied, name)
+
+			var fks []sqliteForeignKey
+			for _, fk := range info.ForeignKeys {
+				if !containsColumn(fk.Columns, name) {
+					fks = append(fks, fk)
+				}
+			}
+			info.ForeignKeys = fks
+
+			var uniques [][]string
+			for _, u := range info.Uniques {
+				if !containsColumn(u, name) {
+					uniques = append(uniques, u)
+				}
+			}
+			info.Uniques = uniques
+
+			var indexes []sqliteIndex
+			for _, idx := range info.Indexes {
+				if !containsColumn(idx.Columns, name) {
+					indexes = append(indexes, idx)
+				}
+			}
+			info.Indexes = indexes
+
+		case OpAddForeign:
+			fk := op.Foreign
+			info.ForeignKeys = append(info.ForeignKeys, sqliteForeignKey{
+				Name:       fk.Name,
+				Columns:    []string{fk.Column},
+				Table:      fk.References,
+				RefColumns: []string{fk.On},
+				OnDelete:   fk.OnDelete,
+			})
+
+		case OpDropForeign:
+			var fks []sqliteForeignKey
+			for _, fk := range info.ForeignKeys {
+				if len(fk.Columns) == 1 && strings.EqualFold(fk.Columns[0], op.Foreign.Column) {
+					continue
+				}
+				fks = append(fks, fk)
+			}
+			if len(fks) == len(info.ForeignKeys) {
+				return nil, fmt.Errorf("db.alter_table: no foreign key on column '%s' in '%s'", op.Foreign.Column, s.Table)
+			}
+			info.ForeignKeys = fks
+		}
+	}
+
+	tmp := "_zeno_tmp_" + s.Table
+	return s.sqliteRebuildStatements(info, tmp, copied), nil
+}
+
+func (s *SchemaState) sqliteRebuildStatements(info *sqliteTable, tmp string, copied map[string]bool) []string {
+	q := s.Dialect.QuoteIdentifier
+
+	var pks []string
+	for _, c := range info.Columns {
+		if c.PK && c.Def == "" {
+			pks = append(pks, c.Name)
+		}
+	}
+
+	var defs []string
+	var copyCols []string
+	for _, c := range info.Columns {
+		if copied[c.Name] {
+			copyCols = append(copyCols, q(c.Name))
+		}
+		if c.Def != "" {
+			defs = append(defs, "  "+c.Def)
+			continue
+		}
+
+		def := q(c.Name)
+		if c.Type != "" {
+			def += " " + c.Type
+		}
+		if c.PK && len(pks) == 1 {
+			def += " PRIMARY KEY"
+			if info.Autoincrement {
+				def += " AUTOINCREMENT"
+			}
+		}
+		if c.NotNull {
+			def += " NOT NULL"
+		}
+		if c.Default != nil {
+			def += " DEFAULT " + *c.Default
+		}
+		if c.Unique {
+			def += " UNIQUE"
+		}
+		defs = append(defs, "  "+def)
+	}
+
+	if len(pks) > 1 {
+		defs = append(defs, "  PRIMARY KEY ("+s.quoteColumns(pks)+")")
+	}
+	for _, u := range info.Uniques {
+		defs = append(defs, "  UNIQUE ("+s.quoteColumns(u)+")")
+	}
+	for _, fk := range info.ForeignKeys {
+		def := ""
+		if fk.Name != "" {
+			def = "CONSTRAINT " + q(fk.Name) + " "
+		}
+		def += fmt.Sprintf("FOREIGN KEY (%s) REFERENCES %s", s.quoteColumns(fk.Columns), q(fk.Table))
+		if len(fk.RefColumns) > 0 {
+			def += "(" + s.quoteColumns(fk.RefColumns) + ")"
+		}
+		if fk.OnUpdate != "" && fk.OnUpdate != "NO ACTION" {
+			def += " ON UPDATE " + fk.OnUpdate
+		}
+		if fk.OnDelete != "" && fk.OnDelete != "NO ACTION" {
+			def += " ON DELETE " + fk.OnDelete
+		}
+		defs = append(defs, "  "+def)
+	}
+
+	stmts := []string{
+		fmt.Sprintf("CREATE TABLE %s (\n%s\n);", q(tmp), strings.Join(defs, ",\n")),
+	}
+	if len(copyCols) > 0 {
+		cols := strings.Join(copyCols, ", ")
+		stmts = append(stmts, fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s;", q(tmp), cols, cols, q(s.Table)))
+	}
+	stmts = append(stmts,
+		fmt.Sprintf("DROP TABLE %s;", q(s.Table)),
+		fmt.Sprintf("ALTER TABLE %s RENAME TO %s;", q(tmp), q(s.Table)),
+	)
+	for _, idx := range info.Indexes {
+		stmts = append(stmts, strings.TrimSuffix(idx.SQL, ";")+";")
+	}
+	return stmts
+}
+
+func registerAlterSlots(eng *engine.Engine, dbMgr *dbmanager.DBManager) {
+	// DB.ALTER_TABLE
+	eng.Register("db.alter_table", func(ctx context.Context, node *engine.Node, scope *engine.Scope) error {
+		tableName := coerce.ToString(resolveValue(node.Value, scope))
+		dbName := migrationDB(scope)
+
+		for _, c := range node.Children {
+			if c.Name == "db" {
+				dbName = coerce.ToString(parseNodeValue(c, scope))
+			}
+		}
+
+		dialect := dbMgr.GetDialect(dbName)
+		if dialect == nil {
+			dialect = dbmanager.SQLiteDialect{}
+		}
+
+		state := &SchemaState{
+			Table:   tableName,
+			DBName:  dbName,
+			Dialect: dialect,
+			Alter:   true,
+		}
+
+		if err := executeSchemaBlock(ctx, eng, node, scope, state); err != nil {
+			return err
+		}
+
+		exec := func(stmts []string) error {
+			for _, stmt := range stmts {
+				slog.Info("Generated SQL", "sql", stmt)
+				if err := execSchemaSQL(ctx, scope, dbMgr, dbName, stmt); err != nil {
+					return fmt.Errorf("db.alter_table: %w", err)
+				}
+			}
+			return nil
+		}
+
+		ops := state.Ops
+		for i := 0; i < len(ops); {
+			// Consecutive SQLite operations that need a rebuild share a single rebuild
+			if dialect.Name() == "sqlite" && sqliteNeedsRebuild(ops[i]) {
+				j := i
+				for j < len(ops) && sqliteNeedsRebuild(ops[j]) {
+					j++
+				}
+				reader, _, err := getExecutor(scope, dbMgr, dbName)
+				if err != nil {
+					return err
+				}
+				stmts, err := state.SQLiteRebuildSQL(ctx, reader, ops[i:j])
+				if err != nil {
+					return err
+				}
+				if err := exec(stmts); err != nil {
+					return err
+				}
+				i = j
+				continue
+			}
+
+			stmts, err := state.AlterSQL(ops[i])
+			if err != nil {
+				return err
+			}
+			if err := exec(stmts); err != nil {
+				return err
+			}
+			i++
+		}
+		return nil
+	}, engine.SlotMeta{
+		Description: "Change an existing table: add, modify, rename or drop columns, indexes and foreign keys.",
+		Example:     "db.alter_table: 'users' {\n  db.string: 'phone' { nullable: true }\n  db.text: 'bio' { change: true }\n  db.rename_column: 'name' { to: 'full_name' }\n  db.drop_column: 'legacy_code'\n  db.index: 'email'\n}",
+		ValueType:   "string",
+		Inputs: map[string]engine.InputMeta{
+			"(value)": {Description: "The name of the table to alter", Required: true, Type: "string"},
+			"db":      {Description: "The database connection name (Default: the migration connection or 'default')", Required: false, Type: "string"},
+		},
+	})
+
+	// Helper untuk slot yang hanya berlaku di dalam db.alter_table
+	alterState := func(slotName string, scope *engine.Scope) (*SchemaState, error) {
+		stateVal, ok := scope.Get("_schema_state")
+		if !ok {
+			return nil, fmt.Errorf("%s called outside of db.alter_table", slotName)
+		}
+		state := stateVal.(*SchemaState)
+		if !state.Alter {
+			return nil, fmt.Errorf("%s can only be used inside db.alter_table", slotName)
+		}
+		return state, nil
+	}
+
+	// DB.DROP_COLUMN
+	eng.Register("db.drop_column", func(ctx context.Context, node *engine.Node, scope *engine.Scope) error {
+		state, err := alterState("db.drop_column", scope)
+		if err != nil {
+			return err
+		}
+		for _, col := range schemaColumns(resolveValue(node.Value, scope)) {
+			state.Ops = append(state.Ops, AlterOp{Kind: OpDropColumn, Column: ColumnDef{Name: col}})
+		}
+		return nil
+	}, engine.SlotMeta{
+		Description: "Drop one or more columns inside a db.alter_table block.",
+		Example:     "db.drop_column: 'legacy_code'",
+		ValueType:   "string",
+		Inputs: map[string]engine.InputMeta{
+			"(value)": {Description: "Column name, or a comma separated list of columns", Required: true, Type: "string"},
+		},
+	})
+
+	// DB.RENAME_COLUMN
+	eng.Register("db.rename_column", func(ctx context.Context, node *engine.Node, scope *engine.Scope) error {
+		state, err := alterState("db.rename_column", scope)
+		if err != nil {
+			return err
+		}
+		from := coerce.ToString(resolveValue(node.Value, scope))
+		to := ""
+		for _, c := range node.Children {
+			if c.Name == "to" {
+				to = coerce.ToString(parseNodeValue(c, scope))
+			}
+		}
+		if to == "" {
+			return fmt.Errorf("db.rename_column: 'to' is required")
+		}
+		state.Ops = append(state.Ops, AlterOp{Kind: OpRenameColumn, Column: ColumnDef{Name: from}, To: to})
+		return nil
+	}, engine.SlotMeta{
+		Description: "Rename a column inside a db.alter_table block.",
+		Example:     "db.rename_column: 'name' {\n  to: 'full_name'\n}",
+		ValueType:   "string",
+		Inputs: map[string]engine.InputMeta{
+			"(value)": {Description: "The current column name", Required: true, Type: "string"},
+			"to":      {Description: "The new column name", Required: true, Type: "string"},
+		},
+	})
+
+	// DB.INDEX & DB.UNIQUE (create_table dan alter_table)
+	registerIndexSlot := func(slotName, kind, suffix, description string) {
+		eng.Register(slotName, func(ctx context.Context, node *engine.Node, scope *engine.Scope) error {
+			stateVal, ok := scope.Get("_schema_state")
+			if !ok {
+				return fmt.Errorf("%s called outside of db.create_table or db.alter_table", slotName)
+			}
+			state := stateVal.(*SchemaState)
+
+			cols := schemaColumns(resolveValue(node.Value, scope))
+			name := ""
+			for _, c := range node.Children {
+				if c.Name == "name" {
+					name = coerce.ToString(parseNodeValue(c, scope))
+				}
+			}
+			if len(cols) == 0 {
+				return fmt.Errorf("%s: at least one column is required", slotName)
+			}
+			if name == "" {
+				name = indexName(state.Table, cols, suffix)
+			}
+
+			state.Ops = append(state.Ops, AlterOp{Kind: kind, Name: name, Columns: cols})
+			return nil
+		}, engine.SlotMeta{
+			Description: description,
+			Example:     fmt.Sprintf("%s: 'first_name, last_name'", slotName),
+			ValueType:   "string",
+			Inputs: map[string]engine.InputMeta{
+				"(value)": {Description: "Column name, or a comma separated list of columns", Required: true, Type: "string"},
+				"name":    {Description: fmt.Sprintf("Index name (Default: '<table>_<columns>_%s')", suffix), Required: false, Type: "string"},
+			},
+		})
+	}
+	registerIndexSlot("db.index", OpAddIndex, "index", "Add an index on one or more columns inside db.create_table or db.alter_table.")
+	registerIndexSlot("db.unique", OpAddUnique, "unique", "Add a unique constraint on one or more columns inside db.create_table or db.alter_table.")
+
+	// DB.DROP_INDEX & DB.DROP_UNIQUE
+	registerDropIndexSlot := func(slotName, kind, suffix, description string) {
+		eng.Register(slotName, func(ctx context.Context, node *engine.Node, scope *engine.Scope) error {
+			state, err := alterState(slotName, scope)
+			if err != nil {
+				return err
+			}
+
+			name := coerce.ToString(resolveValue(node.Value, scope))
+			for _, c := range node.Children {
+				if c.Name == "columns" {
+					name = indexName(state.Table, schemaColumns(parseNodeValue(c, scope)), suffix)
+				}
+			}
+			if name == "" {
+				return fmt.Errorf("%s: index name or 'columns' is required", slotName)
+			}
+
+			state.Ops = append(state.Ops, AlterOp{Kind: kind, Name: name})
+			return nil
+		}, engine.SlotMeta{
+			Description: description,
+			Example:     fmt.Sprintf("%s: 'users_email_%s'", slotName, suffix),
+			ValueType:   "string",
+			Inputs: map[string]engine.InputMeta{
+				"(value)": {Description: "The index name", Required: false, Type: "string"},
+				"columns": {Description: "Derive the conventional name from these columns instead", Required: false, Type: "string"},
+			},
+		})
+	}
+	registerDropIndexSlot("db.drop_index", OpDropIndex, "index", "Drop an index inside a db.alter_table block.")
+	registerDropIndexSlot("db.drop_unique", OpDropUnique, "unique", "Drop a unique constraint inside a db.alter_table block.")
+
+	// DB.DROP_FOREIGN
+	eng.Register("db.drop_foreign", func(ctx context.Context, node *engine.Node, scope *engine.Scope) error {
+		state, err := alterState("db.drop_foreign", scope)
+		if err != nil {
+			return err
+		}
+
+		col := coerce.ToString(resolveValue(node.Value, scope))
+		name := indexName(state.Table, []string{col}, "foreign")
+		for _, c := range node.Children {
+			if c.Name == "name" {
+				name = coerce.ToString(parseNodeValue(c, scope))
+			}
+		}
+
+		state.Ops = append(state.Ops, AlterOp{Kind: OpDropForeign, Name: name, Foreign: ForeignKeyDef{Name: name, Column: col}})
+		return nil
+	}, engine.SlotMeta{
+		Description: "Drop the foreign key on a column inside a db.alter_table block.",
+		Example:     "db.drop_foreign: 'user_id'",
+		ValueType:   "string",
+		Inputs: map[string]engine.InputMeta{
+			"(value)": {Description: "The column the foreign key is defined on", Required: true, Type: "string"},
+			"name":    {Description: "Constraint name (Default: '<table>_<column>_foreign')", Required: false, Type: "string"},
+		},
+	})
+}
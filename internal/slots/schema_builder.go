@@ -18,9 +18,11 @@ type ColumnDef struct {
 	Nullable  bool
 	Precision int
 	Scale     int
+	Default   string // SQL expression, empty means no default
 }
 
 type ForeignKeyDef struct {
+	Name       string
 	Column     string
 	References string
 	On         string
@@ -33,6 +35,66 @@ type SchemaState struct {
 	ForeignKeys []ForeignKeyDef
 	DBName      string
 	Dialect     dbmanager.Dialect
+
+	// Alter is true inside db.alter_table, where every slot records an AlterOp
+	Alter bool
+	Ops   []AlterOp
+}
+
+// migrationDB returns the connection targeted by the running migration
+// (set by the migrator), falling back to the 'default' connection.
+func migrationDB(scope *engine.Scope) string {
+	if v, ok := scope.Get("_migration_db"); ok {
+		if name := coerce.ToString(v); name != "" {
+			return name
+		}
+	}
+	return "default"
+}
+
+// execSchemaSQL executes a generated schema statement. When the migrator runs in
+// pretend mode the statement is handed to its recorder instead of the database
+// (see getExecutor), and inside a migration transaction it joins that transaction.
+func execSchemaSQL(ctx context.Context, scope *engine.Scope, dbMgr *dbmanager.DBManager, dbName, query string, args ...interface{}) error {
+	executor, _, err := getExecutor(scope, dbMgr, dbName)
+	if err != nil {
+		return err
+	}
+
+	_, err = executor.ExecContext(ctx, query, args...)
+	return err
+}
+
+// executeSchemaBlock runs the children of a db.create_table / db.alter_table block
+// against the given state. Children may omit the 'db.' prefix (string: 'name').
+func executeSchemaBlock(ctx context.Context, eng *engine.Engine, node *engine.Node, scope *engine.Scope, state *SchemaState) error {
+	innerScope := engine.NewScope(scope)
+	innerScope.Set("_schema_state", state)
+
+	for _, c := range node.Children {
+		if c.Name == "db" {
+			continue
+		}
+
+		callName := c.Name
+		if !strings.HasPrefix(callName, "db.") {
+			callName = "db." + callName
+		}
+
+		callNode := &engine.Node{
+			Name:     callName,
+			Value:    c.Value,
+			Children: c.Children,
+			Line:     c.Line,
+			Col:      c.Col,
+			Filename: c.Filename,
+		}
+
+		if err := eng.Execute(ctx, callNode, innerScope); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func RegisterSchemaSlots(eng *engine.Engine, dbMgr *dbmanager.DBManager) {
@@ -78,7 +140,7 @@ func RegisterSchemaSlots(eng *engine.Engine, dbMgr *dbmanager.DBManager) {
 		}
 
 		tableName := coerce.ToString(resolveValue(node.Value, scope))
-		dbName := "default"
+		dbName := migrationDB(scope)
 
 		for _, c := range node.Children {
 			if c.Name == "db" {
@@ -97,31 +159,8 @@ func RegisterSchemaSlots(eng *engine.Engine, dbMgr *dbmanager.DBManager) {
 			Dialect: dialect,
 		}
 
-		innerScope := engine.NewScope(scope)
-		innerScope.Set("_schema_state", state)
-
-		for _, c := range node.Children {
-			if c.Name == "db" {
-				continue
-			}
-
-			callName := c.Name
-			if !strings.HasPrefix(callName, "db.") {
-				callName = "db." + callName
-			}
-
-			callNode := &engine.Node{
-				Name:     callName,
-				Value:    c.Value,
-				Children: c.Children,
-				Line:     c.Line,
-				Col:      c.Col,
-				Filename: c.Filename,
-			}
-
-			if err := eng.Execute(ctx, callNode, innerScope); err != nil {
-				return err
-			}
+		if err := executeSchemaBlock(ctx, eng, node, scope, state); err != nil {
+			return err
 		}
 
 		// Generate SQL and execute
@@ -132,27 +171,38 @@ func RegisterSchemaSlots(eng *engine.Engine, dbMgr *dbmanager.DBManager) {
 
 		slog.Info("Generated SQL", "sql", sql)
 
-		db := dbMgr.GetConnection(dbName)
-		if db == nil {
-			return fmt.Errorf("database connection '%s' not found", dbName)
+		if err := execSchemaSQL(ctx, scope, dbMgr, dbName, sql); err != nil {
+			return err
 		}
 
-		_, err = db.ExecContext(ctx, sql)
-		return err
+		// Indexes declared with db.index / db.unique are created after the table
+		for _, op := range state.Ops {
+			stmts, err := state.AlterSQL(op)
+			if err != nil {
+				return err
+			}
+			for _, stmt := range stmts {
+				slog.Info("Generated SQL", "sql", stmt)
+				if err := execSchemaSQL(ctx, scope, dbMgr, dbName, stmt); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
 	}, engine.SlotMeta{
 		Description: "Create a new database table using a fluent schema building definition.",
 		Example:     "db.create_table: 'posts' {\n  db.id: 'id'\n  db.string: 'title'\n  db.text: 'body'\n}",
 		ValueType:   "string",
 		Inputs: map[string]engine.InputMeta{
 			"(value)": {Description: "The name of the table to create", Required: true, Type: "string"},
-			"db":      {Description: "The database connection name (Default: 'default')", Required: false, Type: "string"},
+			"db":      {Description: "The database connection name (Default: the migration connection or 'default')", Required: false, Type: "string"},
 		},
 	})
 
 	// DB.DROP_TABLE
 	eng.Register("db.drop_table", func(ctx context.Context, node *engine.Node, scope *engine.Scope) error {
 		tableName := coerce.ToString(resolveValue(node.Value, scope))
-		dbName := "default"
+		dbName := migrationDB(scope)
 
 		for _, c := range node.Children {
 			if c.Name == "db" {
@@ -160,11 +210,6 @@ func RegisterSchemaSlots(eng *engine.Engine, dbMgr *dbmanager.DBManager) {
 			}
 		}
 
-		db := dbMgr.GetConnection(dbName)
-		if db == nil {
-			return fmt.Errorf("database connection '%s' not found", dbName)
-		}
-
 		dialect := dbMgr.GetDialect(dbName)
 		if dialect == nil {
 			dialect = dbmanager.SQLiteDialect{}
@@ -173,15 +218,14 @@ func RegisterSchemaSlots(eng *engine.Engine, dbMgr *dbmanager.DBManager) {
 		sql := fmt.Sprintf("DROP TABLE %s;", dialect.QuoteIdentifier(tableName))
 		slog.Info("Generated SQL", "sql", sql)
 
-		_, err := db.ExecContext(ctx, sql)
-		return err
+		return execSchemaSQL(ctx, scope, dbMgr, dbName, sql)
 	}, engine.SlotMeta{
 		Description: "Drop a database table if it exists.",
 		Example:     "db.drop_table: 'users'",
 		ValueType:   "string",
 		Inputs: map[string]engine.InputMeta{
 			"(value)": {Description: "The name of the table to drop", Required: true, Type: "string"},
-			"db":      {Description: "The database connection name (Default: 'default')", Required: false, Type: "string"},
+			"db":      {Description: "The database connection name (Default: the migration connection or 'default')", Required: false, Type: "string"},
 		},
 	})
 
@@ -189,16 +233,20 @@ func RegisterSchemaSlots(eng *engine.Engine, dbMgr *dbmanager.DBManager) {
 	eng.Register("db.foreign", func(ctx context.Context, node *engine.Node, scope *engine.Scope) error {
 		stateVal, ok := scope.Get("_schema_state")
 		if !ok {
-			return fmt.Errorf("db.foreign called outside of db.create_table")
+			return fmt.Errorf("db.foreign called outside of db.create_table or db.alter_table")
 		}
 		state := stateVal.(*SchemaState)
 
 		colName := coerce.ToString(resolveValue(node.Value, scope))
+		name := ""
 		references := ""
 		on := "id"
 		onDelete := "CASCADE"
 
 		for _, c := range node.Children {
+			if c.Name == "name" {
+				name = coerce.ToString(parseNodeValue(c, scope))
+			}
 			if c.Name == "references" {
 				references = coerce.ToString(parseNodeValue(c, scope))
 			}
@@ -210,16 +258,27 @@ func RegisterSchemaSlots(eng *engine.Engine, dbMgr *dbmanager.DBManager) {
 			}
 		}
 
-		state.ForeignKeys = append(state.ForeignKeys, ForeignKeyDef{
+		if name == "" {
+			name = indexName(state.Table, []string{colName}, "foreign")
+		}
+
+		fk := ForeignKeyDef{
+			Name:       name,
 			Column:     colName,
 			References: references,
 			On:         on,
 			OnDelete:   onDelete,
-		})
+		}
+
+		if state.Alter {
+			state.Ops = append(state.Ops, AlterOp{Kind: OpAddForeign, Name: name, Foreign: fk})
+		} else {
+			state.ForeignKeys = append(state.ForeignKeys, fk)
+		}
 
 		return nil
 	}, engine.SlotMeta{
-		Description: "Define a foreign key constraint for a column inside a db.create_table block.",
+		Description: "Define a foreign key constraint for a column inside a db.create_table or db.alter_table block.",
 		Example:     "db.foreign: 'user_id' {\n  references: 'users'\n  on: 'id'\n  on_delete: 'CASCADE'\n}",
 		ValueType:   "string",
 		Inputs: map[string]engine.InputMeta{
@@ -227,9 +286,13 @@ func RegisterSchemaSlots(eng *engine.Engine, dbMgr *dbmanager.DBManager) {
 			"references": {Description: "The parent table name reference", Required: true, Type: "string"},
 			"on":         {Description: "The referenced parent column name (Default: 'id')", Required: false, Type: "string"},
 			"on_delete":  {Description: "Action on parent record deletion (e.g., CASCADE, SET NULL)", Required: false, Type: "string"},
+			"name":       {Description: "Constraint name (Default: '<table>_<column>_foreign')", Required: false, Type: "string"},
 		},
 	})
 
+	registerAlterSlots(eng, dbMgr)
+	registerIntrospectionSlots(eng, dbMgr)
+
 	// Column slots
 	registerColumnSlot(eng, "db.id", "id")
 	registerColumnSlot(eng, "db.string", "string")
@@ -249,9 +312,12 @@ func registerColumnSlot(eng *engine.Engine, slotName, colType string) {
 	}
 
 	inputs := map[string]engine.InputMeta{
-		"(value)":  {Description: "The name of the column", Required: true, Type: "string"},
-		"unique":   {Description: "Whether the column values must be unique (Default: false)", Required: false, Type: "bool"},
-		"nullable": {Description: "Whether the column allows NULL values (Default: true)", Required: false, Type: "bool"},
+		"(value)":     {Description: "The name of the column", Required: true, Type: "string"},
+		"unique":      {Description: "Whether the column values must be unique (Default: false)", Required: false, Type: "bool"},
+		"nullable":    {Description: "Whether the column allows NULL values (Default: true)", Required: false, Type: "bool"},
+		"change":      {Description: "Inside db.alter_table, modify the existing column instead of adding it (Default: false)", Required: false, Type: "bool"},
+		"default":     {Description: "Default value (strings are quoted automatically)", Required: false, Type: "any"},
+		"default_raw": {Description: "Default as a raw SQL expression, e.g. CURRENT_TIMESTAMP", Required: false, Type: "string"},
 	}
 
 	example := fmt.Sprintf("%s: 'column_name'", slotName)
@@ -278,7 +344,7 @@ func registerColumnSlot(eng *engine.Engine, slotName, colType string) {
 	eng.Register(slotName, func(ctx context.Context, node *engine.Node, scope *engine.Scope) error {
 		stateVal, ok := scope.Get("_schema_state")
 		if !ok {
-			return fmt.Errorf("%s called outside of db.create_table", slotName)
+			return fmt.Errorf("%s called outside of db.create_table or db.alter_table", slotName)
 		}
 		state := stateVal.(*SchemaState)
 
@@ -288,8 +354,19 @@ func registerColumnSlot(eng *engine.Engine, slotName, colType string) {
 		nullable := true
 		precision := 0
 		scale := 0
+		change := false
+		defaultExpr := ""
 
 		for _, c := range node.Children {
+			if c.Name == "default" {
+				defaultExpr = defaultLiteral(parseNodeValue(c, scope), state.Dialect)
+			}
+			if c.Name == "default_raw" {
+				defaultExpr = coerce.ToString(parseNodeValue(c, scope))
+			}
+			if c.Name == "change" {
+				change, _ = coerce.ToBool(parseNodeValue(c, scope))
+			}
 			if c.Name == "limit" || c.Name == "length" {
 				limit, _ = coerce.ToInt(parseNodeValue(c, scope))
 			}
@@ -307,7 +384,7 @@ func registerColumnSlot(eng *engine.Engine, slotName, colType string) {
 			}
 		}
 
-		state.Columns = append(state.Columns, ColumnDef{
+		col := ColumnDef{
 			Name:      colName,
 			Type:      colType,
 			Limit:     limit,
@@ -315,8 +392,19 @@ func registerColumnSlot(eng *engine.Engine, slotName, colType string) {
 			Nullable:  nullable,
 			Precision: precision,
 			Scale:     scale,
-		})
+			Default:   defaultExpr,
+		}
+
+		if state.Alter {
+			kind := OpAddColumn
+			if change {
+				kind = OpModifyColumn
+			}
+			state.Ops = append(state.Ops, AlterOp{Kind: kind, Column: col})
+			return nil
+		}
 
+		state.Columns = append(state.Columns, col)
 		return nil
 	}, meta)
 }
@@ -329,25 +417,11 @@ func (s *SchemaState) BuildSQL() (string, error) {
 
 	var colStrings []string
 	for _, col := range s.Columns {
-		colStr := "  " + s.Dialect.QuoteIdentifier(col.Name) + " " + s.translateType(col)
-		if !col.Nullable {
-			colStr += " NOT NULL"
-		}
-		if col.Unique {
-			colStr += " UNIQUE"
-		}
-		colStrings = append(colStrings, colStr)
+		colStrings = append(colStrings, "  "+s.columnSQL(col))
 	}
 
 	for _, fk := range s.ForeignKeys {
-		fkStr := fmt.Sprintf("  FOREIGN KEY (%s) REFERENCES %s(%s)",
-			s.Dialect.QuoteIdentifier(fk.Column),
-			s.Dialect.QuoteIdentifier(fk.References),
-			s.Dialect.QuoteIdentifier(fk.On))
-		if fk.OnDelete != "" {
-			fkStr += " ON DELETE " + fk.OnDelete
-		}
-		colStrings = append(colStrings, fkStr)
+		colStrings = append(colStrings, "  "+s.foreignKeySQL(fk))
 	}
 
 	sb.WriteString(strings.Join(colStrings, ",\n"))
@@ -368,6 +442,8 @@ func (s *SchemaState) translateType(col ColumnDef) string {
 			return "INT AUTO_INCREMENT PRIMARY KEY"
 		case "postgres":
 			return "SERIAL PRIMARY KEY"
+		case "sqlserver":
+			return "INT IDENTITY(1,1) PRIMARY KEY"
 		default:
 			return "INTEGER PRIMARY KEY"
 		}
@@ -381,6 +457,8 @@ func (s *SchemaState) translateType(col ColumnDef) string {
 			return "TEXT"
 		case "mysql", "postgres":
 			return fmt.Sprintf("VARCHAR(%d)", limit)
+		case "sqlserver":
+			return fmt.Sprintf("NVARCHAR(%d)", limit)
 		default:
 			return "TEXT"
 		}
@@ -388,7 +466,7 @@ func (s *SchemaState) translateType(col ColumnDef) string {
 		switch dialectName {
 		case "sqlite":
 			return "INTEGER"
-		case "mysql", "postgres":
+		case "mysql", "postgres", "sqlserver":
 			return "INT"
 		default:
 			return "INTEGER"
@@ -399,6 +477,8 @@ func (s *SchemaState) translateType(col ColumnDef) string {
 			return "DATETIME"
 		case "mysql", "postgres":
 			return "TIMESTAMP"
+		case "sqlserver":
+			return "DATETIME2"
 		default:
 			return "DATETIME"
 		}
@@ -410,10 +490,15 @@ func (s *SchemaState) translateType(col ColumnDef) string {
 			return "TINYINT(1)"
 		case "postgres":
 			return "BOOLEAN"
+		case "sqlserver":
+			return "BIT"
 		default:
 			return "BOOLEAN"
 		}
 	case "text":
+		if dialectName == "sqlserver" {
+			return "NVARCHAR(MAX)"
+		}
 		return "TEXT"
 	case "decimal":
 		precision := col.Precision
@@ -433,6 +518,8 @@ func (s *SchemaState) translateType(col ColumnDef) string {
 			return "JSON"
 		case "postgres":
 			return "JSONB"
+		case "sqlserver":
+			return "NVARCHAR(MAX)"
 		default:
 			return "TEXT"
 		}
@@ -441,3 +528,56 @@ func (s *SchemaState) translateType(col ColumnDef) string {
 	}
 }
 
+// columnSQL renders a column definition: "name TYPE [NOT NULL] [UNIQUE]"
+func (s *SchemaState) columnSQL(col ColumnDef) string {
+	colStr := s.Dialect.QuoteIdentifier(col.Name) + " " + s.translateType(col)
+	if col.Default != "" {
+		colStr += " DEFAULT " + col.Default
+	}
+	if !col.Nullable {
+		colStr += " NOT NULL"
+	}
+	if col.Unique {
+		colStr += " UNIQUE"
+	}
+	return colStr
+}
+
+// foreignKeySQL renders a named table-level foreign key constraint
+func (s *SchemaState) foreignKeySQL(fk ForeignKeyDef) string {
+	name := fk.Name
+	if name == "" {
+		name = indexName(s.Table, []string{fk.Column}, "foreign")
+	}
+	fkStr := fmt.Sprintf("CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s(%s)",
+		s.Dialect.QuoteIdentifier(name),
+		s.Dialect.QuoteIdentifier(fk.Column),
+		s.Dialect.QuoteIdentifier(fk.References),
+		s.Dialect.QuoteIdentifier(fk.On))
+	if fk.OnDelete != "" {
+		fkStr += " ON DELETE " + fk.OnDelete
+	}
+	return fkStr
+}
+
+// defaultLiteral renders a default value as a SQL literal for the dialect
+func defaultLiteral(v interface{}, dialect dbmanager.Dialect) string {
+	switch val := v.(type) {
+	case nil:
+		return "NULL"
+	case bool:
+		if dialect != nil && dialect.Name() == "postgres" {
+			if val {
+				return "TRUE"
+			}
+			return "FALSE"
+		}
+		if val {
+			return "1"
+		}
+		return "0"
+	case int, int64, float64:
+		return fmt.Sprintf("%v", val)
+	}
+	return "'" + strings.ReplaceAll(coerce.ToString(v), "'", "''") + "'"
+}
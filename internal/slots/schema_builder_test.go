@@ -51,3 +51,308 @@ func TestSchemaSlots(t *testing.T) {
 		assert.Equal(t, "products", res["name"])
 	})
 }
+
+func TestSchemaPretendMode(t *testing.T) {
+	dbMgr := dbmanager.NewDBManager()
+	err := dbMgr.AddConnection("default", "sqlite", ":memory:", 1, 1)
+	if err != nil {
+		t.Fatalf("Failed to create in-memory db: %v", err)
+	}
+	defer dbMgr.Close()
+
+	eng := engine.NewEngine()
+	RegisterSchemaSlots(eng, dbMgr)
+
+	var captured []string
+	scope := engine.NewScope(nil)
+	scope.Set("_migration_pretend", func(conn, query string, args []interface{}) {
+		captured = append(captured, query)
+	})
+
+	node := &engine.Node{
+		Name:  "db.create_table",
+		Value: "pretend_products",
+		Children: []*engine.Node{
+			{Name: "db.id", Value: "id"},
+			{Name: "db.string", Value: "name"},
+		},
+	}
+	err = eng.Execute(context.Background(), node, scope)
+	assert.NoError(t, err)
+
+	if assert.Len(t, captured, 1) {
+		assert.Contains(t, captured[0], `CREATE TABLE "pretend_products"`)
+	}
+
+	// Nothing must reach the database
+	var count int
+	dbMgr.GetConnection("default").QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE name = 'pretend_products'").Scan(&count)
+	assert.Equal(t, 0, count)
+}
+
+func TestSchemaJoinsMigrationTransaction(t *testing.T) {
+	dbMgr := dbmanager.NewDBManager()
+	err := dbMgr.AddConnection("default", "sqlite", ":memory:", 1, 1)
+	if err != nil {
+		t.Fatalf("Failed to create in-memory db: %v", err)
+	}
+	defer dbMgr.Close()
+
+	eng := engine.NewEngine()
+	RegisterSchemaSlots(eng, dbMgr)
+
+	tx, err := dbMgr.GetConnection("default").Begin()
+	if err != nil {
+		t.Fatalf("Failed to begin transaction: %v", err)
+	}
+
+	scope := engine.NewScope(nil)
+	scope.Set("_active_tx", tx)
+	scope.Set("_active_tx_db", "default")
+
+	node := &engine.Node{
+		Name:  "db.create_table",
+		Value: "tx_products",
+		Children: []*engine.Node{
+			{Name: "db.id", Value: "id"},
+		},
+	}
+	err = eng.Execute(context.Background(), node, scope)
+	assert.NoError(t, err)
+
+	// A failed migration rolls back its DDL
+	assert.NoError(t, tx.Rollback())
+
+	var count int
+	dbMgr.GetConnection("default").QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE name = 'tx_products'").Scan(&count)
+	assert.Equal(t, 0, count)
+}
+
+func TestSchemaBuildSQLSQLServer(t *testing.T) {
+	state := &SchemaState{
+		Table:   "posts",
+		Dialect: dbmanager.SQLServerDialect{},
+		Columns: []ColumnDef{
+			{Name: "id", Type: "id"},
+			{Name: "title", Type: "string", Limit: 100, Nullable: false},
+			{Name: "body", Type: "text", Nullable: true},
+			{Name: "published", Type: "boolean", Nullable: true},
+		},
+	}
+
+	sql, err := state.BuildSQL()
+	assert.NoError(t, err)
+	assert.Contains(t, sql, "[id] INT IDENTITY(1,1) PRIMARY KEY")
+	assert.Contains(t, sql, "[title] NVARCHAR(100) NOT NULL")
+	assert.Contains(t, sql, "[body] NVARCHAR(MAX)")
+	assert.Contains(t, sql, "[published] BIT")
+}
+
+func TestSchemaAlterSQL(t *testing.T) {
+	col := ColumnDef{Name: "bio", Type: "text", Nullable: false}
+
+	tests := []struct {
+		dialect dbmanager.Dialect
+		op      AlterOp
+		want    string
+	}{
+		{dbmanager.MySQLDialect{}, AlterOp{Kind: OpAddColumn, Column: ColumnDef{Name: "phone", Type: "string", Nullable: true}}, "ALTER TABLE `users` ADD COLUMN `phone` VARCHAR(255);"},
+		{dbmanager.SQLServerDialect{}, AlterOp{Kind: OpAddColumn, Column: ColumnDef{Name: "phone", Type: "string", Nullable: true}}, "ALTER TABLE [users] ADD [phone] NVARCHAR(255);"},
+		{dbmanager.MySQLDialect{}, AlterOp{Kind: OpModifyColumn, Column: col}, "ALTER TABLE `users` MODIFY COLUMN `bio` TEXT NOT NULL;"},
+		{dbmanager.PostgreSQLDialect{}, AlterOp{Kind: OpModifyColumn, Column: col}, `ALTER TABLE "users" ALTER COLUMN "bio" TYPE TEXT USING "bio"::TEXT, ALTER COLUMN "bio" SET NOT NULL, ALTER COLUMN "bio" DROP DEFAULT;`},
+		{dbmanager.PostgreSQLDialect{}, AlterOp{Kind: OpRenameColumn, Column: ColumnDef{Name: "name"}, To: "full_name"}, `ALTER TABLE "users" RENAME COLUMN "name" TO "full_name";`},
+		{dbmanager.SQLServerDialect{}, AlterOp{Kind: OpRenameColumn, Column: ColumnDef{Name: "name"}, To: "full_name"}, "EXEC sp_rename 'users.name', 'full_name', 'COLUMN';"},
+		{dbmanager.MySQLDialect{}, AlterOp{Kind: OpDropIndex, Name: "users_email_index"}, "DROP INDEX `users_email_index` ON `users`;"},
+		{dbmanager.PostgreSQLDialect{}, AlterOp{Kind: OpDropIndex, Name: "users_email_index"}, `DROP INDEX "users_email_index";`},
+		{dbmanager.SQLiteDialect{}, AlterOp{Kind: OpAddUnique, Name: "users_email_unique", Columns: []string{"email"}}, `CREATE UNIQUE INDEX "users_email_unique" ON "users" ("email");`},
+		{dbmanager.PostgreSQLDialect{}, AlterOp{Kind: OpAddUnique, Name: "users_email_unique", Columns: []string{"email"}}, `ALTER TABLE "users" ADD CONSTRAINT "users_email_unique" UNIQUE ("email");`},
+		{dbmanager.MySQLDialect{}, AlterOp{Kind: OpDropForeign, Name: "users_team_id_foreign"}, "ALTER TABLE `users` DROP FOREIGN KEY `users_team_id_foreign`;"},
+		{dbmanager.SQLServerDialect{}, AlterOp{Kind: OpAddForeign, Foreign: ForeignKeyDef{Name: "users_team_id_foreign", Column: "team_id", References: "teams", On: "id", OnDelete: "CASCADE"}},
+			"ALTER TABLE [users] ADD CONSTRAINT [users_team_id_foreign] FOREIGN KEY ([team_id]) REFERENCES [teams]([id]) ON DELETE CASCADE;"},
+	}
+
+	for _, tt := range tests {
+		state := &SchemaState{Table: "users", Dialect: tt.dialect, Alter: true}
+		stmts, err := state.AlterSQL(tt.op)
+		if assert.NoError(t, err, tt.want) && assert.NotEmpty(t, stmts) {
+			assert.Equal(t, tt.want, stmts[0])
+		}
+	}
+
+	// change: true dengan default dan unique
+	changed := ColumnDef{Name: "status", Type: "string", Limit: 20, Default: "'draft'", Unique: true}
+	modifyTests := []struct {
+		dialect dbmanager.Dialect
+		want    []string
+	}{
+		{dbmanager.MySQLDialect{}, []string{
+			"ALTER TABLE `users` MODIFY COLUMN `status` VARCHAR(20) DEFAULT 'draft' NOT NULL;",
+			"ALTER TABLE `users` ADD CONSTRAINT `users_status_unique` UNIQUE (`status`);",
+		}},
+		{dbmanager.PostgreSQLDialect{}, []string{
+			`ALTER TABLE "users" ALTER COLUMN "status" TYPE VARCHAR(20) USING "status"::VARCHAR(20), ALTER COLUMN "status" SET NOT NULL, ALTER COLUMN "status" SET DEFAULT 'draft';`,
+			`ALTER TABLE "users" ADD CONSTRAINT "users_status_unique" UNIQUE ("status");`,
+		}},
+		{dbmanager.SQLServerDialect{}, []string{
+			`DECLARE @df NVARCHAR(256) = (SELECT d.name FROM sys.default_constraints d
+	JOIN sys.columns c ON c.object_id = d.parent_object_id AND c.column_id = d.parent_column_id
+	WHERE d.parent_object_id = OBJECT_ID(N'users') AND c.name = N'status');
+IF @df IS NOT NULL EXEC(N'ALTER TABLE [users] DROP CONSTRAINT ' + QUOTENAME(@df));`,
+			"ALTER TABLE [users] ALTER COLUMN [status] NVARCHAR(20) NOT NULL;",
+			"ALTER TABLE [users] ADD CONSTRAINT [users_status_default] DEFAULT 'draft' FOR [status];",
+			"ALTER TABLE [users] ADD CONSTRAINT [users_status_unique] UNIQUE ([status]);",
+		}},
+	}
+	for _, tt := range modifyTests {
+		state := &SchemaState{Table: "users", Dialect: tt.dialect, Alter: true}
+		stmts, err := state.AlterSQL(AlterOp{Kind: OpModifyColumn, Column: changed})
+		if assert.NoError(t, err, tt.dialect.Name()) {
+			assert.Equal(t, tt.want, stmts, tt.dialect.Name())
+		}
+	}
+
+	// Tanpa default, SQL Server hanya menghapus default yang lama
+	state := &SchemaState{Table: "users", Dialect: dbmanager.SQLServerDialect{}, Alter: true}
+	stmts, err := state.AlterSQL(AlterOp{Kind: OpModifyColumn, Column: col})
+	if assert.NoError(t, err) && assert.Len(t, stmts, 2) {
+		assert.Equal(t, "ALTER TABLE [users] ALTER COLUMN [bio] NVARCHAR(MAX) NOT NULL;", stmts[1])
+	}
+
+	assert.True(t, sqliteNeedsRebuild(AlterOp{Kind: OpDropColumn}))
+	assert.True(t, sqliteNeedsRebuild(AlterOp{Kind: OpAddColumn, Column: ColumnDef{Type: "string", Nullable: false}}))
+	assert.False(t, sqliteNeedsRebuild(AlterOp{Kind: OpRenameColumn}))
+}
+
+func TestSchemaSQLiteRebuild(t *testing.T) {
+	dbMgr := dbmanager.NewDBManager()
+	err := dbMgr.AddConnection("default", "sqlite", ":memory:", 1, 1)
+	if err != nil {
+		t.Fatalf("Failed to create in-memory db: %v", err)
+	}
+	defer dbMgr.Close()
+	db := dbMgr.GetConnection("default")
+
+	for _, q := range []string{
+		`CREATE TABLE "teams" ("id" INTEGER PRIMARY KEY AUTOINCREMENT)`,
+		`CREATE TABLE "members" ("id" INTEGER PRIMARY KEY AUTOINCREMENT, "email" TEXT NOT NULL UNIQUE, "team_id" INTEGER, "legacy" TEXT, "age" TEXT,
+			FOREIGN KEY ("team_id") REFERENCES "teams"("id") ON DELETE CASCADE)`,
+		`CREATE INDEX "members_legacy_index" ON "members" ("legacy")`,
+		`CREATE INDEX "members_age_index" ON "members" ("age")`,
+		`INSERT INTO "teams" ("id") VALUES (1)`,
+		`INSERT INTO "members" ("email", "team_id", "legacy", "age") VALUES ('a@example.com', 1, 'x', '42')`,
+	} {
+		if _, err := db.Exec(q); err != nil {
+			t.Fatalf("setup failed: %v", err)
+		}
+	}
+
+	state := &SchemaState{Table: "members", Dialect: dbmanager.SQLiteDialect{}, Alter: true}
+	stmts, err := state.SQLiteRebuildSQL(context.Background(), db, []AlterOp{
+		{Kind: OpDropColumn, Column: ColumnDef{Name: "legacy"}},
+		{Kind: OpModifyColumn, Column: ColumnDef{Name: "age", Type: "integer", Nullable: true}},
+		{Kind: OpDropForeign, Foreign: ForeignKeyDef{Column: "team_id"}},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	for _, q := range stmts {
+		if _, err := db.Exec(q); err != nil {
+			t.Fatalf("rebuild statement failed: %v\n%s", err, q)
+		}
+	}
+
+	// Data, unique constraint and the unaffected index survive
+	var email string
+	var age int
+	assert.NoError(t, db.QueryRow(`SELECT "email", "age" FROM "members"`).Scan(&email, &age))
+	assert.Equal(t, "a@example.com", email)
+	assert.Equal(t, 42, age)
+
+	_, err = db.Exec(`INSERT INTO "members" ("email") VALUES ('a@example.com')`)
+	assert.Error(t, err, "unique constraint on email must be kept")
+
+	var count int
+	db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'index' AND name = 'members_age_index'`).Scan(&count)
+	assert.Equal(t, 1, count)
+	db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'index' AND name = 'members_legacy_index'`).Scan(&count)
+	assert.Equal(t, 0, count)
+	db.QueryRow(`SELECT COUNT(*) FROM pragma_foreign_key_list('members')`).Scan(&count)
+	assert.Equal(t, 0, count)
+	db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('members') WHERE name = 'legacy'`).Scan(&count)
+	assert.Equal(t, 0, count)
+}
+
+func TestSchemaDescribeSQLite(t *testing.T) {
+	dbMgr := dbmanager.NewDBManager()
+	err := dbMgr.AddConnection("default", "sqlite", ":memory:", 1, 1)
+	if err != nil {
+		t.Fatalf("Failed to create in-memory db: %v", err)
+	}
+	defer dbMgr.Close()
+	db := dbMgr.GetConnection("default")
+
+	for _, q := range []string{
+		`CREATE TABLE "teams" ("id" INTEGER PRIMARY KEY AUTOINCREMENT)`,
+		`CREATE TABLE "members" ("id" INTEGER PRIMARY KEY AUTOINCREMENT, "email" VARCHAR(100) NOT NULL UNIQUE, "team_id" INTEGER,
+			"score" DECIMAL(8,2) DEFAULT 0, "active" BOOLEAN DEFAULT 1,
+			CONSTRAINT "members_team_id_foreign" FOREIGN KEY ("team_id") REFERENCES "teams"("id") ON DELETE CASCADE)`,
+		`CREATE INDEX "members_score_index" ON "members" ("score")`,
+		`CREATE TABLE "badges" ("code" TEXT PRIMARY KEY)`,
+		`CREATE TABLE "awards" ("badge" TEXT REFERENCES "badges")`,
+	} {
+		if _, err := db.Exec(q); err != nil {
+			t.Fatalf("setup failed: %v", err)
+		}
+	}
+
+	tables, err := dbmanager.ListTables(context.Background(), db, dbmanager.SQLiteDialect{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"awards", "badges", "members", "teams"}, tables)
+
+	ts, err := dbmanager.DescribeTable(context.Background(), db, dbmanager.SQLiteDialect{}, "members")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	builderTypes := map[string]string{}
+	for _, c := range ts.Columns {
+		colType, _, _, _ := c.BuilderType()
+		builderTypes[c.Name] = colType
+	}
+	assert.Equal(t, map[string]string{
+		"id": "id", "email": "string", "team_id": "integer", "score": "decimal", "active": "boolean",
+	}, builderTypes)
+
+	email, _ := ts.Column("email")
+	assert.False(t, email.Nullable)
+	_, limit, _, _ := email.BuilderType()
+	assert.Equal(t, 100, limit)
+
+	score, _ := ts.Column("score")
+	if assert.NotNil(t, score.Default) {
+		assert.Equal(t, "0", *score.Default)
+	}
+
+	indexes := map[string]bool{}
+	for _, idx := range ts.Indexes {
+		indexes[idx.Name] = idx.Unique
+	}
+	assert.Equal(t, true, indexes["members_email_unique"])
+	assert.Equal(t, false, indexes["members_score_index"])
+
+	if assert.Len(t, ts.ForeignKeys, 1) {
+		fk := ts.ForeignKeys[0]
+		assert.Equal(t, "members_team_id_foreign", fk.Name)
+		assert.Equal(t, "teams", fk.RefTable)
+		assert.Equal(t, "CASCADE", fk.OnDelete)
+	}
+
+	// Foreign key tanpa kolom tujuan merujuk primary key tabel induk
+	awards, err := dbmanager.DescribeTable(context.Background(), db, dbmanager.SQLiteDialect{}, "awards")
+	if assert.NoError(t, err) && assert.Len(t, awards.ForeignKeys, 1) {
+		assert.Equal(t, []string{"code"}, awards.ForeignKeys[0].RefColumns)
+	}
+
+	_, err = dbmanager.DescribeTable(context.Background(), db, dbmanager.SQLiteDialect{}, "missing")
+	assert.Error(t, err)
+}
@@ -0,0 +1,428 @@
+package slots
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/nextcore/zeno-go/pkg/engine"
+	"github.com/nextcore/zeno-go/pkg/utils/coerce"
+	"github.com/nextcore/zenoengine/pkg/dbmanager"
+)
+
+// ModelSchemas collects the tables declared by orm.model blocks that list their columns:
+//
+//	orm.model: 'users' {
+//	    columns: {
+//	        db.id: 'id'
+//	        db.string: 'email' { unique: true }
+//	    }
+//	}
+//
+// The columns block uses the Schema Builder slots, so eng must have the schema slots registered.
+func ModelSchemas(ctx context.Context, eng *engine.Engine, root *engine.Node, dialect dbmanager.Dialect) ([]*SchemaState, error) {
+	var models []*SchemaState
+	scope := engine.NewScope(nil)
+
+	var walk func(n *engine.Node) error
+	walk = func(n *engine.Node) error {
+		if n.Name == "orm.model" {
+			state := &SchemaState{
+				Table:   coerce.ToString(resolveValue(n.Value, scope)),
+				DBName:  "default",
+				Dialect: dialect,
+			}
+			var columns *engine.Node
+			for _, c := range n.Children {
+				if c.Name == "db" || c.Name == "connection" {
+					state.DBName = coerce.ToString(parseNodeValue(c, scope))
+				}
+				if c.Name == "columns" {
+					columns = c
+				}
+			}
+			if columns == nil {
+				return nil // Model tanpa definisi kolom tidak ikut di-diff
+			}
+			if err := executeSchemaBlock(ctx, eng, columns, scope, state); err != nil {
+				return fmt.Errorf("model '%s': %w", state.Table, err)
+			}
+			models = append(models, state)
+			return nil
+		}
+		for _, c := range n.Children {
+			if err := walk(c); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := walk(root); err != nil {
+		return nil, err
+	}
+	return models, nil
+}
+
+// SchemaDiff holds the ZenoLang statements that bring a table in line with its model
+type SchemaDiff struct {
+	Table string
+	Up    []string
+	Down  []string
+
+	// Kept lists live columns missing from the model. They are only dropped when
+	// Diff is asked to, because a renamed or forgotten column would lose its data.
+	Kept []string
+}
+
+// Empty reports whether the table already matches the model
+func (d SchemaDiff) Empty() bool {
+	return len(d.Up) == 0
+}
+
+// schemaIndex is an index or unique constraint compared by its columns
+type schemaIndex struct {
+	Name    string
+	Columns []string
+	Unique  bool
+}
+
+func (i schemaIndex) key() string {
+	return fmt.Sprintf("%t:%s", i.Unique, strings.Join(i.Columns, ","))
+}
+
+func (i schemaIndex) zl() string {
+	slot := "db.index"
+	if i.Unique {
+		slot = "db.unique"
+	}
+	return fmt.Sprintf("%s: %s { name: %s }", slot, zlString(strings.Join(i.Columns, ", ")), zlString(i.Name))
+}
+
+func (i schemaIndex) dropZL() string {
+	if i.Unique {
+		return "db.drop_unique: " + zlString(i.Name)
+	}
+	return "db.drop_index: " + zlString(i.Name)
+}
+
+func zlString(s string) string {
+	if strings.Contains(s, "'") {
+		return `"` + s + `"`
+	}
+	return "'" + s + "'"
+}
+
+// columnZL renders a column as a Schema Builder slot call
+func columnZL(col ColumnDef, extra ...string) string {
+	var props []string
+	if col.Type != "id" {
+		if col.Type == "string" && col.Limit > 0 && col.Limit != 255 {
+			props = append(props, fmt.Sprintf("limit: %d", col.Limit))
+		}
+		if col.Type == "decimal" && col.Precision > 0 {
+			props = append(props, fmt.Sprintf("precision: %d", col.Precision), fmt.Sprintf("scale: %d", col.Scale))
+		}
+		if !col.Nullable {
+			props = append(props, "nullable: false")
+		}
+		if col.Default != "" {
+			props = append(props, "default_raw: "+zlString(col.Default))
+		}
+		if col.Unique {
+			props = append(props, "unique: true")
+		}
+	}
+	props = append(props, extra...)
+
+	call := fmt.Sprintf("db.%s: %s", col.Type, zlString(col.Name))
+	if len(props) > 0 {
+		call += " { " + strings.Join(props, ", ") + " }"
+	}
+	return call
+}
+
+func foreignZL(fk ForeignKeyDef) string {
+	onDelete := fk.OnDelete
+	if onDelete == "" {
+		onDelete = "NO ACTION"
+	}
+	return fmt.Sprintf("db.foreign: %s { name: %s, references: %s, on: %s, on_delete: %s }",
+		zlString(fk.Column), zlString(fk.Name), zlString(fk.References), zlString(fk.On), zlString(onDelete))
+}
+
+func dropForeignZL(fk ForeignKeyDef) string {
+	return fmt.Sprintf("db.drop_foreign: %s { name: %s }", zlString(fk.Column), zlString(fk.Name))
+}
+
+// columnFromInfo converts an introspected column into the Schema Builder definition closest to it
+func columnFromInfo(c dbmanager.ColumnInfo) ColumnDef {
+	colType, limit, precision, scale := c.BuilderType()
+	col := ColumnDef{
+		Name:      c.Name,
+		Type:      colType,
+		Limit:     limit,
+		Nullable:  c.Nullable,
+		Precision: precision,
+		Scale:     scale,
+	}
+	if c.Default != nil && !c.AutoIncrement {
+		col.Default = *c.Default
+	}
+	return col
+}
+
+// canonicalType maps a model column to the Schema Builder type the database will report back,
+// e.g. db.json is stored as TEXT on SQLite and read back as "text".
+func (s *SchemaState) canonicalType(col ColumnDef) (string, int, int, int) {
+	if col.Type == "id" {
+		return "id", 0, 0, 0
+	}
+	return dbmanager.ColumnInfo{Type: s.translateType(col)}.BuilderType()
+}
+
+// normalizeDefault strips the decorations catalogs add to default expressions ("((0))", "'x'::text")
+func normalizeDefault(expr string) string {
+	expr = strings.TrimSpace(expr)
+	for strings.HasPrefix(expr, "(") && strings.HasSuffix(expr, ")") {
+		expr = strings.TrimSpace(expr[1 : len(expr)-1])
+	}
+	if i := strings.Index(expr, "::"); i >= 0 {
+		expr = expr[:i]
+	}
+	return strings.ToLower(expr)
+}
+
+func (s *SchemaState) columnChanged(model ColumnDef, live dbmanager.ColumnInfo) bool {
+	mt, ml, mp, ms := s.canonicalType(model)
+	lt, ll, lp, ls := live.BuilderType()
+	if mt != lt || ml != ll || mp != lp || ms != ls {
+		return true
+	}
+	if model.Type == "id" {
+		return false
+	}
+	if model.Nullable != live.Nullable {
+		return true
+	}
+	liveDefault := ""
+	if live.Default != nil {
+		liveDefault = *live.Default
+	}
+	return normalizeDefault(model.Default) != normalizeDefault(liveDefault)
+}
+
+// modelIndexes lists the indexes declared by the model, including inline unique columns
+func (s *SchemaState) modelIndexes() []schemaIndex {
+	var list []schemaIndex
+	for _, col := range s.Columns {
+		if col.Unique {
+			list = append(list, schemaIndex{Name: indexName(s.Table, []string{col.Name}, "unique"), Columns: []string{col.Name}, Unique: true})
+		}
+	}
+	for _, op := range s.Ops {
+		if op.Kind == OpAddIndex || op.Kind == OpAddUnique {
+			list = append(list, schemaIndex{Name: op.Name, Columns: op.Columns, Unique: op.Kind == OpAddUnique})
+		}
+	}
+	return list
+}
+
+// liveIndexes lists the indexes of an existing table, without the primary key and the
+// indexes MySQL creates implicitly for foreign keys
+func liveIndexes(ts *dbmanager.TableSchema) []schemaIndex {
+	var list []schemaIndex
+	for _, idx := range ts.Indexes {
+		if idx.Primary {
+			continue
+		}
+		implicit := false
+		for _, fk := range ts.ForeignKeys {
+			if fk.Name == idx.Name && strings.Join(fk.Columns, ",") == strings.Join(idx.Columns, ",") {
+				implicit = true
+			}
+		}
+		if !implicit {
+			list = append(list, schemaIndex{Name: idx.Name, Columns: idx.Columns, Unique: idx.Unique})
+		}
+	}
+	return list
+}
+
+func (s *SchemaState) foreignKeyName(fk ForeignKeyDef) ForeignKeyDef {
+	if fk.Name == "" {
+		fk.Name = indexName(s.Table, []string{fk.Column}, "foreign")
+	}
+	return fk
+}
+
+// Diff compares the model with the live table (nil when the table does not exist yet)
+// and returns the statements for the up and down sections of a migration. Live columns
+// missing from the model are dropped only with dropColumns, otherwise they end up in Kept.
+func (s *SchemaState) Diff(live *dbmanager.TableSchema, dropColumns bool) SchemaDiff {
+	diff := SchemaDiff{Table: s.Table}
+
+	if live == nil {
+		lines := []string{fmt.Sprintf("db.create_table: %s {", zlString(s.Table))}
+		for _, col := range s.Columns {
+			lines = append(lines, "    "+columnZL(col))
+		}
+		for _, op := range s.Ops {
+			if op.Kind == OpAddIndex || op.Kind == OpAddUnique {
+				lines = append(lines, "    "+schemaIndex{Name: op.Name, Columns: op.Columns, Unique: op.Kind == OpAddUnique}.zl())
+			}
+		}
+		for _, fk := range s.ForeignKeys {
+			lines = append(lines, "    "+foreignZL(s.foreignKeyName(fk)))
+		}
+		lines = append(lines, "}")
+		diff.Up = lines
+		diff.Down = []string{"db.drop_table: " + zlString(s.Table)}
+		return diff
+	}
+
+	// Urutan up: lepas FK & index lama, ubah kolom, lalu pasang index & FK baru.
+	// Down berisi kebalikan setiap langkah dalam urutan terbalik.
+	var up, down []string
+	step := func(u, d string) {
+		up = append(up, u)
+		down = append([]string{d}, down...)
+	}
+
+	// 1. Foreign keys yang tidak lagi dideklarasikan
+	modelFKs := make(map[string]ForeignKeyDef)
+	for _, fk := range s.ForeignKeys {
+		fk = s.foreignKeyName(fk)
+		modelFKs[fk.Column+">"+fk.References] = fk
+	}
+	liveFKs := make(map[string]bool)
+	for _, fk := range live.ForeignKeys {
+		if len(fk.Columns) != 1 {
+			continue // Schema Builder hanya mengenal FK satu kolom
+		}
+		key := fk.Columns[0] + ">" + fk.RefTable
+		liveFKs[key] = true
+		if _, ok := modelFKs[key]; !ok {
+			old := ForeignKeyDef{Name: fk.Name, Column: fk.Columns[0], References: fk.RefTable, On: fk.RefColumns[0], OnDelete: fk.OnDelete}
+			step(dropForeignZL(old), foreignZL(old))
+		}
+	}
+
+	// 2. Index yang tidak lagi dideklarasikan
+	modelIdx := make(map[string]bool)
+	for _, idx := range s.modelIndexes() {
+		modelIdx[idx.key()] = true
+	}
+	liveIdx := make(map[string]bool)
+	for _, idx := range liveIndexes(live) {
+		liveIdx[idx.key()] = true
+		if !modelIdx[idx.key()] {
+			step(idx.dropZL(), idx.zl())
+		}
+	}
+
+	// 3. Kolom baru & kolom yang berubah (unique ditangani sebagai index)
+	declared := make(map[string]bool)
+	for _, col := range s.Columns {
+		declared[col.Name] = true
+		col.Unique = false
+
+		current, exists := live.Column(col.Name)
+		if !exists {
+			step(columnZL(col), "db.drop_column: "+zlString(col.Name))
+			continue
+		}
+		if s.columnChanged(col, current) {
+			step(columnZL(col, "change: true"), columnZL(columnFromInfo(current), "change: true"))
+		}
+	}
+
+	// 4. Kolom yang tidak ada di model (hanya di-drop bila diminta)
+	for _, c := range live.Columns {
+		if declared[c.Name] {
+			continue
+		}
+		if dropColumns {
+			step("db.drop_column: "+zlString(c.Name), columnZL(columnFromInfo(c)))
+		} else {
+			diff.Kept = append(diff.Kept, c.Name)
+		}
+	}
+
+	// 5. Index & foreign key baru
+	for _, idx := range s.modelIndexes() {
+		if !liveIdx[idx.key()] {
+			step(idx.zl(), idx.dropZL())
+		}
+	}
+	for _, fk := range s.ForeignKeys {
+		fk = s.foreignKeyName(fk)
+		if !liveFKs[fk.Column+">"+fk.References] {
+			step(foreignZL(fk), dropForeignZL(fk))
+		}
+	}
+
+	if len(up) == 0 {
+		return diff
+	}
+
+	indent := func(lines []string) []string {
+		block := []string{fmt.Sprintf("db.alter_table: %s {", zlString(s.Table))}
+		for _, l := range lines {
+			block = append(block, "    "+l)
+		}
+		return append(block, "}")
+	}
+	diff.Up = indent(up)
+	diff.Down = indent(down)
+	return diff
+}
+
+// RenderMigration writes the diffs as a migration file with up and down blocks.
+// Down blocks are emitted in reverse order so dependent tables are removed first.
+func RenderMigration(diffs []SchemaDiff) string {
+	var sb strings.Builder
+	sb.WriteString("// Generated by 'zeno make:migration --auto'\n")
+	sb.WriteString("up {\n")
+	for _, d := range diffs {
+		for _, l := range d.Up {
+			sb.WriteString("    " + l + "\n")
+		}
+	}
+	sb.WriteString("}\n\ndown {\n")
+	for i := len(diffs) - 1; i >= 0; i-- {
+		for _, l := range diffs[i].Down {
+			sb.WriteString("    " + l + "\n")
+		}
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// SortByForeignKeys orders models so that referenced tables come before the tables pointing at them
+func SortByForeignKeys(models []*SchemaState) []*SchemaState {
+	byTable := make(map[string]*SchemaState, len(models))
+	for _, m := range models {
+		byTable[m.Table] = m
+	}
+
+	visited := make(map[string]bool, len(models))
+	sorted := make([]*SchemaState, 0, len(models))
+	var visit func(m *SchemaState)
+	visit = func(m *SchemaState) {
+		if visited[m.Table] {
+			return
+		}
+		visited[m.Table] = true
+		for _, fk := range m.ForeignKeys {
+			if ref, ok := byTable[fk.References]; ok && ref != m {
+				visit(ref)
+			}
+		}
+		sorted = append(sorted, m)
+	}
+	for _, m := range models {
+		visit(m)
+	}
+	return sorted
+}
@@ -0,0 +1,107 @@
+package slots
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/nextcore/zenoengine/pkg/dbmanager"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchemaDiffNewTable(t *testing.T) {
+	model := &SchemaState{
+		Table:   "posts",
+		Dialect: dbmanager.SQLiteDialect{},
+		Columns: []ColumnDef{
+			{Name: "id", Type: "id"},
+			{Name: "title", Type: "string", Limit: 100, Nullable: true},
+			{Name: "user_id", Type: "integer", Nullable: true},
+		},
+		ForeignKeys: []ForeignKeyDef{{Column: "user_id", References: "users", On: "id", OnDelete: "CASCADE"}},
+	}
+
+	diff := model.Diff(nil, false)
+	assert.Equal(t, []string{
+		"db.create_table: 'posts' {",
+		"    db.id: 'id'",
+		"    db.string: 'title' { limit: 100 }",
+		"    db.integer: 'user_id'",
+		"    db.foreign: 'user_id' { name: 'posts_user_id_foreign', references: 'users', on: 'id', on_delete: 'CASCADE' }",
+		"}",
+	}, diff.Up)
+	assert.Equal(t, []string{"db.drop_table: 'posts'"}, diff.Down)
+}
+
+func TestSchemaDiffExistingTable(t *testing.T) {
+	dbMgr := dbmanager.NewDBManager()
+	err := dbMgr.AddConnection("default", "sqlite", ":memory:", 1, 1)
+	if err != nil {
+		t.Fatalf("Failed to create in-memory db: %v", err)
+	}
+	defer dbMgr.Close()
+	db := dbMgr.GetConnection("default")
+
+	_, err = db.Exec(`CREATE TABLE "users" ("id" INTEGER PRIMARY KEY AUTOINCREMENT, "name" TEXT NOT NULL, "bio" TEXT, "legacy" TEXT, "settings" TEXT)`)
+	if err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+	live, err := dbmanager.DescribeTable(context.Background(), db, dbmanager.SQLiteDialect{}, "users")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	model := &SchemaState{
+		Table:   "users",
+		Dialect: dbmanager.SQLiteDialect{},
+		Columns: []ColumnDef{
+			{Name: "id", Type: "id"},
+			{Name: "name", Type: "string", Nullable: false},
+			{Name: "bio", Type: "text", Nullable: false},
+			{Name: "settings", Type: "json", Nullable: true}, // JSON is TEXT on SQLite: unchanged
+			{Name: "email", Type: "string", Nullable: true, Unique: true},
+		},
+	}
+
+	// Columns missing from the model are kept unless drops are requested
+	diff := model.Diff(live, false)
+	assert.Equal(t, []string{
+		"db.alter_table: 'users' {",
+		"    db.text: 'bio' { nullable: false, change: true }",
+		"    db.string: 'email'",
+		"    db.unique: 'email' { name: 'users_email_unique' }",
+		"}",
+	}, diff.Up)
+	assert.Equal(t, []string{
+		"db.alter_table: 'users' {",
+		"    db.drop_unique: 'users_email_unique'",
+		"    db.drop_column: 'email'",
+		"    db.text: 'bio' { change: true }",
+		"}",
+	}, diff.Down)
+	assert.Equal(t, []string{"legacy"}, diff.Kept)
+
+	diff = model.Diff(live, true)
+	assert.Equal(t, []string{
+		"db.alter_table: 'users' {",
+		"    db.text: 'bio' { nullable: false, change: true }",
+		"    db.string: 'email'",
+		"    db.drop_column: 'legacy'",
+		"    db.unique: 'email' { name: 'users_email_unique' }",
+		"}",
+	}, diff.Up)
+	assert.Equal(t, []string{
+		"db.alter_table: 'users' {",
+		"    db.drop_unique: 'users_email_unique'",
+		"    db.text: 'legacy'",
+		"    db.drop_column: 'email'",
+		"    db.text: 'bio' { change: true }",
+		"}",
+	}, diff.Down)
+	assert.Empty(t, diff.Kept)
+
+	// Up and down are rendered in opposite table order
+	out := RenderMigration([]SchemaDiff{{Table: "a", Up: []string{"A"}, Down: []string{"-A"}}, {Table: "b", Up: []string{"B"}, Down: []string{"-B"}}})
+	assert.True(t, strings.Index(out, "-B") < strings.Index(out, "-A"))
+	assert.True(t, strings.Index(out, "    A") < strings.Index(out, "    B"))
+}
@@ -0,0 +1,153 @@
+package slots
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/nextcore/zeno-go/pkg/engine"
+	"github.com/nextcore/zeno-go/pkg/utils/coerce"
+	"github.com/nextcore/zenoengine/pkg/dbmanager"
+)
+
+// tableSchemaToMap converts a described table into plain maps/slices usable from ZenoLang
+func tableSchemaToMap(ts *dbmanager.TableSchema) map[string]interface{} {
+	columns := make([]interface{}, 0, len(ts.Columns))
+	for _, c := range ts.Columns {
+		var dflt interface{}
+		if c.Default != nil {
+			dflt = *c.Default
+		}
+		colType, _, _, _ := c.BuilderType()
+		columns = append(columns, map[string]interface{}{
+			"name":           c.Name,
+			"type":           c.Type,
+			"builder_type":   colType,
+			"nullable":       c.Nullable,
+			"default":        dflt,
+			"primary_key":    c.PrimaryKey,
+			"auto_increment": c.AutoIncrement,
+		})
+	}
+
+	indexes := make([]interface{}, 0, len(ts.Indexes))
+	for _, idx := range ts.Indexes {
+		indexes = append(indexes, map[string]interface{}{
+			"name":    idx.Name,
+			"columns": stringsToInterfaces(idx.Columns),
+			"unique":  idx.Unique,
+			"primary": idx.Primary,
+		})
+	}
+
+	foreignKeys := make([]interface{}, 0, len(ts.ForeignKeys))
+	for _, fk := range ts.ForeignKeys {
+		foreignKeys = append(foreignKeys, map[string]interface{}{
+			"name":       fk.Name,
+			"columns":    stringsToInterfaces(fk.Columns),
+			"references": fk.RefTable,
+			"on":         stringsToInterfaces(fk.RefColumns),
+			"on_delete":  fk.OnDelete,
+			"on_update":  fk.OnUpdate,
+		})
+	}
+
+	return map[string]interface{}{
+		"name":         ts.Name,
+		"columns":      columns,
+		"indexes":      indexes,
+		"foreign_keys": foreignKeys,
+	}
+}
+
+func stringsToInterfaces(list []string) []interface{} {
+	res := make([]interface{}, len(list))
+	for i, v := range list {
+		res[i] = v
+	}
+	return res
+}
+
+func registerIntrospectionSlots(eng *engine.Engine, dbMgr *dbmanager.DBManager) {
+	// DB.TABLES
+	eng.Register("db.tables", func(ctx context.Context, node *engine.Node, scope *engine.Scope) error {
+		dbName := "default"
+		target := "tables"
+
+		if node.Value != nil {
+			dbName = coerce.ToString(resolveValue(node.Value, scope))
+		}
+		for _, c := range node.Children {
+			if c.Name == "db" || c.Name == "connection" {
+				dbName = coerce.ToString(parseNodeValue(c, scope))
+			}
+			if c.Name == "as" {
+				target = strings.TrimPrefix(coerce.ToString(c.Value), "$")
+			}
+		}
+
+		db := dbMgr.GetConnection(dbName)
+		if db == nil {
+			return fmt.Errorf("db.tables: database connection '%s' not found", dbName)
+		}
+
+		tables, err := dbmanager.ListTables(ctx, db, dbMgr.GetDialect(dbName))
+		if err != nil {
+			return fmt.Errorf("db.tables: %w", err)
+		}
+
+		scope.Set(target, stringsToInterfaces(tables))
+		return nil
+	}, engine.SlotMeta{
+		Description: "List the tables of a database connection.",
+		Example:     "db.tables\n  as: $tables",
+		ValueType:   "string",
+		Inputs: map[string]engine.InputMeta{
+			"(value)": {Description: "Database connection name (Default: 'default')", Required: false, Type: "string"},
+			"db":      {Description: "Database connection name", Required: false, Type: "string"},
+			"as":      {Description: "Variable to store the table names (Default: $tables)", Required: false, Type: "string"},
+		},
+	})
+
+	// DB.DESCRIBE
+	eng.Register("db.describe", func(ctx context.Context, node *engine.Node, scope *engine.Scope) error {
+		table := coerce.ToString(resolveValue(node.Value, scope))
+		dbName := "default"
+		target := "table"
+
+		for _, c := range node.Children {
+			if c.Name == "db" || c.Name == "connection" {
+				dbName = coerce.ToString(parseNodeValue(c, scope))
+			}
+			if c.Name == "as" {
+				target = strings.TrimPrefix(coerce.ToString(c.Value), "$")
+			}
+		}
+
+		if table == "" {
+			return fmt.Errorf("db.describe: table name is required")
+		}
+
+		db := dbMgr.GetConnection(dbName)
+		if db == nil {
+			return fmt.Errorf("db.describe: database connection '%s' not found", dbName)
+		}
+
+		ts, err := dbmanager.DescribeTable(ctx, db, dbMgr.GetDialect(dbName), table)
+		if err != nil {
+			return fmt.Errorf("db.describe: %w", err)
+		}
+
+		scope.Set(target, tableSchemaToMap(ts))
+		return nil
+	}, engine.SlotMeta{
+		Description: "Read the columns, indexes and foreign keys of a table from the database catalog.",
+		Example:     "db.describe: 'users'\n  as: $schema\n\nfor: $schema.columns {\n  log: $item.name\n}",
+		ValueType:   "string",
+		Inputs: map[string]engine.InputMeta{
+			"(value)": {Description: "The table name", Required: true, Type: "string"},
+			"db":      {Description: "Database connection name (Default: 'default')", Required: false, Type: "string"},
+			"as":      {Description: "Variable to store the table description (Default: $table)", Required: false, Type: "string"},
+		},
+	})
+}
@@ -4,18 +4,33 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"regexp"
 	"sync"
+	"time"
+
 	"github.com/nextcore/zeno-go/pkg/engine"
 	"github.com/nextcore/zeno-go/pkg/utils/coerce"
 )
 
+// TestResult is the outcome of a single test block
+type TestResult struct {
+	Name     string        `json:"name"`
+	File     string        `json:"file,omitempty"`
+	Line     int           `json:"line,omitempty"`
+	Passed   bool          `json:"passed"`
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
 // TestStats tracks the results of the test execution
 type TestStats struct {
-	Total  int
-	Passed int
-	Failed int
-	Errors []string
-	mu     sync.Mutex
+	Total   int
+	Passed  int
+	Failed  int
+	Skipped int
+	Errors  []string
+	Results []TestResult
+	mu      sync.Mutex
 }
 
 func (s *TestStats) AddPass() {
@@ -33,15 +48,42 @@ func (s *TestStats) AddFail(name string, err error) {
 	s.Errors = append(s.Errors, fmt.Sprintf("FAIL [%s]: %v", name, err))
 }
 
+// AddResult records a finished test including its location and duration
+func (s *TestStats) AddResult(r TestResult) {
+	if r.Passed {
+		s.AddPass()
+	} else {
+		s.AddFail(r.Name, fmt.Errorf("%s", r.Error))
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Results = append(s.Results, r)
+}
+
+// AddSkip counts a test that was excluded by the filter
+func (s *TestStats) AddSkip() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Skipped++
+}
+
 type contextKey string
 
-const statsKey contextKey = "testStats"
+const (
+	statsKey  contextKey = "testStats"
+	filterKey contextKey = "testFilter"
+)
 
 // WithTestStats injects the stats into the context
 func WithTestStats(ctx context.Context, stats *TestStats) context.Context {
 	return context.WithValue(ctx, statsKey, stats)
 }
 
+// WithTestFilter makes the test slot skip tests whose name doesn't match the pattern
+func WithTestFilter(ctx context.Context, filter *regexp.Regexp) context.Context {
+	return context.WithValue(ctx, filterKey, filter)
+}
+
 func RegisterTestSlots(eng *engine.Engine) {
 	// SLOT: test
 	eng.Register("test", func(ctx context.Context, node *engine.Node, scope *engine.Scope) error {
@@ -50,28 +92,55 @@ func RegisterTestSlots(eng *engine.Engine) {
 			testName = "Unnamed Test"
 		}
 
+		stats, ok := ctx.Value(statsKey).(*TestStats)
+		if filter, _ := ctx.Value(filterKey).(*regexp.Regexp); filter != nil && !filter.MatchString(testName) {
+			if ok {
+				stats.AddSkip()
+			}
+			return nil
+		}
+
 		fmt.Printf("RUN   %s...\n", testName)
 
+		// Setiap test mulai tanpa identitas login dan dengan database yang di-rollback setelahnya
+		scope.Set(testTokenVar, nil)
+		scope.Set(testTenantVar, nil)
+		rollback, err := beginTestTransaction(ctx)
+		if err != nil {
+			return err
+		}
+
 		// Execute children (assertions)
-		var err error
+		start := time.Now()
+		testCtx := context.WithValue(ctx, testCaseKey, &testCase{Name: testName, File: node.Filename})
 		for _, child := range node.Children {
-			if e := eng.Execute(ctx, child, scope); e != nil {
+			if e := eng.Execute(testCtx, child, scope); e != nil {
 				err = e
 				break // Stop on first failure in a test case? Or continue? Usually stop.
 			}
 		}
+		rollback()
+		scope.Set(testTokenVar, nil)
+		scope.Set(testTenantVar, nil)
 
-		stats, ok := ctx.Value(statsKey).(*TestStats)
 		if ok {
+			result := TestResult{
+				Name:     testName,
+				File:     node.Filename,
+				Line:     node.Line,
+				Passed:   err == nil,
+				Duration: time.Since(start),
+			}
 			if err != nil {
 				fmt.Printf("FAIL  %s\n", testName)
-				stats.AddFail(testName, err)
+				result.Error = err.Error()
+				stats.AddResult(result)
 				// We return nil to allow other independent tests to run.
 				// The error is recorded in stats.
 				return nil
 			} else {
 				fmt.Printf("PASS  %s\n", testName)
-				stats.AddPass()
+				stats.AddResult(result)
 			}
 		}
 
@@ -144,4 +213,7 @@ func RegisterTestSlots(eng *engine.Engine) {
 
 		return eng.Execute(ctx, callNode, scope)
 	}, engine.SlotMeta{Example: "call: math.add { val: 1; as: $res }"})
+
+	registerTestHTTPSlots(eng)
+	registerAssertSlots(eng)
 }
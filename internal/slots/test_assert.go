@@ -0,0 +1,449 @@
+package slots
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/nextcore/zeno-go/pkg/engine"
+	pkgslots "github.com/nextcore/zeno-go/pkg/slots"
+	"github.com/nextcore/zeno-go/pkg/utils/coerce"
+	"github.com/nextcore/zenoengine/pkg/dbmanager"
+)
+
+const (
+	testCaseKey       contextKey = "testCase"
+	snapshotUpdateKey contextKey = "snapshotUpdate"
+)
+
+// SnapshotDir is where assert.snapshot stores its golden files
+var SnapshotDir = filepath.Join("tests", "__snapshots__")
+
+// testCase is the test block currently running, used to name snapshots
+type testCase struct {
+	Name      string
+	File      string
+	snapshots int
+}
+
+// WithSnapshotUpdate makes assert.snapshot overwrite stored snapshots instead of comparing them
+func WithSnapshotUpdate(ctx context.Context, update bool) context.Context {
+	return context.WithValue(ctx, snapshotUpdateKey, update)
+}
+
+// assertFailure returns the custom 'message' of an assertion, or the default failure
+func assertFailure(node *engine.Node, scope *engine.Scope, format string, args ...interface{}) error {
+	for _, c := range node.Children {
+		if c.Name == "message" {
+			return fmt.Errorf("%s", coerce.ToString(parseNodeValue(c, scope)))
+		}
+	}
+	return fmt.Errorf(format, args...)
+}
+
+// assertExpected returns the 'expected' (or 'val') child of an assertion
+func assertExpected(node *engine.Node, scope *engine.Scope, names ...string) (interface{}, bool) {
+	if len(names) == 0 {
+		names = []string{"expected", "val"}
+	}
+	for _, c := range node.Children {
+		for _, n := range names {
+			if c.Name == n {
+				return parseNodeValue(c, scope), true
+			}
+		}
+	}
+	return nil, false
+}
+
+// isTruthy follows the truthy check of the 'if' slot
+func isTruthy(val interface{}) bool {
+	if b, err := coerce.ToBool(val); err == nil {
+		return b
+	}
+	s := coerce.ToString(val)
+	return s != "" && s != "false" && s != "0" && s != "<nil>"
+}
+
+// lengthOf returns the number of items in a list or map, or the characters of a string
+func lengthOf(val interface{}) (int, bool) {
+	if val == nil {
+		return 0, true
+	}
+	rv := reflect.ValueOf(val)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return rv.Len(), true
+	case reflect.String:
+		return len([]rune(rv.String())), true
+	}
+	return 0, false
+}
+
+// containsValue checks a substring, a list item or a map key
+func containsValue(haystack, needle interface{}) bool {
+	switch h := haystack.(type) {
+	case string:
+		return strings.Contains(h, coerce.ToString(needle))
+	case map[string]interface{}:
+		_, ok := h[coerce.ToString(needle)]
+		return ok
+	}
+	rv := reflect.ValueOf(haystack)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			if looseEqual(rv.Index(i).Interface(), needle) {
+				return true
+			}
+		}
+	case reflect.Map:
+		for _, k := range rv.MapKeys() {
+			if looseEqual(k.Interface(), needle) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func registerAssertSlots(eng *engine.Engine) {
+	// SLOT: assert.true / assert.false
+	truthSlot := func(name string, want bool) {
+		eng.Register(name, func(ctx context.Context, node *engine.Node, scope *engine.Scope) error {
+			val := resolveValue(node.Value, scope)
+			if isTruthy(val) != want {
+				return assertFailure(node, scope, "expected %v to be %v", val, want)
+			}
+			return nil
+		}, engine.SlotMeta{Example: name + ": $user.active"})
+	}
+	truthSlot("assert.true", true)
+	truthSlot("assert.false", false)
+
+	// SLOT: assert.contains
+	eng.Register("assert.contains", func(ctx context.Context, node *engine.Node, scope *engine.Scope) error {
+		haystack := resolveValue(node.Value, scope)
+		needle, ok := assertExpected(node, scope, "expected", "val", "key")
+		if !ok {
+			return fmt.Errorf("assert.contains: 'expected' is required")
+		}
+		if !containsValue(haystack, needle) {
+			return assertFailure(node, scope, "expected %v to contain %v", haystack, needle)
+		}
+		return nil
+	}, engine.SlotMeta{
+		Description: "Checks that a string contains a substring, a list contains an item or a map contains a key.",
+		Example:     "assert.contains: $roles { expected: 'admin' }",
+	})
+
+	// SLOT: assert.matches
+	eng.Register("assert.matches", func(ctx context.Context, node *engine.Node, scope *engine.Scope) error {
+		val := coerce.ToString(resolveValue(node.Value, scope))
+		pattern, ok := assertExpected(node, scope, "pattern", "expected")
+		if !ok {
+			return fmt.Errorf("assert.matches: 'pattern' is required")
+		}
+		re, err := regexp.Compile(coerce.ToString(pattern))
+		if err != nil {
+			return fmt.Errorf("assert.matches: invalid pattern: %v", err)
+		}
+		if !re.MatchString(val) {
+			return assertFailure(node, scope, "expected '%s' to match /%s/", val, re)
+		}
+		return nil
+	}, engine.SlotMeta{Example: "assert.matches: $order.code { pattern: '^ORD-\\d+$' }"})
+
+	// SLOT: assert.count
+	eng.Register("assert.count", func(ctx context.Context, node *engine.Node, scope *engine.Scope) error {
+		val := resolveValue(node.Value, scope)
+		expected, ok := assertExpected(node, scope)
+		if !ok {
+			return fmt.Errorf("assert.count: 'expected' is required")
+		}
+		n, ok := lengthOf(val)
+		if !ok {
+			return fmt.Errorf("assert.count: cannot count %T", val)
+		}
+		if coerce.ToString(n) != coerce.ToString(expected) {
+			return assertFailure(node, scope, "expected %v item(s), got %d", expected, n)
+		}
+		return nil
+	}, engine.SlotMeta{Example: "assert.count: $users { expected: 3 }"})
+
+	// SLOT: assert.throws
+	// Usage: assert.throws { message: 'not found'; do: { ... } }
+	eng.Register("assert.throws", func(ctx context.Context, node *engine.Node, scope *engine.Scope) error {
+		var message, pattern string
+		var body []*engine.Node
+		for _, c := range node.Children {
+			switch c.Name {
+			case "message":
+				message = coerce.ToString(parseNodeValue(c, scope))
+			case "matches":
+				pattern = coerce.ToString(parseNodeValue(c, scope))
+			case "do":
+				body = append(body, c.Children...)
+			default:
+				body = append(body, c)
+			}
+		}
+
+		var err error
+		for _, child := range body {
+			if err = eng.Execute(ctx, child, scope); err != nil {
+				break
+			}
+		}
+		// return: menghentikan blok secara normal, bukan error
+		if err == nil || errors.Is(err, pkgslots.ErrReturn) {
+			return fmt.Errorf("expected an error, but the block succeeded")
+		}
+		if message != "" && !strings.Contains(err.Error(), message) {
+			return fmt.Errorf("expected an error containing '%s', got '%v'", message, err)
+		}
+		if pattern != "" {
+			re, reErr := regexp.Compile(pattern)
+			if reErr != nil {
+				return fmt.Errorf("assert.throws: invalid pattern: %v", reErr)
+			}
+			if !re.MatchString(err.Error()) {
+				return fmt.Errorf("expected an error matching /%s/, got '%v'", pattern, err)
+			}
+		}
+		return nil
+	}, engine.SlotMeta{
+		Description: "Passes when the block returns an error, optionally containing 'message' or matching 'matches'.",
+		Example:     "assert.throws {\n  message: 'not found'\n  do: {\n    orm.find: 999\n  }\n}",
+	})
+
+	// SLOT: assert.snapshot
+	eng.Register("assert.snapshot", func(ctx context.Context, node *engine.Node, scope *engine.Scope) error {
+		val := resolveValue(node.Value, scope)
+		name, format := "", ""
+		for _, c := range node.Children {
+			switch c.Name {
+			case "name":
+				name = coerce.ToString(parseNodeValue(c, scope))
+			case "format":
+				format = strings.ToLower(coerce.ToString(parseNodeValue(c, scope)))
+			}
+		}
+
+		tc, _ := ctx.Value(testCaseKey).(*testCase)
+		if name == "" {
+			if tc == nil {
+				return fmt.Errorf("assert.snapshot: 'name' is required outside a test block")
+			}
+			tc.snapshots++
+			name = tc.Name
+			if tc.snapshots > 1 {
+				name = fmt.Sprintf("%s %d", name, tc.snapshots)
+			}
+		}
+
+		content, ext, err := snapshotContent(val, format)
+		if err != nil {
+			return fmt.Errorf("assert.snapshot: %v", err)
+		}
+
+		file := ""
+		if tc != nil {
+			file = tc.File
+		}
+		path := snapshotPath(file, name, ext)
+		update, _ := ctx.Value(snapshotUpdateKey).(bool)
+
+		stored, err := os.ReadFile(path)
+		if err == nil && !update {
+			if string(stored) != content {
+				return fmt.Errorf("snapshot %s does not match: %s (run 'zeno test --update-snapshots' to accept the change)", path, firstDifference(string(stored), content))
+			}
+			return nil
+		}
+
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("assert.snapshot: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			return fmt.Errorf("assert.snapshot: %v", err)
+		}
+		fmt.Printf("📸 Snapshot written: %s\n", path)
+		return nil
+	}, engine.SlotMeta{
+		Description: "Compares a value with the golden file in tests/__snapshots__, writing it on the first run.",
+		Example:     "assert.snapshot: $response\n  name: 'users index'",
+	})
+}
+
+// snapshotContent serializes a value for a snapshot: strings as HTML, anything else as indented JSON.
+// For a response from test.get & co. only the body is stored.
+func snapshotContent(val interface{}, format string) (string, string, error) {
+	if res, ok := val.(map[string]interface{}); ok {
+		if _, isResponse := res["content"]; isResponse {
+			if _, hasStatus := res["status"]; hasStatus {
+				val = res["body"]
+			}
+		}
+	}
+
+	if format == "" {
+		format = "json"
+		if _, ok := val.(string); ok {
+			format = "html"
+		}
+	}
+	switch format {
+	case "html", "txt":
+		s := coerce.ToString(val)
+		if !strings.HasSuffix(s, "\n") {
+			s += "\n"
+		}
+		return s, format, nil
+	case "json":
+		data, err := json.MarshalIndent(val, "", "  ")
+		if err != nil {
+			return "", "", err
+		}
+		return string(data) + "\n", "json", nil
+	}
+	return "", "", fmt.Errorf("unknown format '%s' (use json, html or txt)", format)
+}
+
+var snapshotNameCleaner = regexp.MustCompile(`[^a-z0-9]+`)
+
+// snapshotPath returns tests/__snapshots__/<test file without _test.zl>/<test name>.<ext>
+func snapshotPath(testFile, name, ext string) string {
+	dir := SnapshotDir
+	if testFile != "" {
+		rel := filepath.ToSlash(filepath.Clean(testFile))
+		rel = strings.TrimPrefix(rel, "tests/")
+		rel = strings.TrimSuffix(strings.TrimSuffix(rel, ".zl"), "_test")
+		dir = filepath.Join(dir, filepath.FromSlash(rel))
+	}
+	slug := strings.Trim(snapshotNameCleaner.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if slug == "" {
+		slug = "snapshot"
+	}
+	return filepath.Join(dir, slug+"."+ext)
+}
+
+// firstDifference describes the first line where two snapshots differ
+func firstDifference(expected, actual string) string {
+	exp := strings.Split(expected, "\n")
+	act := strings.Split(actual, "\n")
+	for i := 0; i < len(exp) || i < len(act); i++ {
+		var e, a string
+		if i < len(exp) {
+			e = exp[i]
+		}
+		if i < len(act) {
+			a = act[i]
+		}
+		if e != a {
+			return fmt.Sprintf("line %d: expected %q, got %q", i+1, e, a)
+		}
+	}
+	return "content differs"
+}
+
+// RegisterTestDBSlots registers the database assertions (assert.db_has, assert.db_missing)
+func RegisterTestDBSlots(eng *engine.Engine, dbMgr *dbmanager.DBManager) {
+	dbSlot := func(name string, want bool) {
+		eng.Register(name, func(ctx context.Context, node *engine.Node, scope *engine.Scope) error {
+			table := coerce.ToString(resolveValue(node.Value, scope))
+			dbName := "default"
+			var where map[string]interface{}
+			var expectedCount interface{}
+
+			for _, c := range node.Children {
+				val := parseNodeValue(c, scope)
+				switch c.Name {
+				case "table":
+					table = coerce.ToString(val)
+				case "db":
+					dbName = coerce.ToString(val)
+				case "where":
+					if m, ok := val.(map[string]interface{}); ok {
+						where = m
+					}
+				case "count":
+					expectedCount = val
+				}
+			}
+			if table == "" {
+				return fmt.Errorf("%s: table is required", name)
+			}
+
+			db := dbMgr.GetConnection(dbName)
+			if db == nil {
+				return fmt.Errorf("%s: database '%s' not found", name, dbName)
+			}
+			dialect := dbMgr.GetDialect(dbName)
+
+			count, err := countRows(ctx, db, dialect, table, where)
+			if err != nil {
+				return fmt.Errorf("%s: %v", name, err)
+			}
+
+			switch {
+			case want && expectedCount != nil:
+				if coerce.ToString(count) != coerce.ToString(expectedCount) {
+					return assertFailure(node, scope, "expected %v row(s) in '%s' matching %v, found %d", expectedCount, table, where, count)
+				}
+			case want && count == 0:
+				return assertFailure(node, scope, "expected a row in '%s' matching %v", table, where)
+			case !want && count > 0:
+				return assertFailure(node, scope, "expected no row in '%s' matching %v, found %d", table, where, count)
+			}
+			return nil
+		}, engine.SlotMeta{
+			Example: name + ": 'users'\n  where: { email: 'budi@example.com' }",
+			Inputs: map[string]engine.InputMeta{
+				"where": {Description: "Column values the row must have", Required: false},
+				"count": {Description: "Exact number of matching rows (assert.db_has only)", Required: false},
+				"db":    {Description: "Database connection name (Default: 'default')", Required: false},
+			},
+		})
+	}
+	dbSlot("assert.db_has", true)
+	dbSlot("assert.db_missing", false)
+}
+
+// countRows counts the rows of table whose columns equal the given values (nil means IS NULL)
+func countRows(ctx context.Context, db *sql.DB, dialect dbmanager.Dialect, table string, where map[string]interface{}) (int, error) {
+	cols := make([]string, 0, len(where))
+	for col := range where {
+		cols = append(cols, col)
+	}
+	sort.Strings(cols)
+
+	query := "SELECT COUNT(*) FROM " + dialect.QuoteIdentifier(table)
+	var conds []string
+	var args []interface{}
+	for _, col := range cols {
+		if where[col] == nil {
+			conds = append(conds, dialect.QuoteIdentifier(col)+" IS NULL")
+			continue
+		}
+		args = append(args, where[col])
+		conds = append(conds, dialect.QuoteIdentifier(col)+" = "+dialect.Placeholder(len(args)))
+	}
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+
+	var count int
+	if err := db.QueryRowContext(ctx, query, args...).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
@@ -0,0 +1,103 @@
+package slots
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nextcore/zeno-go/pkg/engine"
+	"github.com/nextcore/zenoengine/pkg/dbmanager"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssertHelpers(t *testing.T) {
+	assert.True(t, containsValue("hello zeno", "zeno"))
+	assert.True(t, containsValue([]interface{}{"admin", "editor"}, "admin"))
+	assert.True(t, containsValue([]interface{}{1, 2, 3}, "2"))
+	assert.True(t, containsValue(map[string]interface{}{"email": "x"}, "email"))
+	assert.False(t, containsValue(map[string]interface{}{"email": "x"}, "name"))
+	assert.False(t, containsValue([]string{"a"}, "b"))
+
+	n, ok := lengthOf([]interface{}{1, 2})
+	assert.True(t, ok)
+	assert.Equal(t, 2, n)
+	n, _ = lengthOf("héllo")
+	assert.Equal(t, 5, n)
+	_, ok = lengthOf(42)
+	assert.False(t, ok)
+}
+
+func TestSnapshotFiles(t *testing.T) {
+	assert.Equal(t, filepath.Join("tests", "__snapshots__", "users", "list", "lists_active_users.json"),
+		snapshotPath("tests/users/list_test.zl", "Lists active users!", "json"))
+	assert.Equal(t, filepath.Join("tests", "__snapshots__", "home.html"), snapshotPath("", "Home", "html"))
+
+	content, ext, err := snapshotContent(map[string]interface{}{
+		"status": 200, "content": `{"b":1,"a":[true]}`, "body": map[string]interface{}{"b": 1, "a": []interface{}{true}},
+	}, "")
+	require.NoError(t, err)
+	assert.Equal(t, "json", ext)
+	assert.Equal(t, "{\n  \"a\": [\n    true\n  ],\n  \"b\": 1\n}\n", content)
+
+	content, ext, err = snapshotContent("<h1>Hi</h1>", "")
+	require.NoError(t, err)
+	assert.Equal(t, "html", ext)
+	assert.Equal(t, "<h1>Hi</h1>\n", content)
+
+	assert.Equal(t, `line 2: expected "b", got "c"`, firstDifference("a\nb\n", "a\nc\n"))
+}
+
+func TestAssertSnapshotSlot(t *testing.T) {
+	dir := t.TempDir()
+	old := SnapshotDir
+	SnapshotDir = dir
+	defer func() { SnapshotDir = old }()
+
+	eng := engine.NewEngine()
+	RegisterTestSlots(eng)
+	scope := engine.NewScope(nil)
+	scope.Set("page", "<p>v1</p>")
+	node := &engine.Node{Name: "assert.snapshot", Value: "$page", Children: []*engine.Node{{Name: "name", Value: "page"}}}
+
+	// First run writes the snapshot, the second compares against it
+	require.NoError(t, eng.Execute(context.Background(), node, scope))
+	stored, err := os.ReadFile(filepath.Join(dir, "page.html"))
+	require.NoError(t, err)
+	assert.Equal(t, "<p>v1</p>\n", string(stored))
+	require.NoError(t, eng.Execute(context.Background(), node, scope))
+
+	scope.Set("page", "<p>v2</p>")
+	assert.Error(t, eng.Execute(context.Background(), node, scope))
+	require.NoError(t, eng.Execute(WithSnapshotUpdate(context.Background(), true), node, scope))
+	stored, _ = os.ReadFile(filepath.Join(dir, "page.html"))
+	assert.Equal(t, "<p>v2</p>\n", string(stored))
+}
+
+func TestCountRows(t *testing.T) {
+	dbMgr := dbmanager.NewDBManager()
+	require.NoError(t, dbMgr.AddConnection("default", "sqlite", ":memory:", 1, 1))
+	defer dbMgr.Close()
+	db := dbMgr.GetConnection("default")
+	_, err := db.Exec("CREATE TABLE users (id INTEGER PRIMARY KEY, email TEXT, deleted_at TEXT)")
+	require.NoError(t, err)
+	_, err = db.Exec("INSERT INTO users (email) VALUES ('a@x.com'), ('b@x.com')")
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	dialect := dbMgr.GetDialect("default")
+
+	n, err := countRows(ctx, db, dialect, "users", nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	n, err = countRows(ctx, db, dialect, "users", map[string]interface{}{"email": "a@x.com", "deleted_at": nil})
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	n, err = countRows(ctx, db, dialect, "users", map[string]interface{}{"email": "c@x.com"})
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+}
@@ -0,0 +1,509 @@
+package slots
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/nextcore/zeno-go/pkg/engine"
+	"github.com/nextcore/zeno-go/pkg/utils/coerce"
+)
+
+const (
+	testRouterKey contextKey = "testRouter"
+	testDBKey     contextKey = "testDatabase"
+
+	// Variabel scope untuk identitas dari test.acting_as, direset di setiap blok test
+	testTokenVar  = "_test_token"
+	testTenantVar = "_test_tenant"
+
+	// testSavepoint membungkus setiap blok test agar perubahan database di-rollback
+	testSavepoint = "zeno_test"
+)
+
+// TestRouterFunc returns the application router used by the test.* request slots.
+// It is called lazily so test files without HTTP requests don't need src/main.zl.
+type TestRouterFunc func() (http.Handler, error)
+
+// WithTestRouter makes the router available to test.get, test.post, test.json, etc.
+func WithTestRouter(ctx context.Context, router TestRouterFunc) context.Context {
+	return context.WithValue(ctx, testRouterKey, router)
+}
+
+// WithTestDatabase wraps every test block in a savepoint on db that is rolled back afterwards.
+// db must be limited to a single connection so the application queries share the transaction.
+func WithTestDatabase(ctx context.Context, db *sql.DB) context.Context {
+	return context.WithValue(ctx, testDBKey, db)
+}
+
+// beginTestTransaction opens the savepoint of a test block, the returned func rolls it back
+func beginTestTransaction(ctx context.Context) (func(), error) {
+	db, ok := ctx.Value(testDBKey).(*sql.DB)
+	if !ok || db == nil {
+		return func() {}, nil
+	}
+	if _, err := db.ExecContext(ctx, "SAVEPOINT "+testSavepoint); err != nil {
+		return nil, fmt.Errorf("failed to start test transaction: %w", err)
+	}
+	return func() {
+		db.ExecContext(context.Background(), "ROLLBACK TO SAVEPOINT "+testSavepoint)
+		db.ExecContext(context.Background(), "RELEASE SAVEPOINT "+testSavepoint)
+	}, nil
+}
+
+// testSavepointSeq memberi nama unik untuk savepoint db.transaction di dalam test
+var testSavepointSeq atomic.Int64
+
+// inTestDatabase reports whether db is the connection wrapped by WithTestDatabase
+func inTestDatabase(ctx context.Context, db *sql.DB) bool {
+	testDB, ok := ctx.Value(testDBKey).(*sql.DB)
+	return ok && testDB == db
+}
+
+// withTestSavepoint runs fn in a savepoint nested in the test block's savepoint. db.transaction
+// uses it on the test database: BEGIN would fail inside the open savepoint, and a *sql.Tx would
+// hold the only connection that the application queries need.
+func withTestSavepoint(ctx context.Context, db *sql.DB, fn func() error) error {
+	name := fmt.Sprintf("%s_%d", testSavepoint, testSavepointSeq.Add(1))
+	if _, err := db.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return err
+	}
+	if err := fn(); err != nil {
+		db.ExecContext(context.Background(), "ROLLBACK TO SAVEPOINT "+name)
+		db.ExecContext(context.Background(), "RELEASE SAVEPOINT "+name)
+		return err
+	}
+	_, err := db.ExecContext(ctx, "RELEASE SAVEPOINT "+name)
+	return err
+}
+
+// testRequest sends one request through the application router without a listener
+func testRequest(ctx context.Context, method, target string, body io.Reader, headers map[string]string) (map[string]interface{}, error) {
+	routerFn, ok := ctx.Value(testRouterKey).(TestRouterFunc)
+	if !ok || routerFn == nil {
+		return nil, fmt.Errorf("no application router available, run the test with 'zeno test'")
+	}
+	router, err := routerFn()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build router: %v", err)
+	}
+
+	req := httptest.NewRequest(method, target, body).WithContext(ctx)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	res := rec.Result()
+	content := rec.Body.String()
+
+	respHeaders := make(map[string]interface{})
+	for k, v := range res.Header {
+		if len(v) > 0 {
+			respHeaders[k] = v[0]
+		}
+	}
+
+	var respBody interface{} = content
+	if strings.Contains(res.Header.Get("Content-Type"), "application/json") {
+		var parsed interface{}
+		if err := json.Unmarshal(rec.Body.Bytes(), &parsed); err == nil {
+			respBody = parsed
+		}
+	}
+
+	return map[string]interface{}{
+		"status":  res.StatusCode,
+		"headers": respHeaders,
+		"body":    respBody,
+		"content": content,
+	}, nil
+}
+
+// registerTestRequestSlot registers test.get, test.post, ... and test.json
+func registerTestRequestSlot(eng *engine.Engine, name, method string, asJSON bool) {
+	eng.Register(name, func(ctx context.Context, node *engine.Node, scope *engine.Scope) error {
+		target := "response"
+		path := coerce.ToString(resolveValue(node.Value, scope))
+		reqMethod := method
+		var body interface{}
+		rawJSON := asJSON
+		headers := map[string]string{}
+		query := url.Values{}
+
+		for _, c := range node.Children {
+			val := parseNodeValue(c, scope)
+			switch c.Name {
+			case "path", "url":
+				path = coerce.ToString(val)
+			case "method":
+				reqMethod = strings.ToUpper(coerce.ToString(val))
+			case "headers":
+				if h, ok := val.(map[string]interface{}); ok {
+					for k, v := range h {
+						headers[k] = coerce.ToString(v)
+					}
+				}
+			case "query":
+				if q, ok := val.(map[string]interface{}); ok {
+					for k, v := range q {
+						query.Set(k, coerce.ToString(v))
+					}
+				}
+			case "body", "data", "form":
+				body = val
+			case "json":
+				body, rawJSON = val, true
+			case "as":
+				target = strings.TrimPrefix(coerce.ToString(c.Value), "$")
+			}
+		}
+
+		if path == "" {
+			return fmt.Errorf("%s: path is required", name)
+		}
+		if reqMethod == "" {
+			reqMethod = "GET"
+			if body != nil {
+				reqMethod = "POST"
+			}
+		}
+		if len(query) > 0 {
+			sep := "?"
+			if strings.Contains(path, "?") {
+				sep = "&"
+			}
+			path += sep + query.Encode()
+		}
+
+		// Body: map dikirim sebagai form (atau JSON untuk test.json / json:), string dikirim apa adanya
+		var reqBody io.Reader
+		if body != nil {
+			switch b := body.(type) {
+			case string:
+				reqBody = strings.NewReader(b)
+			case map[string]interface{}:
+				if rawJSON {
+					data, err := json.Marshal(b)
+					if err != nil {
+						return fmt.Errorf("%s: failed to marshal body: %w", name, err)
+					}
+					reqBody = bytes.NewReader(data)
+				} else {
+					form := url.Values{}
+					for k, v := range b {
+						form.Set(k, coerce.ToString(v))
+					}
+					reqBody = strings.NewReader(form.Encode())
+					setDefaultHeader(headers, "Content-Type", "application/x-www-form-urlencoded")
+				}
+			default:
+				data, err := json.Marshal(b)
+				if err != nil {
+					return fmt.Errorf("%s: failed to marshal body: %w", name, err)
+				}
+				reqBody, rawJSON = bytes.NewReader(data), true
+			}
+		}
+		if rawJSON {
+			setDefaultHeader(headers, "Content-Type", "application/json")
+		}
+		if asJSON {
+			setDefaultHeader(headers, "Accept", "application/json")
+		}
+
+		// Identitas dari test.acting_as
+		if token, ok := scope.Get(testTokenVar); ok && coerce.ToString(token) != "" {
+			setDefaultHeader(headers, "Authorization", "Bearer "+coerce.ToString(token))
+		}
+		if tenant, ok := scope.Get(testTenantVar); ok && coerce.ToString(tenant) != "" {
+			setDefaultHeader(headers, "X-Tenant-ID", coerce.ToString(tenant))
+		}
+
+		res, err := testRequest(ctx, reqMethod, path, reqBody, headers)
+		if err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+		scope.Set(target, res)
+		return nil
+	}, engine.SlotMeta{
+		Description: fmt.Sprintf("Sends a %s request through the application router inside a test.", strings.ToUpper(strings.TrimPrefix(name, "test."))),
+		Example:     name + ": '/api/users'\n  as: $response",
+		Inputs: map[string]engine.InputMeta{
+			"headers": {Description: "Request headers", Required: false},
+			"query":   {Description: "Query string parameters", Required: false},
+			"body":    {Description: "Request body (map is sent as form, or JSON for test.json)", Required: false},
+			"json":    {Description: "Request body sent as JSON", Required: false},
+			"as":      {Description: "Variable to store the response (Default: $response)", Required: false},
+		},
+	})
+}
+
+func setDefaultHeader(headers map[string]string, key, value string) {
+	for k := range headers {
+		if strings.EqualFold(k, key) {
+			return
+		}
+	}
+	headers[key] = value
+}
+
+// testResponse resolves the response an assertion refers to: the 'response' child or $response
+func testResponse(node *engine.Node, scope *engine.Scope) (map[string]interface{}, error) {
+	var res interface{}
+	found := false
+	for _, c := range node.Children {
+		if c.Name == "response" {
+			res, found = parseNodeValue(c, scope), true
+		}
+	}
+	if !found {
+		res, _ = scope.Get("response")
+	}
+	m, ok := res.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("no response to assert on, send a request with test.get/test.post first")
+	}
+	return m, nil
+}
+
+// jsonPathValue walks a dot separated path (data.items.0.name) through decoded JSON
+func jsonPathValue(data interface{}, path string) (interface{}, bool) {
+	curr := data
+	for _, part := range strings.Split(strings.Trim(path, "."), ".") {
+		if part == "" {
+			continue
+		}
+		switch v := curr.(type) {
+		case map[string]interface{}:
+			next, ok := v[part]
+			if !ok {
+				return nil, false
+			}
+			curr = next
+		case []interface{}:
+			idx := -1
+			fmt.Sscanf(part, "%d", &idx)
+			if idx < 0 || idx >= len(v) {
+				return nil, false
+			}
+			curr = v[idx]
+		default:
+			return nil, false
+		}
+	}
+	return curr, true
+}
+
+// looseEqual compares like assert.eq: deep equality or the same string form
+func looseEqual(a, b interface{}) bool {
+	return reflect.DeepEqual(a, b) || coerce.ToString(a) == coerce.ToString(b)
+}
+
+func registerTestHTTPSlots(eng *engine.Engine) {
+	registerTestRequestSlot(eng, "test.get", "GET", false)
+	registerTestRequestSlot(eng, "test.post", "POST", false)
+	registerTestRequestSlot(eng, "test.put", "PUT", false)
+	registerTestRequestSlot(eng, "test.patch", "PATCH", false)
+	registerTestRequestSlot(eng, "test.delete", "DELETE", false)
+	registerTestRequestSlot(eng, "test.json", "", true)
+
+	// SLOT: test.acting_as
+	// Menandatangani JWT yang diterima MultiTenantAuth untuk request berikutnya di blok test ini
+	eng.Register("test.acting_as", func(ctx context.Context, node *engine.Node, scope *engine.Scope) error {
+		subject := resolveValue(node.Value, scope)
+		secret := os.Getenv("JWT_SECRET")
+		expiresIn := time.Hour
+		claims := jwt.MapClaims{}
+		tenant := ""
+
+		for _, c := range node.Children {
+			val := parseNodeValue(c, scope)
+			switch c.Name {
+			case "user":
+				subject = val
+			case "claims":
+				if m, ok := val.(map[string]interface{}); ok {
+					for k, v := range m {
+						claims[k] = v
+					}
+				}
+			case "tenant":
+				tenant = coerce.ToString(val)
+			case "secret":
+				secret = coerce.ToString(val)
+			case "expires_in":
+				if s, err := coerce.ToInt(val); err == nil && s > 0 {
+					expiresIn = time.Duration(s) * time.Second
+				}
+			}
+		}
+
+		if secret == "" {
+			return fmt.Errorf("test.acting_as: JWT_SECRET is not configured")
+		}
+
+		// User bisa berupa row (map) atau langsung ID
+		if user, ok := subject.(map[string]interface{}); ok {
+			for _, key := range []string{"user_id", "email", "username", "role", "tenant_id"} {
+				if v, exists := user[key]; exists {
+					if _, set := claims[key]; !set {
+						claims[key] = v
+					}
+				}
+			}
+			if id, exists := user["id"]; exists {
+				if _, set := claims["user_id"]; !set {
+					claims["user_id"] = id
+				}
+			}
+		} else if subject != nil {
+			if _, set := claims["user_id"]; !set {
+				claims["user_id"] = subject
+			}
+		}
+		if tid, ok := claims["tenant_id"]; ok && tenant == "" {
+			tenant = coerce.ToString(tid)
+		}
+		claims["exp"] = time.Now().Add(expiresIn).Unix()
+
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+		if err != nil {
+			return fmt.Errorf("test.acting_as: failed to sign token: %v", err)
+		}
+		scope.Set(testTokenVar, token)
+		scope.Set(testTenantVar, tenant)
+		return nil
+	}, engine.SlotMeta{
+		Description: "Authenticates the following test requests as the given user with a signed JWT.",
+		Example:     "test.acting_as: $user\n  claims: { role: 'admin' }",
+		Inputs: map[string]engine.InputMeta{
+			"claims":     {Description: "Extra JWT claims", Required: false},
+			"tenant":     {Description: "Tenant code sent as X-Tenant-ID", Required: false},
+			"secret":     {Description: "JWT secret (Default: JWT_SECRET)", Required: false},
+			"expires_in": {Description: "Token lifetime in seconds (Default: 3600)", Required: false},
+		},
+	})
+
+	// SLOT: assert.status
+	eng.Register("assert.status", func(ctx context.Context, node *engine.Node, scope *engine.Scope) error {
+		res, err := testResponse(node, scope)
+		if err != nil {
+			return err
+		}
+		expected := coerce.ToString(resolveValue(node.Value, scope))
+		if got := coerce.ToString(res["status"]); got != expected {
+			return fmt.Errorf("expected status %s, got %s", expected, got)
+		}
+		return nil
+	}, engine.SlotMeta{Example: "assert.status: 200"})
+
+	// SLOT: assert.header
+	eng.Register("assert.header", func(ctx context.Context, node *engine.Node, scope *engine.Scope) error {
+		res, err := testResponse(node, scope)
+		if err != nil {
+			return err
+		}
+		name := coerce.ToString(resolveValue(node.Value, scope))
+		headers, _ := res["headers"].(map[string]interface{})
+		value, ok := headers[http.CanonicalHeaderKey(name)]
+		if !ok {
+			return fmt.Errorf("expected header '%s' to be present", name)
+		}
+		for _, c := range node.Children {
+			switch c.Name {
+			case "expected", "val":
+				if exp := parseNodeValue(c, scope); !looseEqual(value, exp) {
+					return fmt.Errorf("expected header '%s' to be '%v', got '%v'", name, exp, value)
+				}
+			case "contains":
+				if exp := coerce.ToString(parseNodeValue(c, scope)); !strings.Contains(coerce.ToString(value), exp) {
+					return fmt.Errorf("expected header '%s' to contain '%s', got '%v'", name, exp, value)
+				}
+			}
+		}
+		return nil
+	}, engine.SlotMeta{Example: "assert.header: 'Content-Type'\n  contains: 'application/json'"})
+
+	// SLOT: assert.json
+	eng.Register("assert.json", func(ctx context.Context, node *engine.Node, scope *engine.Scope) error {
+		res, err := testResponse(node, scope)
+		if err != nil {
+			return err
+		}
+		path := coerce.ToString(resolveValue(node.Value, scope))
+		body := res["body"]
+		if _, isString := body.(string); isString {
+			headers, _ := res["headers"].(map[string]interface{})
+			return fmt.Errorf("expected a JSON response, got '%v'", headers["Content-Type"])
+		}
+		value, ok := jsonPathValue(body, path)
+		if !ok {
+			return fmt.Errorf("expected JSON path '%s' to exist", path)
+		}
+		for _, c := range node.Children {
+			if c.Name == "expected" || c.Name == "val" {
+				if exp := parseNodeValue(c, scope); !looseEqual(value, exp) {
+					return fmt.Errorf("expected JSON path '%s' to be %v, got %v", path, exp, value)
+				}
+			}
+		}
+		return nil
+	}, engine.SlotMeta{Example: "assert.json: 'data.0.name'\n  expected: 'Budi'"})
+
+	// SLOT: assert.see / assert.dont_see
+	seeSlot := func(name string, want bool) {
+		eng.Register(name, func(ctx context.Context, node *engine.Node, scope *engine.Scope) error {
+			res, err := testResponse(node, scope)
+			if err != nil {
+				return err
+			}
+			text := coerce.ToString(resolveValue(node.Value, scope))
+			content := coerce.ToString(res["content"])
+			// Teks dicocokkan mentah atau dalam bentuk HTML-escaped seperti hasil render Blade
+			found := strings.Contains(content, text) || strings.Contains(content, html.EscapeString(text))
+			if found != want {
+				if want {
+					return fmt.Errorf("expected response to contain '%s'", text)
+				}
+				return fmt.Errorf("expected response not to contain '%s'", text)
+			}
+			return nil
+		}, engine.SlotMeta{Example: name + ": 'Welcome back'"})
+	}
+	seeSlot("assert.see", true)
+	seeSlot("assert.dont_see", false)
+
+	// SLOT: assert.redirect
+	eng.Register("assert.redirect", func(ctx context.Context, node *engine.Node, scope *engine.Scope) error {
+		res, err := testResponse(node, scope)
+		if err != nil {
+			return err
+		}
+		status := coerce.ToString(res["status"])
+		if !strings.HasPrefix(status, "3") {
+			return fmt.Errorf("expected a redirect, got status %s", status)
+		}
+		headers, _ := res["headers"].(map[string]interface{})
+		if expected := coerce.ToString(resolveValue(node.Value, scope)); node.Value != nil && expected != "" {
+			if location := coerce.ToString(headers["Location"]); location != expected {
+				return fmt.Errorf("expected redirect to '%s', got '%s'", expected, location)
+			}
+		}
+		return nil
+	}, engine.SlotMeta{Example: "assert.redirect: '/login'"})
+}
@@ -0,0 +1,164 @@
+package slots
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/nextcore/zeno-go/pkg/engine"
+	"github.com/nextcore/zenoengine/pkg/dbmanager"
+	"github.com/nextcore/zenoengine/pkg/middleware"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAppRouter() TestRouterFunc {
+	r := chi.NewRouter()
+	r.Get("/hello", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<h1>Hello &lt;Zeno&gt;</h1>"))
+	})
+	r.With(middleware.MultiTenantAuth("testsecret")).Get("/api/me", func(w http.ResponseWriter, r *http.Request) {
+		claims := r.Context().Value("session").(jwt.MapClaims)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{"user_id": claims["user_id"], "roles": []string{"admin"}},
+		})
+	})
+	return func() (http.Handler, error) { return r, nil }
+}
+
+func TestTestRequest(t *testing.T) {
+	ctx := WithTestRouter(context.Background(), newTestAppRouter())
+
+	res, err := testRequest(ctx, "GET", "/hello", nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 200, res["status"])
+	assert.Equal(t, "<h1>Hello &lt;Zeno&gt;</h1>", res["content"])
+
+	res, err = testRequest(ctx, "GET", "/api/me", nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 401, res["status"])
+
+	_, err = testRequest(context.Background(), "GET", "/hello", nil, nil)
+	assert.Error(t, err)
+}
+
+func TestJSONPathValue(t *testing.T) {
+	data := map[string]interface{}{
+		"data": []interface{}{
+			map[string]interface{}{"name": "Budi"},
+		},
+	}
+
+	v, ok := jsonPathValue(data, "data.0.name")
+	assert.True(t, ok)
+	assert.Equal(t, "Budi", v)
+
+	_, ok = jsonPathValue(data, "data.1.name")
+	assert.False(t, ok)
+	_, ok = jsonPathValue(data, "meta")
+	assert.False(t, ok)
+}
+
+func TestTestHTTPSlots(t *testing.T) {
+	t.Setenv("JWT_SECRET", "testsecret")
+	eng := engine.NewEngine()
+	RegisterTestSlots(eng)
+	ctx := WithTestRouter(context.Background(), newTestAppRouter())
+	scope := engine.NewScope(nil)
+
+	run := func(node *engine.Node) error { return eng.Execute(ctx, node, scope) }
+
+	require.NoError(t, run(&engine.Node{Name: "test.get", Value: "/hello"}))
+	assert.NoError(t, run(&engine.Node{Name: "assert.status", Value: 200}))
+	assert.NoError(t, run(&engine.Node{Name: "assert.see", Value: "Hello <Zeno>"}))
+	assert.Error(t, run(&engine.Node{Name: "assert.dont_see", Value: "Hello"}))
+	assert.NoError(t, run(&engine.Node{Name: "assert.header", Value: "content-type", Children: []*engine.Node{{Name: "expected", Value: "text/html"}}}))
+
+	require.NoError(t, run(&engine.Node{Name: "test.acting_as", Value: map[string]interface{}{"id": 7}}))
+	require.NoError(t, run(&engine.Node{Name: "test.json", Value: "/api/me"}))
+	assert.NoError(t, run(&engine.Node{Name: "assert.status", Value: 200}))
+	assert.NoError(t, run(&engine.Node{Name: "assert.json", Value: "data.user_id", Children: []*engine.Node{{Name: "expected", Value: 7}}}))
+	assert.NoError(t, run(&engine.Node{Name: "assert.json", Value: "data.roles.0"}))
+	assert.Error(t, run(&engine.Node{Name: "assert.json", Value: "data.missing"}))
+}
+
+func TestTestDatabaseRollback(t *testing.T) {
+	dbMgr := dbmanager.NewDBManager()
+	require.NoError(t, dbMgr.AddConnection("default", "sqlite", ":memory:", 1, 1))
+	defer dbMgr.Close()
+	db := dbMgr.GetConnection("default")
+	_, err := db.Exec("CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)")
+	require.NoError(t, err)
+
+	ctx := WithTestDatabase(context.Background(), db)
+	rollback, err := beginTestTransaction(ctx)
+	require.NoError(t, err)
+	_, err = db.Exec("INSERT INTO users (name) VALUES ('budi')")
+	require.NoError(t, err)
+	rollback()
+
+	var count int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM users").Scan(&count))
+	assert.Equal(t, 0, count)
+
+	// The next test starts a new savepoint on the same connection
+	rollback, err = beginTestTransaction(ctx)
+	require.NoError(t, err)
+	rollback()
+}
+
+func TestTestDatabaseTransaction(t *testing.T) {
+	dbMgr := dbmanager.NewDBManager()
+	require.NoError(t, dbMgr.AddConnection("default", "sqlite", ":memory:", 1, 1))
+	defer dbMgr.Close()
+	db := dbMgr.GetConnection("default")
+	_, err := db.Exec("CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)")
+	require.NoError(t, err)
+
+	eng := engine.NewEngine()
+	RegisterTransactionSlots(eng, dbMgr)
+	RegisterRawDBSlots(eng, dbMgr)
+	eng.Register("fail_now", func(ctx context.Context, n *engine.Node, s *engine.Scope) error {
+		return assert.AnError
+	}, engine.SlotMeta{})
+	insert := func(name string) *engine.Node {
+		return &engine.Node{Name: "db.execute", Value: "INSERT INTO users (name) VALUES ('" + name + "')"}
+	}
+	count := func() int {
+		var n int
+		require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM users").Scan(&n))
+		return n
+	}
+
+	ctx := WithTestDatabase(context.Background(), db)
+	rollback, err := beginTestTransaction(ctx)
+	require.NoError(t, err)
+
+	// Commit: the rows stay until the test block is rolled back
+	scope := engine.NewScope(nil)
+	err = eng.Execute(ctx, &engine.Node{Name: "db.transaction", Children: []*engine.Node{
+		{Name: "do", Children: []*engine.Node{insert("budi"), insert("siti")}},
+	}}, scope)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count())
+
+	// Error: only the changes of this transaction are undone, including a nested one
+	err = eng.Execute(ctx, &engine.Node{Name: "db.transaction", Children: []*engine.Node{
+		{Name: "do", Children: []*engine.Node{
+			insert("andi"),
+			{Name: "db.transaction", Children: []*engine.Node{{Name: "do", Children: []*engine.Node{insert("rina")}}}},
+			{Name: "fail_now"},
+		}},
+	}}, scope)
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.Equal(t, 2, count())
+
+	rollback()
+	assert.Equal(t, 0, count())
+}
@@ -0,0 +1,186 @@
+package slots
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+)
+
+// TestSuite groups the results of one *_test.zl file
+type TestSuite struct {
+	File     string
+	Stats    *TestStats
+	Duration time.Duration
+	Err      error // Error di luar blok test (gagal load / eksekusi file)
+}
+
+// Failures counts the failed tests of the suite, a file level error counts as one failure
+func (s *TestSuite) Failures() int {
+	n := s.Stats.Failed
+	if s.Err != nil {
+		n++
+	}
+	return n
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr,omitempty"`
+	Line      int           `xml:"line,attr,omitempty"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Body    string `xml:",chardata"`
+}
+
+func junitSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+// WriteJUnitReport writes the suites in the JUnit XML format understood by most CI systems
+func WriteJUnitReport(w io.Writer, suites []*TestSuite) error {
+	report := junitTestSuites{}
+	var total time.Duration
+	for _, s := range suites {
+		js := junitTestSuite{
+			Name:     s.File,
+			Tests:    s.Stats.Total,
+			Failures: s.Failures(),
+			Skipped:  s.Stats.Skipped,
+			Time:     junitSeconds(s.Duration),
+		}
+		for _, r := range s.Stats.Results {
+			tc := junitTestCase{
+				Name:      r.Name,
+				Classname: s.File,
+				File:      r.File,
+				Line:      r.Line,
+				Time:      junitSeconds(r.Duration),
+			}
+			if !r.Passed {
+				tc.Failure = &junitFailure{Message: r.Error, Body: r.Error}
+			}
+			js.Cases = append(js.Cases, tc)
+		}
+		if s.Err != nil {
+			js.Tests++
+			js.Cases = append(js.Cases, junitTestCase{
+				Name:      "(file)",
+				Classname: s.File,
+				Time:      "0.000",
+				Failure:   &junitFailure{Message: s.Err.Error(), Body: s.Err.Error()},
+			})
+		}
+
+		report.Tests += js.Tests
+		report.Failures += js.Failures
+		report.Skipped += js.Skipped
+		total += s.Duration
+		report.Suites = append(report.Suites, js)
+	}
+	report.Time = junitSeconds(total)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+type jsonTestReport struct {
+	Total      int             `json:"total"`
+	Passed     int             `json:"passed"`
+	Failed     int             `json:"failed"`
+	Skipped    int             `json:"skipped"`
+	DurationMs int64           `json:"duration_ms"`
+	Files      []jsonTestSuite `json:"files"`
+}
+
+type jsonTestSuite struct {
+	File       string         `json:"file"`
+	Passed     int            `json:"passed"`
+	Failed     int            `json:"failed"`
+	Skipped    int            `json:"skipped"`
+	DurationMs int64          `json:"duration_ms"`
+	Error      string         `json:"error,omitempty"`
+	Tests      []jsonTestCase `json:"tests"`
+}
+
+type jsonTestCase struct {
+	Name       string `json:"name"`
+	Line       int    `json:"line,omitempty"`
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// WriteJSONReport writes a machine readable summary of the suites
+func WriteJSONReport(w io.Writer, suites []*TestSuite) error {
+	report := jsonTestReport{Files: []jsonTestSuite{}}
+	for _, s := range suites {
+		js := jsonTestSuite{
+			File:       s.File,
+			Passed:     s.Stats.Passed,
+			Failed:     s.Failures(),
+			Skipped:    s.Stats.Skipped,
+			DurationMs: s.Duration.Milliseconds(),
+			Tests:      []jsonTestCase{},
+		}
+		if s.Err != nil {
+			js.Error = s.Err.Error()
+		}
+		for _, r := range s.Stats.Results {
+			tc := jsonTestCase{
+				Name:       r.Name,
+				Line:       r.Line,
+				Status:     "passed",
+				Error:      r.Error,
+				DurationMs: r.Duration.Milliseconds(),
+			}
+			if !r.Passed {
+				tc.Status = "failed"
+			}
+			js.Tests = append(js.Tests, tc)
+		}
+
+		report.Total += s.Stats.Total
+		report.Passed += js.Passed
+		report.Failed += js.Failed
+		report.Skipped += js.Skipped
+		report.DurationMs += js.DurationMs
+		report.Files = append(report.Files, js)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
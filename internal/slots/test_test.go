@@ -1,8 +1,13 @@
 package slots
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"regexp"
 	"testing"
+	"time"
 	"github.com/nextcore/zeno-go/pkg/engine"
 
 	"github.com/stretchr/testify/assert"
@@ -76,3 +81,70 @@ func TestTestSlots(t *testing.T) {
 		assert.Contains(t, stats.Errors[0], "FAIL [Failing Test]")
 	})
 }
+
+func TestTestFilter(t *testing.T) {
+	eng := engine.NewEngine()
+	RegisterTestSlots(eng)
+
+	stats := &TestStats{}
+	ctx := WithTestStats(context.Background(), stats)
+	ctx = WithTestFilter(ctx, regexp.MustCompile("login"))
+
+	for _, name := range []string{"user can login", "user can logout"} {
+		node := &engine.Node{
+			Name:     "test",
+			Value:    name,
+			Filename: "tests/auth_test.zl",
+			Line:     3,
+			Children: []*engine.Node{
+				{Name: "assert.eq", Value: 1, Children: []*engine.Node{{Name: "expected", Value: 1}}},
+			},
+		}
+		require.NoError(t, eng.Execute(ctx, node, engine.NewScope(nil)))
+	}
+
+	assert.Equal(t, 1, stats.Total)
+	assert.Equal(t, 1, stats.Skipped)
+	require.Len(t, stats.Results, 1)
+	assert.Equal(t, "user can login", stats.Results[0].Name)
+	assert.Equal(t, "tests/auth_test.zl", stats.Results[0].File)
+	assert.Equal(t, 3, stats.Results[0].Line)
+}
+
+func TestTestReports(t *testing.T) {
+	stats := &TestStats{}
+	stats.AddResult(TestResult{Name: "adds numbers", Passed: true, Duration: 2 * time.Millisecond})
+	stats.AddResult(TestResult{Name: "divides <numbers>", Error: "expected 2, got 3", Line: 9})
+	suites := []*TestSuite{
+		{File: "tests/math_test.zl", Stats: stats, Duration: 5 * time.Millisecond},
+		{File: "tests/broken_test.zl", Stats: &TestStats{}, Err: errors.New("syntax error")},
+	}
+
+	t.Run("junit", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, WriteJUnitReport(&buf, suites))
+		out := buf.String()
+		assert.Contains(t, out, `<testsuites tests="3" failures="2" skipped="0" time="0.005">`)
+		assert.Contains(t, out, `<testsuite name="tests/math_test.zl" tests="2" failures="1"`)
+		assert.Contains(t, out, `<testcase name="divides &lt;numbers&gt;" classname="tests/math_test.zl" line="9"`)
+		assert.Contains(t, out, `<failure message="expected 2, got 3">`)
+		assert.Contains(t, out, `<failure message="syntax error">`)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, WriteJSONReport(&buf, suites))
+
+		var report map[string]interface{}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &report))
+		assert.Equal(t, float64(2), report["total"])
+		assert.Equal(t, float64(1), report["passed"])
+		assert.Equal(t, float64(2), report["failed"])
+
+		files := report["files"].([]interface{})
+		require.Len(t, files, 2)
+		tests := files[0].(map[string]interface{})["tests"].([]interface{})
+		assert.Equal(t, "failed", tests[1].(map[string]interface{})["status"])
+		assert.Equal(t, "syntax error", files[1].(map[string]interface{})["error"])
+	})
+}
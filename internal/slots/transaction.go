@@ -37,6 +37,32 @@ func RegisterTransactionSlots(eng *engine.Engine, dbMgr *dbmanager.DBManager) {
 			return fmt.Errorf("db.transaction: database '%s' not found", dbName)
 		}
 
+		// Jika ada node 'do', eksekusi isinya.
+		// Jika tidak, eksekusi children langsung (shorthand).
+		nodesToExec := node.Children
+		if doNode != nil {
+			nodesToExec = doNode.Children
+		}
+		execBlock := func() error {
+			for _, child := range nodesToExec {
+				// Skip node konfigurasi 'db' atau 'do' wrapper saat looping direct children
+				if doNode == nil && (child.Name == "db" || child.Name == "do") {
+					continue
+				}
+
+				if err := eng.Execute(ctx, child, scope); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		// Di 'zeno test' koneksi ini sudah berada di savepoint test: transaksi menjadi
+		// savepoint bersarang di koneksi yang sama, query di dalam blok ikut di dalamnya
+		if inTestDatabase(ctx, db) {
+			return withTestSavepoint(ctx, db, execBlock)
+		}
+
 		// 3. Mulai Transaksi
 		tx, err := db.BeginTx(ctx, nil)
 		if err != nil {
@@ -51,26 +77,7 @@ func RegisterTransactionSlots(eng *engine.Engine, dbMgr *dbmanager.DBManager) {
 		defer scope.Set("_active_tx", nil)
 
 		// 4. Eksekusi Blok
-		var execErr error
-
-		// Jika ada node 'do', eksekusi isinya.
-		// Jika tidak, eksekusi children langsung (shorthand).
-		nodesToExec := node.Children
-		if doNode != nil {
-			nodesToExec = doNode.Children
-		}
-
-		for _, child := range nodesToExec {
-			// Skip node konfigurasi 'db' atau 'do' wrapper saat looping direct children
-			if doNode == nil && (child.Name == "db" || child.Name == "do") {
-				continue
-			}
-
-			if err := eng.Execute(ctx, child, scope); err != nil {
-				execErr = err
-				break
-			}
-		}
+		execErr := execBlock()
 
 		// 5. Commit atau Rollback
 		if execErr != nil {
@@ -29,6 +29,11 @@ type RouteDoc struct {
 	Params      []ParamDoc             `json:"parameters,omitempty"`
 	RequestBody *RequestBodyDoc        `json:"requestBody,omitempty"`
 	Responses   map[string]ResponseDoc `json:"responses"`
+
+	// Route listing (zeno route:list), not part of the OpenAPI output
+	Middleware []string `json:"middleware,omitempty"`
+	File       string   `json:"file,omitempty"`
+	Line       int      `json:"line,omitempty"`
 }
 
 type ParamDoc struct {
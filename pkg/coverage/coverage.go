@@ -0,0 +1,182 @@
+package coverage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/nextcore/zeno-go/pkg/engine"
+)
+
+// Collector records which script lines executed a slot while it was installed on one or more engines.
+// A line counts as coverable when it starts a slot call; variable assignments and keywords
+// handled by the executor itself are not tracked.
+type Collector struct {
+	mu    sync.Mutex
+	hits  map[string]map[int]int  // file -> line -> jumlah eksekusi
+	lines map[string]map[int]bool // file -> baris yang bisa di-cover
+	slots map[string]bool
+}
+
+// FileCoverage is the coverage of a single script
+type FileCoverage struct {
+	File  string
+	Lines []LineCoverage
+	Found int
+	Hit   int
+}
+
+// LineCoverage is a coverable line and how often it executed
+type LineCoverage struct {
+	Line int
+	Hits int
+}
+
+// Percent returns the covered percentage of the file
+func (f FileCoverage) Percent() float64 {
+	return percent(f.Hit, f.Found)
+}
+
+func NewCollector() *Collector {
+	return &Collector{
+		hits:  make(map[string]map[int]int),
+		lines: make(map[string]map[int]bool),
+		slots: make(map[string]bool),
+	}
+}
+
+// Instrument wraps every slot registered on eng so each call records its node's file and line.
+// Call it after all slots are registered.
+func (c *Collector) Instrument(eng *engine.Engine) {
+	names := make([]string, 0, len(eng.Registry))
+	for name := range eng.Registry {
+		names = append(names, name)
+	}
+
+	c.mu.Lock()
+	for _, name := range names {
+		c.slots[name] = true
+	}
+	c.mu.Unlock()
+
+	for _, name := range names {
+		handler := eng.Registry[name]
+		eng.Register(name, func(ctx context.Context, node *engine.Node, scope *engine.Scope) error {
+			c.Hit(node.Filename, node.Line)
+			return handler(ctx, node, scope)
+		}, eng.Docs[name])
+	}
+}
+
+// Hit records one execution of a line
+func (c *Collector) Hit(file string, line int) {
+	if file == "" || line <= 0 {
+		return
+	}
+	file = normalize(file)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.hits[file] == nil {
+		c.hits[file] = make(map[int]int)
+	}
+	c.hits[file][line]++
+}
+
+// AddSources parses every .zl script below root (Blade views excluded) and registers its
+// slot lines as coverable, so files that never ran are reported with 0%.
+func (c *Collector) AddSources(root string) error {
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			if path != root && strings.HasPrefix(info.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".zl") || strings.HasSuffix(path, ".blade.zl") {
+			return nil
+		}
+		node, err := engine.LoadScript(path)
+		if err != nil {
+			return nil // Script yang gagal di-parse dilaporkan oleh test runner, bukan di sini
+		}
+		c.addNode(normalize(path), node)
+		return nil
+	})
+}
+
+func (c *Collector) addNode(file string, node *engine.Node) {
+	if node == nil {
+		return
+	}
+	c.mu.Lock()
+	if node.Line > 0 && c.slots[node.Name] {
+		if c.lines[file] == nil {
+			c.lines[file] = make(map[int]bool)
+		}
+		c.lines[file][node.Line] = true
+	}
+	c.mu.Unlock()
+	for _, child := range node.Children {
+		c.addNode(file, child)
+	}
+}
+
+// Files returns the coverage of every source added with AddSources, sorted by path.
+// Hits on lines outside those sources (tests, vendor scripts) are ignored.
+func (c *Collector) Files() []FileCoverage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	files := make([]FileCoverage, 0, len(c.lines))
+	for file, lines := range c.lines {
+		fc := FileCoverage{File: file}
+		for line := range lines {
+			hits := c.hits[file][line]
+			fc.Lines = append(fc.Lines, LineCoverage{Line: line, Hits: hits})
+			fc.Found++
+			if hits > 0 {
+				fc.Hit++
+			}
+		}
+		sort.Slice(fc.Lines, func(i, j int) bool { return fc.Lines[i].Line < fc.Lines[j].Line })
+		files = append(files, fc)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].File < files[j].File })
+	return files
+}
+
+// Total returns the covered percentage over all files
+func Total(files []FileCoverage) float64 {
+	found, hit := 0, 0
+	for _, f := range files {
+		found += f.Found
+		hit += f.Hit
+	}
+	return percent(hit, found)
+}
+
+func percent(hit, found int) float64 {
+	if found == 0 {
+		return 100
+	}
+	return float64(hit) * 100 / float64(found)
+}
+
+// normalize makes paths from LoadScript and include comparable: relative to the working directory, with forward slashes
+func normalize(file string) string {
+	if filepath.IsAbs(file) {
+		if wd, err := os.Getwd(); err == nil {
+			if rel, err := filepath.Rel(wd, file); err == nil && !strings.HasPrefix(rel, "..") {
+				file = rel
+			}
+		}
+	}
+	return filepath.ToSlash(filepath.Clean(file))
+}
@@ -0,0 +1,134 @@
+package coverage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nextcore/zeno-go/pkg/engine"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestEngine membuat engine dengan slot log dan http.ok yang menghitung pemanggilannya
+func newTestEngine() (*engine.Engine, map[string]int) {
+	eng := engine.NewEngine()
+	calls := make(map[string]int)
+	for _, name := range []string{"log", "http.ok"} {
+		eng.Register(name, func(ctx context.Context, node *engine.Node, scope *engine.Scope) error {
+			calls[name]++
+			return nil
+		}, engine.SlotMeta{})
+	}
+	return eng, calls
+}
+
+func writeScript(t *testing.T, path, content string) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestInstrument(t *testing.T) {
+	eng, calls := newTestEngine()
+	c := NewCollector()
+	c.Instrument(eng)
+	t.Chdir(t.TempDir())
+	writeScript(t, "src/main.zl", "log: 'a'\nhttp.ok: 'b'\n")
+	assert.NoError(t, c.AddSources("."))
+
+	// Slot aslinya tetap dipanggil, setiap pemanggilan dicatat di file dan barisnya
+	ctx := context.Background()
+	scope := engine.NewScope(nil)
+	node := &engine.Node{Name: "log", Value: "'a'", Filename: "src/main.zl", Line: 1}
+	assert.NoError(t, eng.Execute(ctx, node, scope))
+	assert.NoError(t, eng.Execute(ctx, node, scope))
+	assert.Equal(t, 2, calls["log"])
+
+	// Node tanpa lokasi (dibuat oleh slot lain) tidak dihitung
+	assert.NoError(t, eng.Execute(ctx, &engine.Node{Name: "http.ok"}, scope))
+	assert.Equal(t, 1, calls["http.ok"])
+
+	files := c.Files()
+	if assert.Len(t, files, 1) {
+		assert.Equal(t, []LineCoverage{{Line: 1, Hits: 2}, {Line: 2, Hits: 0}}, files[0].Lines)
+	}
+}
+
+func TestHit(t *testing.T) {
+	eng, _ := newTestEngine()
+	c := NewCollector()
+	c.Instrument(eng)
+	dir := t.TempDir()
+	t.Chdir(dir)
+	writeScript(t, "src/main.zl", "log: 'a'\nlog: 'b'\nlog: 'c'\n")
+	assert.NoError(t, c.AddSources("."))
+
+	c.Hit("src/main.zl", 1)
+	c.Hit("./src/../src/main.zl", 1)               // Path yang sama setelah dibersihkan
+	c.Hit(filepath.Join(dir, "src", "main.zl"), 3) // Path absolut di bawah working directory
+	c.Hit("src/main.zl", 0)                        // Baris tidak valid
+	c.Hit("", 2)                                   // Tanpa file
+	c.Hit("tests/main_test.zl", 1)                 // Di luar sources: diabaikan
+
+	files := c.Files()
+	if assert.Len(t, files, 1) {
+		f := files[0]
+		assert.Equal(t, "src/main.zl", f.File)
+		assert.Equal(t, []LineCoverage{{Line: 1, Hits: 2}, {Line: 2, Hits: 0}, {Line: 3, Hits: 1}}, f.Lines)
+		assert.Equal(t, 3, f.Found)
+		assert.Equal(t, 2, f.Hit)
+		assert.InDelta(t, 66.67, f.Percent(), 0.01)
+	}
+}
+
+func TestAddSources(t *testing.T) {
+	eng, _ := newTestEngine()
+	c := NewCollector()
+	c.Instrument(eng)
+	t.Chdir(t.TempDir())
+
+	writeScript(t, "src/main.zl", "log: 'start'\n$name: 'zeno'\nhttp.ok: $name\n")
+	writeScript(t, "src/jobs/report.zl", "log: 'report'\n")
+	writeScript(t, "views/home.blade.zl", "log: 'view'\n")
+	writeScript(t, ".cache/old.zl", "log: 'cached'\n")
+	writeScript(t, "README.md", "log: 'docs'\n")
+	assert.NoError(t, c.AddSources("."))
+
+	files := c.Files()
+	var names []string
+	for _, f := range files {
+		names = append(names, f.File)
+	}
+	// Blade views, direktori tersembunyi dan file selain .zl dilewati; hasil terurut per path
+	assert.Equal(t, []string{"src/jobs/report.zl", "src/main.zl"}, names)
+
+	// Hanya baris yang memanggil slot yang bisa di-cover; file yang tidak pernah jalan tercatat 0%
+	assert.Equal(t, []LineCoverage{{Line: 1}, {Line: 3}}, files[1].Lines)
+	assert.Equal(t, 0.0, files[1].Percent())
+
+	// Sources yang tidak ada tidak membuat error
+	assert.NoError(t, c.AddSources("missing"))
+}
+
+func TestTotal(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []FileCoverage
+		want  float64
+	}{
+		{"no files", nil, 100},
+		{"nothing coverable", []FileCoverage{{File: "a.zl"}}, 100},
+		{"weighted by lines", []FileCoverage{{File: "a.zl", Found: 1, Hit: 1}, {File: "b.zl", Found: 3, Hit: 0}}, 25},
+		{"all covered", []FileCoverage{{File: "a.zl", Found: 2, Hit: 2}, {File: "b.zl", Found: 4, Hit: 4}}, 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Total(tt.files))
+		})
+	}
+}
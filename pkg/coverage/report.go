@@ -0,0 +1,126 @@
+package coverage
+
+import (
+	"bufio"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// WriteLCOV writes the coverage in the LCOV tracefile format (lcov.info)
+func WriteLCOV(w io.Writer, files []FileCoverage) error {
+	bw := bufio.NewWriter(w)
+	for _, f := range files {
+		fmt.Fprintf(bw, "TN:\nSF:%s\n", f.File)
+		for _, l := range f.Lines {
+			fmt.Fprintf(bw, "DA:%d,%d\n", l.Line, l.Hits)
+		}
+		fmt.Fprintf(bw, "LF:%d\nLH:%d\nend_of_record\n", f.Found, f.Hit)
+	}
+	return bw.Flush()
+}
+
+type htmlLine struct {
+	Number int
+	Text   string
+	Class  string // "hit", "miss" atau kosong untuk baris yang tidak di-cover
+	Hits   int
+}
+
+type htmlFile struct {
+	FileCoverage
+	Page  string
+	Lines []htmlLine
+}
+
+func (f htmlFile) Percent() string { return fmt.Sprintf("%.1f", f.FileCoverage.Percent()) }
+
+var htmlStyle = `
+body { font-family: -apple-system, Segoe UI, sans-serif; margin: 2rem; color: #222; }
+table { border-collapse: collapse; }
+td, th { padding: .3rem .8rem; text-align: left; border-bottom: 1px solid #eee; }
+.bar { display: inline-block; width: 120px; height: 8px; background: #f3c6c6; vertical-align: middle; }
+.bar span { display: block; height: 8px; background: #4caf50; }
+.src td { padding: 0 .6rem; border: 0; font-family: Menlo, Consolas, monospace; font-size: 13px; white-space: pre; }
+.src .num, .src .cnt { color: #999; text-align: right; }
+.hit { background: #e6ffed; }
+.miss { background: #ffeef0; }
+`
+
+var indexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>ZenoLang Coverage</title><style>` + htmlStyle + `</style></head>
+<body>
+<h1>Coverage: {{printf "%.1f" .Total}}%</h1>
+<table>
+<tr><th>File</th><th></th><th>Lines</th><th>%</th></tr>
+{{range .Files}}<tr><td><a href="{{.Page}}">{{.File}}</a></td><td><span class="bar"><span style="width: {{.Percent}}%"></span></span></td><td>{{.Hit}} / {{.Found}}</td><td>{{.Percent}}</td></tr>
+{{end}}</table>
+</body></html>
+`))
+
+var fileTemplate = template.Must(template.New("file").Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>{{.File}}</title><style>` + htmlStyle + `</style></head>
+<body>
+<p><a href="index.html">&larr; All files</a></p>
+<h1>{{.File}} &mdash; {{.Percent}}%</h1>
+<table class="src">
+{{range .Lines}}<tr class="{{.Class}}"><td class="num">{{.Number}}</td><td class="cnt">{{if .Class}}{{.Hits}}x{{end}}</td><td>{{.Text}}</td></tr>
+{{end}}</table>
+</body></html>
+`))
+
+// WriteHTML writes index.html and one annotated source page per file into dir
+func WriteHTML(dir string, files []FileCoverage) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	pages := make([]htmlFile, 0, len(files))
+	for _, f := range files {
+		page := htmlFile{
+			FileCoverage: f,
+			Page:         strings.NewReplacer("/", "_", "\\", "_").Replace(f.File) + ".html",
+		}
+
+		hits := make(map[int]int, len(f.Lines))
+		for _, l := range f.Lines {
+			hits[l.Line] = l.Hits
+		}
+		source, err := os.ReadFile(f.File)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", f.File, err)
+		}
+		for i, text := range strings.Split(strings.TrimRight(string(source), "\n"), "\n") {
+			line := htmlLine{Number: i + 1, Text: strings.TrimRight(text, "\r")}
+			if n, ok := hits[i+1]; ok {
+				line.Hits, line.Class = n, "miss"
+				if n > 0 {
+					line.Class = "hit"
+				}
+			}
+			page.Lines = append(page.Lines, line)
+		}
+
+		if err := renderHTML(filepath.Join(dir, page.Page), fileTemplate, page); err != nil {
+			return err
+		}
+		pages = append(pages, page)
+	}
+
+	return renderHTML(filepath.Join(dir, "index.html"), indexTemplate, map[string]interface{}{
+		"Total": Total(files),
+		"Files": pages,
+	})
+}
+
+func renderHTML(path string, tmpl *template.Template, data interface{}) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return tmpl.Execute(f, data)
+}
@@ -0,0 +1,56 @@
+package coverage
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteLCOV(t *testing.T) {
+	files := []FileCoverage{
+		{File: "src/main.zl", Lines: []LineCoverage{{Line: 1, Hits: 2}, {Line: 4, Hits: 0}}, Found: 2, Hit: 1},
+		{File: "src/jobs/report.zl", Lines: []LineCoverage{{Line: 2, Hits: 1}}, Found: 1, Hit: 1},
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, WriteLCOV(&buf, files))
+	assert.Equal(t, `TN:
+SF:src/main.zl
+DA:1,2
+DA:4,0
+LF:2
+LH:1
+end_of_record
+TN:
+SF:src/jobs/report.zl
+DA:2,1
+LF:1
+LH:1
+end_of_record
+`, buf.String())
+
+	buf.Reset()
+	assert.NoError(t, WriteLCOV(&buf, nil))
+	assert.Empty(t, buf.String())
+}
+
+func TestWriteHTML(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeScript(t, "src/main.zl", "log: 'a'\n\nlog: 'b'\n")
+	files := []FileCoverage{{File: "src/main.zl", Lines: []LineCoverage{{Line: 1, Hits: 3}, {Line: 3, Hits: 0}}, Found: 2, Hit: 1}}
+
+	assert.NoError(t, WriteHTML("coverage", files))
+	index, err := os.ReadFile(filepath.Join("coverage", "index.html"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(index), "Coverage: 50.0%")
+	assert.Contains(t, string(index), `href="src_main.zl.html"`)
+
+	page, err := os.ReadFile(filepath.Join("coverage", "src_main.zl.html"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(page), `<tr class="hit"><td class="num">1</td><td class="cnt">3x</td>`)
+	assert.Contains(t, string(page), `<tr class=""><td class="num">2</td>`)
+	assert.Contains(t, string(page), `<tr class="miss"><td class="num">3</td><td class="cnt">0x</td>`)
+}
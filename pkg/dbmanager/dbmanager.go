@@ -14,6 +14,7 @@ type DBManager struct {
 	mu          sync.RWMutex
 	connections map[string]*sql.DB
 	dialects    map[string]Dialect
+	dsns        map[string]string
 	defaultName string
 }
 
@@ -22,6 +23,7 @@ func NewDBManager() *DBManager {
 	return &DBManager{
 		connections: make(map[string]*sql.DB),
 		dialects:    make(map[string]Dialect),
+		dsns:        make(map[string]string),
 		defaultName: "default",
 	}
 }
@@ -56,6 +58,7 @@ func (m *DBManager) AddConnection(name, driverName, dsn string, maxOpen, maxIdle
 
 	m.connections[name] = db
 	m.dialects[name] = GetDialect(driverName)
+	m.dsns[name] = dsn
 	return nil
 }
 
@@ -74,6 +77,13 @@ func (m *DBManager) GetDialect(name string) Dialect {
 	return m.dialects[name]
 }
 
+// GetDSN mengembalikan DSN koneksi, untuk fitur yang butuh koneksi khusus di luar pool (mis. LISTEN Postgres)
+func (m *DBManager) GetDSN(name string) string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.dsns[name]
+}
+
 // GetDefault mengambil koneksi database default (primary)
 func (m *DBManager) GetDefault() (*sql.DB, Dialect) {
 	m.mu.RLock()
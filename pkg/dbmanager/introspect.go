@@ -0,0 +1,665 @@
+package dbmanager
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Querier dipenuhi *sql.DB, *sql.Tx dan *sql.Conn, sehingga katalog juga bisa dibaca di dalam transaksi
+type Querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// ColumnInfo mendeskripsikan satu kolom dari katalog database
+type ColumnInfo struct {
+	Name          string  `json:"name"`
+	Type          string  `json:"type"` // Tipe asli database, contoh: varchar(255)
+	Nullable      bool    `json:"nullable"`
+	Default       *string `json:"default"` // Ekspresi SQL, nil jika tidak ada default
+	PrimaryKey    bool    `json:"primary_key"`
+	AutoIncrement bool    `json:"auto_increment"`
+}
+
+// IndexInfo mendeskripsikan index (termasuk unique constraint dan primary key)
+type IndexInfo struct {
+	Name    string   `json:"name"`
+	Columns []string `json:"columns"`
+	Unique  bool     `json:"unique"`
+	Primary bool     `json:"primary"`
+}
+
+// ForeignKeyInfo mendeskripsikan foreign key constraint
+type ForeignKeyInfo struct {
+	Name       string   `json:"name"`
+	Columns    []string `json:"columns"`
+	RefTable   string   `json:"references"`
+	RefColumns []string `json:"on"`
+	OnDelete   string   `json:"on_delete"`
+	OnUpdate   string   `json:"on_update"`
+}
+
+// TableSchema adalah struktur lengkap satu tabel
+type TableSchema struct {
+	Name        string           `json:"name"`
+	Columns     []ColumnInfo     `json:"columns"`
+	Indexes     []IndexInfo      `json:"indexes"`
+	ForeignKeys []ForeignKeyInfo `json:"foreign_keys"`
+}
+
+// Column mencari kolom berdasarkan nama (case-insensitive)
+func (t *TableSchema) Column(name string) (ColumnInfo, bool) {
+	for _, c := range t.Columns {
+		if strings.EqualFold(c.Name, name) {
+			return c, true
+		}
+	}
+	return ColumnInfo{}, false
+}
+
+// ListTables mengembalikan nama semua tabel di koneksi (tanpa tabel internal SQLite)
+func ListTables(ctx context.Context, db Querier, dialect Dialect) ([]string, error) {
+	var query string
+	switch dialect.Name() {
+	case "sqlite":
+		query = "SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name"
+	case "postgres":
+		query = "SELECT tablename FROM pg_tables WHERE schemaname = current_schema() ORDER BY tablename"
+	case "sqlserver":
+		query = "SELECT TABLE_NAME FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_TYPE = 'BASE TABLE' ORDER BY TABLE_NAME"
+	default:
+		query = "SELECT TABLE_NAME FROM information_schema.TABLES WHERE TABLE_SCHEMA = DATABASE() AND TABLE_TYPE = 'BASE TABLE' ORDER BY TABLE_NAME"
+	}
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		tables = append(tables, name)
+	}
+	return tables, rows.Err()
+}
+
+// DescribeTable membaca kolom, index dan foreign key sebuah tabel dari katalog dialect
+func DescribeTable(ctx context.Context, db Querier, dialect Dialect, table string) (*TableSchema, error) {
+	var ts *TableSchema
+	var err error
+	switch dialect.Name() {
+	case "sqlite":
+		ts, err = describeSQLite(ctx, db, table)
+	case "postgres":
+		ts, err = describePostgres(ctx, db, table)
+	case "sqlserver":
+		ts, err = describeSQLServer(ctx, db, table)
+	default:
+		ts, err = describeMySQL(ctx, db, table)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(ts.Columns) == 0 {
+		return nil, fmt.Errorf("table '%s' not found", table)
+	}
+	return ts, nil
+}
+
+// appendIndexColumn menambahkan kolom ke index bernama, membuat index baru jika belum ada
+func appendIndexColumn(ts *TableSchema, name, column string, unique, primary bool) {
+	for i := range ts.Indexes {
+		if ts.Indexes[i].Name == name {
+			ts.Indexes[i].Columns = append(ts.Indexes[i].Columns, column)
+			return
+		}
+	}
+	ts.Indexes = append(ts.Indexes, IndexInfo{Name: name, Columns: []string{column}, Unique: unique, Primary: primary})
+}
+
+// appendForeignKeyColumn menambahkan pasangan kolom ke foreign key bernama
+func appendForeignKeyColumn(ts *TableSchema, fk ForeignKeyInfo, column, refColumn string) {
+	for i := range ts.ForeignKeys {
+		if ts.ForeignKeys[i].Name == fk.Name {
+			ts.ForeignKeys[i].Columns = append(ts.ForeignKeys[i].Columns, column)
+			ts.ForeignKeys[i].RefColumns = append(ts.ForeignKeys[i].RefColumns, refColumn)
+			return
+		}
+	}
+	fk.Columns = []string{column}
+	fk.RefColumns = []string{refColumn}
+	ts.ForeignKeys = append(ts.ForeignKeys, fk)
+}
+
+func markPrimaryKey(ts *TableSchema) {
+	for _, idx := range ts.Indexes {
+		if !idx.Primary {
+			continue
+		}
+		for _, col := range idx.Columns {
+			for i := range ts.Columns {
+				if ts.Columns[i].Name == col {
+					ts.Columns[i].PrimaryKey = true
+				}
+			}
+		}
+	}
+}
+
+func nullStringPtr(s sql.NullString) *string {
+	if !s.Valid {
+		return nil
+	}
+	v := s.String
+	return &v
+}
+
+// --- SQLite: sqlite_master + PRAGMA ---
+
+func describeSQLite(ctx context.Context, db Querier, table string) (*TableSchema, error) {
+	ts := &TableSchema{Name: table}
+	q := SQLiteDialect{}.QuoteIdentifier(table)
+
+	rows, err := db.QueryContext(ctx, "PRAGMA table_info("+q+")")
+	if err != nil {
+		return nil, err
+	}
+	var pkCols []string
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, colType string
+		var dflt sql.NullString
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dflt, &pk); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ts.Columns = append(ts.Columns, ColumnInfo{
+			Name:       name,
+			Type:       colType,
+			Nullable:   notNull == 0 && pk == 0,
+			Default:    nullStringPtr(dflt),
+			PrimaryKey: pk > 0,
+		})
+		if pk > 0 {
+			pkCols = append(pkCols, name)
+		}
+	}
+	rows.Close()
+
+	// INTEGER PRIMARY KEY adalah alias rowid (auto increment)
+	if len(pkCols) == 1 {
+		for i := range ts.Columns {
+			if ts.Columns[i].Name == pkCols[0] && strings.EqualFold(ts.Columns[i].Type, "INTEGER") {
+				ts.Columns[i].AutoIncrement = true
+			}
+		}
+	}
+	if len(pkCols) > 0 {
+		ts.Indexes = append(ts.Indexes, IndexInfo{Name: "PRIMARY", Columns: pkCols, Unique: true, Primary: true})
+	}
+
+	type indexEntry struct {
+		name   string
+		unique bool
+		origin string
+	}
+	var entries []indexEntry
+	rows, err = db.QueryContext(ctx, "PRAGMA index_list("+q+")")
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var seq, unique, partial int
+		var e indexEntry
+		if err := rows.Scan(&seq, &e.name, &unique, &e.origin, &partial); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		e.unique = unique == 1
+		entries = append(entries, e)
+	}
+	rows.Close()
+
+	for _, e := range entries {
+		if e.origin == "pk" {
+			continue
+		}
+		idxRows, err := db.QueryContext(ctx, "PRAGMA index_info("+SQLiteDialect{}.QuoteIdentifier(e.name)+")")
+		if err != nil {
+			return nil, err
+		}
+		idx := IndexInfo{Name: e.name, Unique: e.unique}
+		for idxRows.Next() {
+			var seqno, cid int
+			var col sql.NullString
+			if err := idxRows.Scan(&seqno, &cid, &col); err != nil {
+				idxRows.Close()
+				return nil, err
+			}
+			idx.Columns = append(idx.Columns, col.String)
+		}
+		idxRows.Close()
+
+		// Nama sqlite_autoindex_* dicadangkan SQLite, gunakan nama konvensi
+		if strings.HasPrefix(idx.Name, "sqlite_autoindex_") {
+			idx.Name = strings.ToLower(table + "_" + strings.Join(idx.Columns, "_") + "_unique")
+		}
+		ts.Indexes = append(ts.Indexes, idx)
+	}
+
+	type fkEntry struct {
+		id, seq int
+		fk      ForeignKeyInfo
+		from    string
+		to      sql.NullString
+	}
+	var fkEntries []fkEntry
+	rows, err = db.QueryContext(ctx, "PRAGMA foreign_key_list("+q+")")
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var e fkEntry
+		var match string
+		if err := rows.Scan(&e.id, &e.seq, &e.fk.RefTable, &e.from, &e.to, &e.fk.OnUpdate, &e.fk.OnDelete, &match); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		fkEntries = append(fkEntries, e)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	fkNames := make(map[int]string)
+	parentKeys := make(map[string][]string)
+	for _, e := range fkEntries {
+		// SQLite tidak menyimpan nama constraint, gunakan nama konvensi dari kolom pertama
+		if _, ok := fkNames[e.id]; !ok {
+			fkNames[e.id] = strings.ToLower(table + "_" + e.from + "_foreign")
+		}
+		e.fk.Name = fkNames[e.id]
+
+		// Kolom tujuan kosong berarti foreign key merujuk primary key tabel induk
+		refCol := e.to.String
+		if !e.to.Valid {
+			keys, ok := parentKeys[e.fk.RefTable]
+			if !ok {
+				if keys, err = sqlitePrimaryKey(ctx, db, e.fk.RefTable); err != nil {
+					return nil, err
+				}
+				parentKeys[e.fk.RefTable] = keys
+			}
+			refCol = "id"
+			if e.seq < len(keys) {
+				refCol = keys[e.seq]
+			}
+		}
+		appendForeignKeyColumn(ts, e.fk, e.from, refCol)
+	}
+	return ts, nil
+}
+
+// sqlitePrimaryKey mengembalikan kolom primary key tabel sesuai urutan di constraint
+func sqlitePrimaryKey(ctx context.Context, db Querier, table string) ([]string, error) {
+	rows, err := db.QueryContext(ctx, "SELECT name FROM pragma_table_info(?) WHERE pk > 0 ORDER BY pk", table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var cols []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		cols = append(cols, name)
+	}
+	return cols, rows.Err()
+}
+
+// --- PostgreSQL: pg_catalog ---
+
+func describePostgres(ctx context.Context, db Querier, table string) (*TableSchema, error) {
+	ts := &TableSchema{Name: table}
+	regclass := "(quote_ident(current_schema()) || '.' || quote_ident($1))::regclass"
+
+	rows, err := db.QueryContext(ctx, `
+	SELECT a.attname, format_type(a.atttypid, a.atttypmod), NOT a.attnotnull,
+		pg_get_expr(d.adbin, d.adrelid), a.attidentity <> ''
+	FROM pg_attribute a
+	LEFT JOIN pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+	WHERE a.attrelid = `+regclass+` AND a.attnum > 0 AND NOT a.attisdropped
+	ORDER BY a.attnum`, table)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var col ColumnInfo
+		var dflt sql.NullString
+		var identity bool
+		if err := rows.Scan(&col.Name, &col.Type, &col.Nullable, &dflt, &identity); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		col.Default = nullStringPtr(dflt)
+		col.AutoIncrement = identity || strings.HasPrefix(dflt.String, "nextval(")
+		ts.Columns = append(ts.Columns, col)
+	}
+	rows.Close()
+
+	rows, err = db.QueryContext(ctx, `
+	SELECT i.relname, ix.indisunique, ix.indisprimary, a.attname
+	FROM pg_index ix
+	JOIN pg_class i ON i.oid = ix.indexrelid
+	JOIN LATERAL unnest(ix.indkey) WITH ORDINALITY AS k(attnum, ord) ON true
+	JOIN pg_attribute a ON a.attrelid = ix.indrelid AND a.attnum = k.attnum
+	WHERE ix.indrelid = `+regclass+`
+	ORDER BY i.relname, k.ord`, table)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var name, col string
+		var unique, primary bool
+		if err := rows.Scan(&name, &unique, &primary, &col); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		appendIndexColumn(ts, name, col, unique, primary)
+	}
+	rows.Close()
+	markPrimaryKey(ts)
+
+	rows, err = db.QueryContext(ctx, `
+	SELECT con.conname, a.attname, ft.relname, fa.attname, con.confdeltype, con.confupdtype
+	FROM pg_constraint con
+	JOIN LATERAL unnest(con.conkey, con.confkey) WITH ORDINALITY AS k(col, fcol, ord) ON true
+	JOIN pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = k.col
+	JOIN pg_class ft ON ft.oid = con.confrelid
+	JOIN pg_attribute fa ON fa.attrelid = con.confrelid AND fa.attnum = k.fcol
+	WHERE con.contype = 'f' AND con.conrelid = `+regclass+`
+	ORDER BY con.conname, k.ord`, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name, col, refTable, refCol, onDelete, onUpdate string
+		if err := rows.Scan(&name, &col, &refTable, &refCol, &onDelete, &onUpdate); err != nil {
+			return nil, err
+		}
+		fk := ForeignKeyInfo{Name: name, RefTable: refTable, OnDelete: pgAction(onDelete), OnUpdate: pgAction(onUpdate)}
+		appendForeignKeyColumn(ts, fk, col, refCol)
+	}
+	return ts, rows.Err()
+}
+
+func pgAction(code string) string {
+	switch code {
+	case "c":
+		return "CASCADE"
+	case "n":
+		return "SET NULL"
+	case "d":
+		return "SET DEFAULT"
+	case "r":
+		return "RESTRICT"
+	default:
+		return "NO ACTION"
+	}
+}
+
+// --- MySQL: information_schema ---
+
+func describeMySQL(ctx context.Context, db Querier, table string) (*TableSchema, error) {
+	ts := &TableSchema{Name: table}
+
+	rows, err := db.QueryContext(ctx, `
+	SELECT COLUMN_NAME, COLUMN_TYPE, IS_NULLABLE, COLUMN_DEFAULT, EXTRA
+	FROM information_schema.COLUMNS
+	WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?
+	ORDER BY ORDINAL_POSITION`, table)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var col ColumnInfo
+		var nullable, extra string
+		var dflt sql.NullString
+		if err := rows.Scan(&col.Name, &col.Type, &nullable, &dflt, &extra); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		col.Nullable = nullable == "YES"
+		col.Default = mysqlDefault(dflt, extra)
+		col.AutoIncrement = strings.Contains(strings.ToLower(extra), "auto_increment")
+		ts.Columns = append(ts.Columns, col)
+	}
+	rows.Close()
+
+	rows, err = db.QueryContext(ctx, `
+	SELECT INDEX_NAME, NON_UNIQUE, COLUMN_NAME
+	FROM information_schema.STATISTICS
+	WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?
+	ORDER BY INDEX_NAME, SEQ_IN_INDEX`, table)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var name, col string
+		var nonUnique int
+		if err := rows.Scan(&name, &nonUnique, &col); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		appendIndexColumn(ts, name, col, nonUnique == 0, name == "PRIMARY")
+	}
+	rows.Close()
+	markPrimaryKey(ts)
+
+	rows, err = db.QueryContext(ctx, `
+	SELECT k.CONSTRAINT_NAME, k.COLUMN_NAME, k.REFERENCED_TABLE_NAME, k.REFERENCED_COLUMN_NAME, r.DELETE_RULE, r.UPDATE_RULE
+	FROM information_schema.KEY_COLUMN_USAGE k
+	JOIN information_schema.REFERENTIAL_CONSTRAINTS r
+		ON r.CONSTRAINT_SCHEMA = k.CONSTRAINT_SCHEMA AND r.CONSTRAINT_NAME = k.CONSTRAINT_NAME
+	WHERE k.TABLE_SCHEMA = DATABASE() AND k.TABLE_NAME = ? AND k.REFERENCED_TABLE_NAME IS NOT NULL
+	ORDER BY k.CONSTRAINT_NAME, k.ORDINAL_POSITION`, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var fk ForeignKeyInfo
+		var col, refCol string
+		if err := rows.Scan(&fk.Name, &col, &fk.RefTable, &refCol, &fk.OnDelete, &fk.OnUpdate); err != nil {
+			return nil, err
+		}
+		appendForeignKeyColumn(ts, fk, col, refCol)
+	}
+	return ts, rows.Err()
+}
+
+// mysqlDefault mengubah COLUMN_DEFAULT menjadi ekspresi SQL. MySQL menyimpan
+// literal string tanpa kutip, sedangkan ekspresi ditandai DEFAULT_GENERATED.
+func mysqlDefault(dflt sql.NullString, extra string) *string {
+	if !dflt.Valid {
+		return nil
+	}
+	v := dflt.String
+	upper := strings.ToUpper(v)
+	_, numErr := strconv.ParseFloat(v, 64)
+	isExpr := strings.Contains(strings.ToUpper(extra), "DEFAULT_GENERATED") ||
+		strings.HasPrefix(upper, "CURRENT_TIMESTAMP") || upper == "NULL" ||
+		(strings.HasPrefix(v, "'") && strings.HasSuffix(v, "'"))
+	if numErr != nil && !isExpr {
+		v = "'" + strings.ReplaceAll(v, "'", "''") + "'"
+	}
+	return &v
+}
+
+// --- SQL Server: sys.* ---
+
+func describeSQLServer(ctx context.Context, db Querier, table string) (*TableSchema, error) {
+	ts := &TableSchema{Name: table}
+
+	rows, err := db.QueryContext(ctx, `
+	SELECT c.name, TYPE_NAME(c.user_type_id), c.max_length, c.precision, c.scale, c.is_nullable, c.is_identity,
+		OBJECT_DEFINITION(c.default_object_id)
+	FROM sys.columns c
+	WHERE c.object_id = OBJECT_ID(@p1)
+	ORDER BY c.column_id`, table)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var col ColumnInfo
+		var typeName string
+		var maxLength, precision, scale int
+		var dflt sql.NullString
+		if err := rows.Scan(&col.Name, &typeName, &maxLength, &precision, &scale, &col.Nullable, &col.AutoIncrement, &dflt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		col.Type = sqlServerType(typeName, maxLength, precision, scale)
+		col.Default = nullStringPtr(dflt)
+		ts.Columns = append(ts.Columns, col)
+	}
+	rows.Close()
+
+	rows, err = db.QueryContext(ctx, `
+	SELECT i.name, i.is_unique, i.is_primary_key, c.name
+	FROM sys.indexes i
+	JOIN sys.index_columns ic ON ic.object_id = i.object_id AND ic.index_id = i.index_id
+	JOIN sys.columns c ON c.object_id = ic.object_id AND c.column_id = ic.column_id
+	WHERE i.object_id = OBJECT_ID(@p1) AND i.name IS NOT NULL AND ic.is_included_column = 0
+	ORDER BY i.name, ic.key_ordinal`, table)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var name, col string
+		var unique, primary bool
+		if err := rows.Scan(&name, &unique, &primary, &col); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		appendIndexColumn(ts, name, col, unique, primary)
+	}
+	rows.Close()
+	markPrimaryKey(ts)
+
+	rows, err = db.QueryContext(ctx, `
+	SELECT fk.name, pc.name, OBJECT_NAME(fk.referenced_object_id), rc.name,
+		fk.delete_referential_action_desc, fk.update_referential_action_desc
+	FROM sys.foreign_keys fk
+	JOIN sys.foreign_key_columns fkc ON fkc.constraint_object_id = fk.object_id
+	JOIN sys.columns pc ON pc.object_id = fkc.parent_object_id AND pc.column_id = fkc.parent_column_id
+	JOIN sys.columns rc ON rc.object_id = fkc.referenced_object_id AND rc.column_id = fkc.referenced_column_id
+	WHERE fk.parent_object_id = OBJECT_ID(@p1)
+	ORDER BY fk.name, fkc.constraint_column_id`, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var fk ForeignKeyInfo
+		var col, refCol string
+		if err := rows.Scan(&fk.Name, &col, &fk.RefTable, &refCol, &fk.OnDelete, &fk.OnUpdate); err != nil {
+			return nil, err
+		}
+		fk.OnDelete = strings.ReplaceAll(fk.OnDelete, "_", " ")
+		fk.OnUpdate = strings.ReplaceAll(fk.OnUpdate, "_", " ")
+		appendForeignKeyColumn(ts, fk, col, refCol)
+	}
+	return ts, rows.Err()
+}
+
+func sqlServerType(name string, maxLength, precision, scale int) string {
+	switch strings.ToLower(name) {
+	case "nvarchar", "nchar":
+		if maxLength == -1 {
+			return name + "(MAX)"
+		}
+		return fmt.Sprintf("%s(%d)", name, maxLength/2)
+	case "varchar", "char", "varbinary", "binary":
+		if maxLength == -1 {
+			return name + "(MAX)"
+		}
+		return fmt.Sprintf("%s(%d)", name, maxLength)
+	case "decimal", "numeric":
+		return fmt.Sprintf("%s(%d,%d)", name, precision, scale)
+	}
+	return name
+}
+
+// --- Pemetaan ke tipe Schema Builder ---
+
+var typeArgsPattern = regexp.MustCompile(`\(([^)]*)\)`)
+
+// BuilderType memetakan tipe asli database ke tipe kolom Schema Builder
+// (id, string, integer, timestamp, boolean, text, decimal, date, json).
+// Tipe yang tidak dikenal dipetakan ke "text".
+func (c ColumnInfo) BuilderType() (colType string, limit, precision, scale int) {
+	t := strings.ToLower(strings.TrimSpace(c.Type))
+	base := t
+	if i := strings.IndexAny(base, "( "); i >= 0 {
+		base = base[:i]
+	}
+
+	var args []int
+	if m := typeArgsPattern.FindStringSubmatch(t); m != nil {
+		for _, part := range strings.Split(m[1], ",") {
+			n, err := strconv.Atoi(strings.TrimSpace(part))
+			if err == nil {
+				args = append(args, n)
+			}
+		}
+	}
+
+	if c.PrimaryKey && c.AutoIncrement {
+		return "id", 0, 0, 0
+	}
+
+	switch {
+	case t == "tinyint(1)" || base == "bit" || base == "boolean" || base == "bool":
+		return "boolean", 0, 0, 0
+	case base == "int" || base == "integer" || base == "bigint" || base == "smallint" || base == "tinyint" ||
+		base == "mediumint" || base == "serial" || base == "bigserial" || base == "int4" || base == "int8":
+		return "integer", 0, 0, 0
+	case base == "varchar" || base == "nvarchar" || base == "char" || base == "nchar" || base == "character" ||
+		strings.HasPrefix(t, "character varying"):
+		if strings.Contains(t, "max") {
+			return "text", 0, 0, 0
+		}
+		if len(args) > 0 {
+			limit = args[0]
+		}
+		return "string", limit, 0, 0
+	case base == "decimal" || base == "numeric":
+		if len(args) > 0 {
+			precision = args[0]
+		}
+		if len(args) > 1 {
+			scale = args[1]
+		}
+		return "decimal", 0, precision, scale
+	case base == "timestamp" || base == "datetime" || base == "datetime2" || base == "datetimeoffset":
+		return "timestamp", 0, 0, 0
+	case base == "date":
+		return "date", 0, 0, 0
+	case base == "json" || base == "jsonb":
+		return "json", 0, 0, 0
+	}
+	return "text", 0, 0, 0
+}
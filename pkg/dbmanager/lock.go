@@ -0,0 +1,244 @@
+package dbmanager
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"time"
+)
+
+// ErrLockTimeout dikembalikan jika lock tidak bisa didapat dalam batas waktu tunggu
+var ErrLockTimeout = fmt.Errorf("timed out waiting for lock")
+
+// SQLiteLockTTL adalah umur baris lock SQLite. SQLite tidak punya lock berbasis sesi,
+// jadi pemegang lock memperpanjang expires_at setiap TTL/3 selama lock dipegang; lock
+// dari proses yang crash kadaluarsa setelah TTL ini.
+var SQLiteLockTTL = time.Minute
+
+// Locker diimplementasikan oleh dialect yang mendukung named application lock
+type Locker interface {
+	AcquireLock(ctx context.Context, db *sql.DB, name string, wait time.Duration) (*Lock, error)
+}
+
+// Lock adalah named lock yang sedang dipegang. Panggil Release untuk melepasnya.
+type Lock struct {
+	Name    string
+	release func() error
+}
+
+// Release melepaskan lock
+func (l *Lock) Release() error {
+	if l == nil || l.release == nil {
+		return nil
+	}
+	err := l.release()
+	l.release = nil
+	return err
+}
+
+// AcquireLock mengambil named lock melalui dialect koneksi
+func AcquireLock(ctx context.Context, db *sql.DB, dialect Dialect, name string, wait time.Duration) (*Lock, error) {
+	locker, ok := dialect.(Locker)
+	if !ok {
+		return nil, fmt.Errorf("dialect '%s' does not support locking", dialect.Name())
+	}
+	return locker.AcquireLock(ctx, db, name, wait)
+}
+
+// SupportsTransactionalDDL melaporkan apakah DDL dapat di-rollback di dalam transaksi
+func SupportsTransactionalDDL(dialect Dialect) bool {
+	if d, ok := dialect.(interface{ TransactionalDDL() bool }); ok {
+		return d.TransactionalDDL()
+	}
+	return false
+}
+
+// --- MySQL: GET_LOCK / RELEASE_LOCK (session scoped) ---
+
+func (d MySQLDialect) TransactionalDDL() bool { return false }
+
+func (d MySQLDialect) AcquireLock(ctx context.Context, db *sql.DB, name string, wait time.Duration) (*Lock, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var result sql.NullInt64
+	if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", name, int(wait.Seconds())).Scan(&result); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if !result.Valid || result.Int64 != 1 {
+		conn.Close()
+		return nil, ErrLockTimeout
+	}
+
+	return &Lock{Name: name, release: func() error {
+		defer conn.Close()
+		_, err := conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", name)
+		return err
+	}}, nil
+}
+
+// --- PostgreSQL: pg_advisory_lock (session scoped) ---
+
+func (d PostgreSQLDialect) TransactionalDDL() bool { return true }
+
+func (d PostgreSQLDialect) AcquireLock(ctx context.Context, db *sql.DB, name string, wait time.Duration) (*Lock, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	key := advisoryKey(name)
+	err = pollLock(ctx, wait, func() (bool, error) {
+		var ok bool
+		err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", key).Scan(&ok)
+		return ok, err
+	})
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return &Lock{Name: name, release: func() error {
+		defer conn.Close()
+		_, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", key)
+		return err
+	}}, nil
+}
+
+// --- SQL Server: sp_getapplock (session scoped) ---
+
+func (d SQLServerDialect) TransactionalDDL() bool { return true }
+
+func (d SQLServerDialect) AcquireLock(ctx context.Context, db *sql.DB, name string, wait time.Duration) (*Lock, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+	DECLARE @result INT;
+	EXEC @result = sp_getapplock @Resource = @p1, @LockMode = 'Exclusive', @LockOwner = 'Session', @LockTimeout = @p2;
+	SELECT @result;`
+
+	var result int
+	if err := conn.QueryRowContext(ctx, query, name, int(wait.Milliseconds())).Scan(&result); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if result < 0 {
+		conn.Close()
+		return nil, ErrLockTimeout
+	}
+
+	return &Lock{Name: name, release: func() error {
+		defer conn.Close()
+		_, err := conn.ExecContext(context.Background(), "EXEC sp_releaseapplock @Resource = @p1, @LockOwner = 'Session'", name)
+		return err
+	}}, nil
+}
+
+// --- SQLite: baris lock di tabel zeno_locks ---
+
+func (d SQLiteDialect) TransactionalDDL() bool { return true }
+
+func (d SQLiteDialect) AcquireLock(ctx context.Context, db *sql.DB, name string, wait time.Duration) (*Lock, error) {
+	queryInit := `
+	CREATE TABLE IF NOT EXISTS zeno_locks (
+		name VARCHAR(255) PRIMARY KEY,
+		owner VARCHAR(255),
+		expires_at INTEGER
+	);`
+	if _, err := db.ExecContext(ctx, queryInit); err != nil {
+		return nil, fmt.Errorf("failed to init lock table: %w", err)
+	}
+
+	owner := lockOwner()
+	err := pollLock(ctx, wait, func() (bool, error) {
+		now := time.Now().Unix()
+		// Bersihkan lock kadaluarsa dari proses yang crash
+		if _, err := db.ExecContext(ctx, "DELETE FROM zeno_locks WHERE name = ? AND expires_at < ?", name, now); err != nil {
+			return false, err
+		}
+		res, err := db.ExecContext(ctx, "INSERT OR IGNORE INTO zeno_locks (name, owner, expires_at) VALUES (?, ?, ?)",
+			name, owner, now+int64(SQLiteLockTTL.Seconds()))
+		if err != nil {
+			return false, err
+		}
+		affected, _ := res.RowsAffected()
+		return affected == 1, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Heartbeat: perpanjang lock selama masih dipegang, agar operasi yang lebih lama
+	// dari TTL (misalnya migrasi besar) tidak kehilangan lock-nya
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(SQLiteLockTTL / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				db.ExecContext(context.Background(), "UPDATE zeno_locks SET expires_at = ? WHERE name = ? AND owner = ?",
+					time.Now().Add(SQLiteLockTTL).Unix(), name, owner)
+			}
+		}
+	}()
+
+	return &Lock{Name: name, release: func() error {
+		close(stop)
+		<-stopped
+		_, err := db.ExecContext(context.Background(), "DELETE FROM zeno_locks WHERE name = ? AND owner = ?", name, owner)
+		return err
+	}}, nil
+}
+
+// pollLock mencoba try() berulang kali sampai berhasil atau waktu tunggu habis
+func pollLock(ctx context.Context, wait time.Duration, try func() (bool, error)) error {
+	deadline := time.Now().Add(wait)
+	for {
+		ok, err := try()
+		if err != nil {
+			return err
+		}
+		if ok {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return ErrLockTimeout
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
+
+// advisoryKey mengubah nama lock menjadi key int64 untuk pg_advisory_lock
+func advisoryKey(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	return int64(h.Sum64())
+}
+
+// lockOwner membuat identitas unik pemegang lock (host:pid:random)
+func lockOwner() string {
+	host, _ := os.Hostname()
+	b := make([]byte, 4)
+	rand.Read(b)
+	return fmt.Sprintf("%s:%d:%s", host, os.Getpid(), hex.EncodeToString(b))
+}
@@ -0,0 +1,66 @@
+package dbmanager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSQLiteLock(t *testing.T) {
+	dbMgr := NewDBManager()
+	if err := dbMgr.AddConnection("default", "sqlite", ":memory:", 1, 1); err != nil {
+		t.Fatalf("Failed to create in-memory db: %v", err)
+	}
+	t.Cleanup(func() { dbMgr.Close() })
+	db, dialect := dbMgr.GetDefault()
+	ctx := context.Background()
+
+	ttl := SQLiteLockTTL
+	SQLiteLockTTL = 2 * time.Second
+	t.Cleanup(func() { SQLiteLockTTL = ttl })
+
+	lock, err := AcquireLock(ctx, db, dialect, "migrate", 0)
+	assert.NoError(t, err)
+	_, err = AcquireLock(ctx, db, dialect, "migrate", 0)
+	assert.ErrorIs(t, err, ErrLockTimeout)
+
+	// Lock lain tidak terpengaruh
+	other, err := AcquireLock(ctx, db, dialect, "schedule", 0)
+	assert.NoError(t, err)
+	assert.NoError(t, other.Release())
+
+	// Heartbeat memperpanjang lock yang dipegang lebih lama dari TTL
+	time.Sleep(3500 * time.Millisecond)
+	_, err = AcquireLock(ctx, db, dialect, "migrate", 0)
+	assert.ErrorIs(t, err, ErrLockTimeout, "a held lock must not expire")
+
+	// Setelah Release, heartbeat berhenti dan lock bisa diambil lagi
+	assert.NoError(t, lock.Release())
+	assert.NoError(t, lock.Release())
+	lock, err = AcquireLock(ctx, db, dialect, "migrate", 0)
+	assert.NoError(t, err)
+	assert.NoError(t, lock.Release())
+	var n int
+	assert.NoError(t, db.QueryRow("SELECT COUNT(*) FROM zeno_locks").Scan(&n))
+	assert.Equal(t, 0, n)
+
+	// Lock dari proses yang crash (tidak diperpanjang) kadaluarsa
+	_, err = db.Exec("INSERT INTO zeno_locks (name, owner, expires_at) VALUES ('migrate', 'crashed', ?)", time.Now().Add(-time.Second).Unix())
+	assert.NoError(t, err)
+	lock, err = AcquireLock(ctx, db, dialect, "migrate", 0)
+	assert.NoError(t, err)
+	assert.NoError(t, lock.Release())
+
+	// Menunggu lock yang sedang dipegang sampai dilepas
+	lock, err = AcquireLock(ctx, db, dialect, "migrate", 0)
+	assert.NoError(t, err)
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		lock.Release()
+	}()
+	waited, err := AcquireLock(ctx, db, dialect, "migrate", 5*time.Second)
+	assert.NoError(t, err)
+	assert.NoError(t, waited.Release())
+}
@@ -2,83 +2,324 @@ package migrator
 
 import (
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 	"fmt"
 	"log/slog"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 	"github.com/nextcore/zenoengine/pkg/dbmanager"
 	"github.com/nextcore/zeno-go/pkg/engine"
 )
 
+// DefaultLockTimeout adalah waktu tunggu default untuk migration lock
+const DefaultLockTimeout = time.Minute
+
+// lockName adalah nama lock yang dipakai bersama oleh semua proses migrasi
+const lockName = "zeno_migrations"
+
 type Migrator struct {
-	Engine  *engine.Engine
-	DB      *sql.DB
-	Dialect dbmanager.Dialect
-	Dir     string
+	Engine     *engine.Engine
+	DB         *sql.DB
+	Dialect    dbmanager.Dialect
+	Dir        string
+	Connection string
+
+	// LockTimeout adalah batas waktu menunggu migration lock dari proses lain
+	LockTimeout time.Duration
+
+	// SchemaFile adalah schema dump (zeno schema:dump) yang dimuat sebelum migrasi
+	// bila database belum pernah dimigrasi. Kosong berarti tidak ada.
+	SchemaFile string
+
+	// Pretend mencatat SQL yang akan dijalankan ke Statements tanpa menyentuh database
+	Pretend    bool
+	Statements []Statement
+}
+
+// Statement is a SQL statement captured while running in pretend mode
+type Statement struct {
+	File       string
+	Connection string
+	SQL        string
+	Args       []interface{}
+}
+
+// MigrationStatus describes a migration file and whether it has been applied
+type MigrationStatus struct {
+	File      string
+	Applied   bool
+	Batch     int
+	AppliedAt string
+	Checksum  string
+
+	// Drift flags
+	Modified   bool // File changed after it was applied
+	Missing    bool // Applied, but the file no longer exists
+	OutOfOrder bool // Applied or pending in a different order than the file names
+}
+
+// Drifted reports whether the migration no longer matches what was applied
+func (s MigrationStatus) Drifted() bool {
+	return s.Modified || s.Missing || s.OutOfOrder
 }
 
 func New(eng *engine.Engine, dbMgr *dbmanager.DBManager, dir string) *Migrator {
 	db, dialect := dbMgr.GetDefault()
 	return &Migrator{
-		Engine:  eng,
-		DB:      db,
-		Dialect: dialect,
-		Dir:     dir,
+		Engine:      eng,
+		DB:          db,
+		Dialect:     dialect,
+		Dir:         dir,
+		Connection:  "default",
+		LockTimeout: DefaultLockTimeout,
 	}
 }
 
-func (m *Migrator) Run() error {
-	ctx := context.Background()
+// NewForConnection membuat Migrator untuk koneksi bernama di DBManager
+func NewForConnection(eng *engine.Engine, dbMgr *dbmanager.DBManager, connName, dir string) (*Migrator, error) {
+	db := dbMgr.GetConnection(connName)
+	if db == nil {
+		return nil, fmt.Errorf("database connection '%s' not found", connName)
+	}
+	return &Migrator{
+		Engine:      eng,
+		DB:          db,
+		Dialect:     dbMgr.GetDialect(connName),
+		Dir:         dir,
+		Connection:  connName,
+		LockTimeout: DefaultLockTimeout,
+	}, nil
+}
 
-	// 1. Pastikan tabel tracking ada
-	queryInit := `
+// TrackingTableSQL mengembalikan DDL tabel schema_migrations untuk dialect
+func TrackingTableSQL(dialect string) string {
+	switch dialect {
+	case "sqlserver":
+		return `
+	IF OBJECT_ID(N'schema_migrations', N'U') IS NULL
+	CREATE TABLE schema_migrations (
+		version NVARCHAR(255) PRIMARY KEY,
+		batch INT,
+		checksum NVARCHAR(64),
+		applied_at DATETIME2 DEFAULT CURRENT_TIMESTAMP
+	);`
+	default:
+		return `
 	CREATE TABLE IF NOT EXISTS schema_migrations (
 		version VARCHAR(255) PRIMARY KEY,
 		batch INTEGER,
+		checksum VARCHAR(64),
 		applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 	);`
-	if _, err := m.DB.ExecContext(ctx, queryInit); err != nil {
+	}
+}
+
+// ensureTable membuat tabel schema_migrations sesuai dialect
+func (m *Migrator) ensureTable(ctx context.Context) error {
+	if _, err := m.DB.ExecContext(ctx, TrackingTableSQL(m.Dialect.Name())); err != nil {
 		return fmt.Errorf("failed to init migration table: %w", err)
 	}
 
-	// 2. Ambil migrasi yang sudah diaplikasikan
+	// Tabel dari versi lama belum punya kolom checksum
+	rows, err := m.DB.QueryContext(ctx, "SELECT checksum FROM schema_migrations WHERE 1 = 0")
+	if err == nil {
+		rows.Close()
+		return nil
+	}
+
+	queryAlter := "ALTER TABLE schema_migrations ADD COLUMN checksum VARCHAR(64)"
+	if m.Dialect.Name() == "sqlserver" {
+		queryAlter = "ALTER TABLE schema_migrations ADD checksum NVARCHAR(64)"
+	}
+	if _, err := m.DB.ExecContext(ctx, queryAlter); err != nil {
+		return fmt.Errorf("failed to add checksum column: %w", err)
+	}
+	return nil
+}
+
+// checksum menghitung hash SHA-256 isi file migrasi. Line ending dinormalisasi
+// agar checkout CRLF/LF tidak terdeteksi sebagai perubahan.
+func (m *Migrator) checksum(filename string) (string, error) {
+	content, err := os.ReadFile(filepath.Join(m.Dir, filename))
+	if err != nil {
+		return "", err
+	}
+	content = []byte(strings.ReplaceAll(string(content), "\r\n", "\n"))
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// files mengembalikan daftar file migrasi .zl yang sudah diurutkan
+func (m *Migrator) files() ([]string, error) {
+	entries, err := os.ReadDir(m.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migration dir: %w", err)
+	}
+
+	var files []string
+	for _, f := range entries {
+		if !f.IsDir() && strings.HasSuffix(f.Name(), ".zl") {
+			files = append(files, f.Name())
+		}
+	}
+	sort.Strings(files) // Pastikan urut (001, 002, ...)
+	return files, nil
+}
+
+// newScope menyiapkan scope eksekusi untuk satu file migrasi
+func (m *Migrator) newScope(filename, direction string) *engine.Scope {
+	scope := engine.NewScope(nil)
+	scope.Set("migration_ver", filename)
+	scope.Set("_migration_db", m.Connection)
+	if direction == "down" {
+		scope.Set("_migration_direction", "down") // Set direction to down!
+	}
+	if m.Pretend {
+		scope.Set("_migration_pretend", func(conn, query string, args []interface{}) {
+			m.Statements = append(m.Statements, Statement{
+				File:       filename,
+				Connection: conn,
+				SQL:        query,
+				Args:       args,
+			})
+		})
+	}
+	return scope
+}
+
+// appliedVersions mengembalikan migrasi yang sudah diaplikasikan dan batch terakhir
+func (m *Migrator) appliedVersions(ctx context.Context) (map[string]bool, int, error) {
+	applied := make(map[string]bool)
+
 	rows, err := m.DB.QueryContext(ctx, "SELECT version FROM schema_migrations")
 	if err != nil {
-		return fmt.Errorf("failed to fetch applied migrations: %w", err)
+		if m.Pretend {
+			return applied, 0, nil // Tabel tracking belum ada, anggap semua pending
+		}
+		return nil, 0, fmt.Errorf("failed to fetch applied migrations: %w", err)
 	}
 	defer rows.Close()
 
-	applied := make(map[string]bool)
 	for rows.Next() {
 		var ver string
 		rows.Scan(&ver)
 		applied[ver] = true
 	}
 
-	// Hitung batch berikutnya
+	// Hitung batch terakhir
 	var currentBatch int
 	err = m.DB.QueryRowContext(ctx, "SELECT COALESCE(MAX(batch), 0) FROM schema_migrations").Scan(&currentBatch)
 	if err != nil {
-		return fmt.Errorf("failed to calculate next batch: %w", err)
+		return nil, 0, fmt.Errorf("failed to calculate next batch: %w", err)
 	}
-	nextBatch := currentBatch + 1
+	return applied, currentBatch, nil
+}
 
-	// 3. Baca file migrasi dari folder
-	files, err := os.ReadDir(m.Dir)
+// lock mengambil migration lock agar dua proses tidak menjalankan migrasi yang sama
+func (m *Migrator) lock(ctx context.Context) (*dbmanager.Lock, error) {
+	timeout := m.LockTimeout
+	if timeout <= 0 {
+		timeout = DefaultLockTimeout
+	}
+
+	lock, err := dbmanager.AcquireLock(ctx, m.DB, m.Dialect, lockName, timeout)
 	if err != nil {
-		return fmt.Errorf("failed to read migration dir: %w", err)
+		return nil, fmt.Errorf("failed to acquire migration lock: %w", err)
 	}
+	return lock, nil
+}
 
-	var pending []string
-	for _, f := range files {
-		if !f.IsDir() && strings.HasSuffix(f.Name(), ".zl") {
-			pending = append(pending, f.Name())
+// execer adalah bagian dari *sql.DB dan *sql.Tx yang dipakai untuk mencatat migrasi
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// apply menjalankan satu file migrasi lalu memanggil record untuk memperbarui
+// schema_migrations. Jika dialect mendukung DDL transaksional, keduanya dibungkus
+// dalam satu transaksi sehingga migrasi yang gagal tidak meninggalkan perubahan setengah jalan.
+func (m *Migrator) apply(ctx context.Context, root *engine.Node, scope *engine.Scope, record func(execer) error) error {
+	if m.Pretend {
+		return m.Engine.Execute(ctx, root, scope)
+	}
+
+	if !dbmanager.SupportsTransactionalDDL(m.Dialect) {
+		if err := m.Engine.Execute(ctx, root, scope); err != nil {
+			slog.Warn("⚠️  DDL is not transactional on this database, statements before the failure were not rolled back", "dialect", m.Dialect.Name())
+			return err
+		}
+		return record(m.DB)
+	}
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	scope.Set("_active_tx", tx)
+	scope.Set("_active_tx_db", m.Connection)
+
+	if err := m.Engine.Execute(ctx, root, scope); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := record(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// Run menjalankan semua migrasi yang belum diaplikasikan
+func (m *Migrator) Run() error {
+	ctx := context.Background()
+
+	if !m.Pretend {
+		lock, err := m.lock(ctx)
+		if err != nil {
+			return err
 		}
+		defer lock.Release()
+	}
+
+	return m.run(ctx)
+}
+
+func (m *Migrator) run(ctx context.Context) error {
+	// 1. Pastikan tabel tracking ada (pretend mode tidak mengubah database)
+	if !m.Pretend {
+		if err := m.ensureTable(ctx); err != nil {
+			return err
+		}
+	}
+
+	// 2. Ambil migrasi yang sudah diaplikasikan
+	applied, currentBatch, err := m.appliedVersions(ctx)
+	if err != nil {
+		return err
+	}
+
+	// 2b. Database baru: muat schema dump lebih dulu, lalu lanjutkan dari riwayat di dalamnya
+	if len(applied) == 0 && !m.Pretend && m.SchemaFile != "" {
+		if _, err := os.Stat(m.SchemaFile); err == nil {
+			if err := m.loadSchema(ctx); err != nil {
+				return fmt.Errorf("failed to load schema dump '%s': %w", m.SchemaFile, err)
+			}
+			if applied, currentBatch, err = m.appliedVersions(ctx); err != nil {
+				return err
+			}
+		}
+	}
+	nextBatch := currentBatch + 1
+
+	// 3. Baca file migrasi dari folder
+	pending, err := m.files()
+	if err != nil {
+		return err
 	}
-	sort.Strings(pending) // Pastikan urut (001, 002, ...)
 
 	// 4. Eksekusi yang belum diaplikasikan
 	count := 0
@@ -96,27 +337,37 @@ func (m *Migrator) Run() error {
 			return fmt.Errorf("failed to parse migration '%s': %w", filename, err)
 		}
 
-		scope := engine.NewScope(nil)
-		scope.Set("migration_ver", filename)
-
-		// Jalankan Script Zenolang
-		if err := m.Engine.Execute(ctx, root, scope); err != nil {
-			return fmt.Errorf("failed to execute migration '%s': %w", filename, err)
+		sum, err := m.checksum(filename)
+		if err != nil {
+			return fmt.Errorf("failed to read migration '%s': %w", filename, err)
 		}
 
-		// Catat ke DB
-		insertQuery := fmt.Sprintf("INSERT INTO schema_migrations (version, batch) VALUES (%s, %s)", m.Dialect.Placeholder(1), m.Dialect.Placeholder(2))
-		_, err = m.DB.ExecContext(ctx, insertQuery, filepath.Join(m.Dir, filename), nextBatch)
+		scope := m.newScope(filename, "up")
+
+		// Jalankan Script Zenolang lalu catat ke DB
+		var recordErr error
+		err = m.apply(ctx, root, scope, func(exec execer) error {
+			insertQuery := fmt.Sprintf("INSERT INTO schema_migrations (version, batch, checksum) VALUES (%s, %s, %s)", m.Dialect.Placeholder(1), m.Dialect.Placeholder(2), m.Dialect.Placeholder(3))
+			_, recordErr = exec.ExecContext(ctx, insertQuery, versionKey, nextBatch, sum)
+			return recordErr
+		})
+		if recordErr != nil {
+			return fmt.Errorf("failed to record migration '%s': %w", filename, recordErr)
+		}
 		if err != nil {
-			return fmt.Errorf("failed to record migration '%s': %w", filename, err)
+			return fmt.Errorf("failed to execute migration '%s': %w", filename, err)
 		}
 
-		slog.Info("✅ Applied", "file", filename)
 		count++
+		if !m.Pretend {
+			slog.Info("✅ Applied", "file", filename)
+		}
 	}
 
 	if count == 0 {
 		slog.Info("✨ Database is up to date.")
+	} else if m.Pretend {
+		slog.Info("📝 Pretend Complete", "migrations", count, "statements", len(m.Statements))
 	} else {
 		slog.Info("🎉 Migration Complete", "applied", count)
 	}
@@ -124,28 +375,64 @@ func (m *Migrator) Run() error {
 	return nil
 }
 
-func (m *Migrator) Rollback() error {
+// Rollback membatalkan sejumlah batch terakhir (minimal 1)
+func (m *Migrator) Rollback(steps int) error {
 	ctx := context.Background()
+	if steps < 1 {
+		steps = 1
+	}
+
+	if !m.Pretend {
+		lock, err := m.lock(ctx)
+		if err != nil {
+			return err
+		}
+		defer lock.Release()
+
+		if err := m.ensureTable(ctx); err != nil {
+			return err
+		}
+	}
 
-	// 1. Ambil batch terakhir
-	var lastBatch int
-	err := m.DB.QueryRowContext(ctx, "SELECT COALESCE(MAX(batch), 0) FROM schema_migrations").Scan(&lastBatch)
+	// 1. Ambil batch terakhir (urut terbalik)
+	rows, err := m.DB.QueryContext(ctx, "SELECT DISTINCT batch FROM schema_migrations ORDER BY batch DESC")
 	if err != nil {
+		if m.Pretend {
+			slog.Info("✨ No migrations to rollback.")
+			return nil
+		}
 		return fmt.Errorf("failed to get last batch: %w", err)
 	}
 
-	if lastBatch == 0 {
+	var batches []int
+	for rows.Next() && len(batches) < steps {
+		var batch int
+		rows.Scan(&batch)
+		batches = append(batches, batch)
+	}
+	rows.Close()
+
+	if len(batches) == 0 {
 		slog.Info("✨ No migrations to rollback.")
 		return nil
 	}
 
+	for _, batch := range batches {
+		if err := m.rollbackBatch(ctx, batch); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *Migrator) rollbackBatch(ctx context.Context, lastBatch int) error {
 	// 2. Ambil semua migrasi dari batch terakhir (urut terbalik)
 	query := fmt.Sprintf("SELECT version FROM schema_migrations WHERE batch = %s ORDER BY version DESC", m.Dialect.Placeholder(1))
 	rows, err := m.DB.QueryContext(ctx, query, lastBatch)
 	if err != nil {
 		return fmt.Errorf("failed to fetch migrations for rollback: %w", err)
 	}
-	defer rows.Close()
 
 	var toRollback []string
 	for rows.Next() {
@@ -153,6 +440,7 @@ func (m *Migrator) Rollback() error {
 		rows.Scan(&ver)
 		toRollback = append(toRollback, ver)
 	}
+	rows.Close()
 
 	slog.Info("🔄 Rolling back...", "batch", lastBatch, "count", len(toRollback))
 
@@ -164,25 +452,224 @@ func (m *Migrator) Rollback() error {
 			return fmt.Errorf("failed to parse migration '%s': %w", filename, err)
 		}
 
-		scope := engine.NewScope(nil)
-		scope.Set("migration_ver", filename)
-		scope.Set("_migration_direction", "down") // Set direction to down!
+		scope := m.newScope(filename, "down")
 
-		// Jalankan Script Zenolang
-		if err := m.Engine.Execute(ctx, root, scope); err != nil {
-			return fmt.Errorf("failed to execute rollback for '%s': %w", filename, err)
+		// Jalankan Script Zenolang lalu hapus dari DB
+		var recordErr error
+		err = m.apply(ctx, root, scope, func(exec execer) error {
+			deleteQuery := fmt.Sprintf("DELETE FROM schema_migrations WHERE version = %s", m.Dialect.Placeholder(1))
+			_, recordErr = exec.ExecContext(ctx, deleteQuery, versionKey)
+			return recordErr
+		})
+		if recordErr != nil {
+			return fmt.Errorf("failed to delete migration record '%s': %w", filename, recordErr)
 		}
-
-		// Hapus dari DB
-		deleteQuery := fmt.Sprintf("DELETE FROM schema_migrations WHERE version = %s", m.Dialect.Placeholder(1))
-		_, err = m.DB.ExecContext(ctx, deleteQuery, versionKey)
 		if err != nil {
-			return fmt.Errorf("failed to delete migration record '%s': %w", filename, err)
+			return fmt.Errorf("failed to execute rollback for '%s': %w", filename, err)
 		}
 
-		slog.Info("Rolled back", "file", filename)
+		if !m.Pretend {
+			slog.Info("Rolled back", "file", filename)
+		}
 	}
 
 	slog.Info("🎉 Rollback Complete", "batch", lastBatch)
 	return nil
 }
+
+// Status mengembalikan daftar file migrasi beserta status penerapannya dan drift
+// terhadap isi schema_migrations (file berubah, hilang, atau urutan tidak sesuai)
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	ctx := context.Background()
+
+	if err := m.ensureTable(ctx); err != nil {
+		return nil, err
+	}
+
+	rows, err := m.DB.QueryContext(ctx, "SELECT version, batch, checksum, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[string]MigrationStatus)
+	for rows.Next() {
+		var ver string
+		var batch sql.NullInt64
+		var checksum, appliedAt sql.NullString
+		if err := rows.Scan(&ver, &batch, &checksum, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[ver] = MigrationStatus{
+			File:      filepath.Base(ver),
+			Applied:   true,
+			Batch:     int(batch.Int64),
+			AppliedAt: appliedAt.String,
+			Checksum:  checksum.String,
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	files, err := m.files()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []MigrationStatus
+	seen := make(map[string]bool)
+	for _, filename := range files {
+		versionKey := filepath.Join(m.Dir, filename)
+		seen[versionKey] = true
+
+		st, ok := applied[versionKey]
+		if !ok {
+			st = MigrationStatus{File: filename}
+		}
+
+		// Checksum kosong berarti migrasi dicatat sebelum checksum diperkenalkan
+		if st.Applied && st.Checksum != "" {
+			sum, err := m.checksum(filename)
+			if err != nil {
+				return nil, err
+			}
+			st.Modified = sum != st.Checksum
+		}
+		result = append(result, st)
+	}
+
+	// Migrasi yang tercatat tapi filenya sudah tidak ada
+	for ver, st := range applied {
+		if !seen[ver] {
+			st.Missing = true
+			result = append(result, st)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].File < result[j].File })
+
+	markOutOfOrder(result)
+	return result, nil
+}
+
+// markOutOfOrder menandai migrasi pending yang urut sebelum migrasi yang sudah
+// diaplikasikan, dan migrasi yang diaplikasikan di batch lebih baru dari file sesudahnya
+func markOutOfOrder(statuses []MigrationStatus) {
+	lastApplied := -1
+	for i, st := range statuses {
+		if st.Applied {
+			lastApplied = i
+		}
+	}
+
+	minBatchAfter := 0
+	for i := len(statuses) - 1; i >= 0; i-- {
+		st := &statuses[i]
+		if !st.Applied {
+			st.OutOfOrder = i < lastApplied
+			continue
+		}
+		if minBatchAfter > 0 && st.Batch > minBatchAfter {
+			st.OutOfOrder = true
+		}
+		if minBatchAfter == 0 || st.Batch < minBatchAfter {
+			minBatchAfter = st.Batch
+		}
+	}
+}
+
+// Drift mengembalikan migrasi yang berubah, hilang, atau tidak berurutan
+func (m *Migrator) Drift() ([]MigrationStatus, error) {
+	statuses, err := m.Status()
+	if err != nil {
+		return nil, err
+	}
+
+	var drifted []MigrationStatus
+	for _, st := range statuses {
+		if st.Drifted() {
+			drifted = append(drifted, st)
+		}
+	}
+	return drifted, nil
+}
+
+// Fresh menghapus seluruh tabel di koneksi lalu menjalankan ulang semua migrasi
+func (m *Migrator) Fresh() error {
+	ctx := context.Background()
+
+	lock, err := m.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	tables, err := m.listTables(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list tables: %w", err)
+	}
+
+	slog.Info("🧹 Dropping all tables...", "connection", m.Connection, "count", len(tables))
+	if err := m.dropTables(ctx, tables); err != nil {
+		return err
+	}
+
+	return m.run(ctx)
+}
+
+func (m *Migrator) listTables(ctx context.Context) ([]string, error) {
+	all, err := dbmanager.ListTables(ctx, m.DB, m.Dialect)
+	if err != nil {
+		return nil, err
+	}
+
+	var tables []string
+	for _, name := range all {
+		if name == "zeno_locks" {
+			continue // Tabel lock SQLite sedang dipakai oleh proses ini
+		}
+		tables = append(tables, name)
+	}
+	return tables, nil
+}
+
+func (m *Migrator) dropTables(ctx context.Context, tables []string) error {
+	// Gunakan satu koneksi agar pengaturan foreign key berlaku untuk semua DROP
+	conn, err := m.DB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	switch m.Dialect.Name() {
+	case "mysql":
+		conn.ExecContext(ctx, "SET FOREIGN_KEY_CHECKS = 0")
+		defer conn.ExecContext(ctx, "SET FOREIGN_KEY_CHECKS = 1")
+	case "sqlite":
+		conn.ExecContext(ctx, "PRAGMA foreign_keys = OFF")
+		defer conn.ExecContext(ctx, "PRAGMA foreign_keys = ON")
+	case "sqlserver":
+		// SQL Server tidak punya CASCADE pada DROP TABLE, hapus constraint FK terlebih dahulu
+		dropFKs := `
+		DECLARE @sql NVARCHAR(MAX) = N'';
+		SELECT @sql += N'ALTER TABLE ' + QUOTENAME(OBJECT_SCHEMA_NAME(parent_object_id)) + N'.' + QUOTENAME(OBJECT_NAME(parent_object_id)) +
+			N' DROP CONSTRAINT ' + QUOTENAME(name) + N';' FROM sys.foreign_keys;
+		EXEC sp_executesql @sql;`
+		if _, err := conn.ExecContext(ctx, dropFKs); err != nil {
+			return fmt.Errorf("failed to drop foreign keys: %w", err)
+		}
+	}
+
+	for _, table := range tables {
+		query := "DROP TABLE " + m.Dialect.QuoteIdentifier(table)
+		if m.Dialect.Name() == "postgres" {
+			query += " CASCADE"
+		}
+		if _, err := conn.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("failed to drop table '%s': %w", table, err)
+		}
+		slog.Info("Dropped", "table", table)
+	}
+
+	return nil
+}
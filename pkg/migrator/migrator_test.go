@@ -0,0 +1,228 @@
+package migrator
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nextcore/zeno-go/pkg/engine"
+	"github.com/nextcore/zenoengine/pkg/dbmanager"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestMigrator membuat Migrator di atas SQLite in-memory dengan slot test.up / test.down,
+// yang menjalankan SQL pada nilainya sesuai arah migrasi
+func newTestMigrator(t *testing.T) (*Migrator, *sql.DB) {
+	dbMgr := dbmanager.NewDBManager()
+	if err := dbMgr.AddConnection("default", "sqlite", ":memory:", 1, 1); err != nil {
+		t.Fatalf("Failed to create in-memory db: %v", err)
+	}
+	t.Cleanup(func() { dbMgr.Close() })
+	db := dbMgr.GetConnection("default")
+
+	eng := engine.NewEngine()
+	for _, slot := range []string{"test.up", "test.down"} {
+		direction := "up"
+		if slot == "test.down" {
+			direction = "down"
+		}
+		eng.Register(slot, func(ctx context.Context, node *engine.Node, scope *engine.Scope) error {
+			current := "up"
+			if v, ok := scope.Get("_migration_direction"); ok && v == "down" {
+				current = "down"
+			}
+			if current != direction {
+				return nil
+			}
+			var exec execer = db
+			if tx, ok := scope.Get("_active_tx"); ok {
+				exec = tx.(*sql.Tx)
+			}
+			_, err := exec.ExecContext(ctx, fmt.Sprint(node.Value))
+			return err
+		}, engine.SlotMeta{})
+	}
+	return New(eng, dbMgr, t.TempDir()), db
+}
+
+// writeMigration menulis file migrasi yang membuat (up) dan menghapus (down) tabel
+func writeMigration(t *testing.T, m *Migrator, filename, table string) {
+	content := fmt.Sprintf("test.up: CREATE TABLE %s (id INTEGER PRIMARY KEY)\ntest.down: DROP TABLE %s\n", table, table)
+	if err := os.WriteFile(filepath.Join(m.Dir, filename), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func tableExists(t *testing.T, db *sql.DB, table string) bool {
+	var n int
+	assert.NoError(t, db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", table).Scan(&n))
+	return n > 0
+}
+
+// batches mengembalikan batch setiap migrasi yang tercatat, dengan nama file sebagai key
+func batches(t *testing.T, db *sql.DB) map[string]int {
+	rows, err := db.Query("SELECT version, batch FROM schema_migrations")
+	assert.NoError(t, err)
+	defer rows.Close()
+	result := make(map[string]int)
+	for rows.Next() {
+		var version string
+		var batch int
+		assert.NoError(t, rows.Scan(&version, &batch))
+		result[filepath.Base(version)] = batch
+	}
+	return result
+}
+
+func TestRun(t *testing.T) {
+	m, db := newTestMigrator(t)
+	writeMigration(t, m, "001_create_users.zl", "users")
+	writeMigration(t, m, "002_create_posts.zl", "posts")
+
+	assert.NoError(t, m.Run())
+	assert.True(t, tableExists(t, db, "users"))
+	assert.True(t, tableExists(t, db, "posts"))
+	assert.Equal(t, map[string]int{"001_create_users.zl": 1, "002_create_posts.zl": 1}, batches(t, db))
+
+	// Migrasi yang sudah diaplikasikan dilewati; file baru masuk batch berikutnya
+	writeMigration(t, m, "003_create_tags.zl", "tags")
+	assert.NoError(t, m.Run())
+	assert.NoError(t, m.Run())
+	assert.Equal(t, map[string]int{"001_create_users.zl": 1, "002_create_posts.zl": 1, "003_create_tags.zl": 2}, batches(t, db))
+
+	// Migrasi yang gagal tidak dicatat dan perubahannya di-rollback
+	content := "test.up: CREATE TABLE comments (id INTEGER)\ntest.up: INSERT INTO missing_table VALUES (1)\n"
+	assert.NoError(t, os.WriteFile(filepath.Join(m.Dir, "004_broken.zl"), []byte(content), 0644))
+	err := m.Run()
+	assert.ErrorContains(t, err, "004_broken.zl")
+	assert.False(t, tableExists(t, db, "comments"))
+	assert.NotContains(t, batches(t, db), "004_broken.zl")
+}
+
+func TestRollback(t *testing.T) {
+	m, db := newTestMigrator(t)
+	writeMigration(t, m, "001_create_users.zl", "users")
+	assert.NoError(t, m.Run())
+	writeMigration(t, m, "002_create_posts.zl", "posts")
+	writeMigration(t, m, "003_create_tags.zl", "tags")
+	assert.NoError(t, m.Run())
+	writeMigration(t, m, "004_create_likes.zl", "likes")
+	assert.NoError(t, m.Run())
+
+	// Satu langkah = batch terakhir saja
+	assert.NoError(t, m.Rollback(1))
+	assert.False(t, tableExists(t, db, "likes"))
+	assert.True(t, tableExists(t, db, "tags"))
+	assert.Equal(t, map[string]int{"001_create_users.zl": 1, "002_create_posts.zl": 2, "003_create_tags.zl": 2}, batches(t, db))
+
+	// Steps melebihi jumlah batch membatalkan semuanya
+	assert.NoError(t, m.Rollback(5))
+	assert.Empty(t, batches(t, db))
+	for _, table := range []string{"users", "posts", "tags"} {
+		assert.False(t, tableExists(t, db, table), table)
+	}
+	assert.NoError(t, m.Rollback(1), "nothing left to roll back")
+
+	// Migrasi yang di-rollback bisa dijalankan lagi
+	assert.NoError(t, m.Run())
+	assert.Len(t, batches(t, db), 4)
+}
+
+func TestFresh(t *testing.T) {
+	m, db := newTestMigrator(t)
+	writeMigration(t, m, "001_create_users.zl", "users")
+	assert.NoError(t, m.Run())
+	writeMigration(t, m, "002_create_posts.zl", "posts")
+	assert.NoError(t, m.Run())
+
+	// Tabel di luar migrasi ikut dihapus
+	_, err := db.Exec("CREATE TABLE leftover (id INTEGER)")
+	assert.NoError(t, err)
+
+	assert.NoError(t, m.Fresh())
+	assert.False(t, tableExists(t, db, "leftover"))
+	assert.True(t, tableExists(t, db, "users"))
+	assert.True(t, tableExists(t, db, "posts"))
+	assert.Equal(t, map[string]int{"001_create_users.zl": 1, "002_create_posts.zl": 1}, batches(t, db))
+}
+
+func TestMarkOutOfOrder(t *testing.T) {
+	applied := func(batch int) MigrationStatus { return MigrationStatus{Applied: true, Batch: batch} }
+	pending := MigrationStatus{}
+
+	tests := []struct {
+		name     string
+		statuses []MigrationStatus
+		want     []bool
+	}{
+		{"empty", nil, nil},
+		{"all pending", []MigrationStatus{pending, pending}, []bool{false, false}},
+		{"in order", []MigrationStatus{applied(1), applied(1), applied(2), pending}, []bool{false, false, false, false}},
+		{"pending before applied", []MigrationStatus{applied(1), pending, applied(2), pending}, []bool{false, true, false, false}},
+		{"newer batch before older batch", []MigrationStatus{applied(1), applied(3), applied(2)}, []bool{false, true, false}},
+		{"newer batch before several older", []MigrationStatus{applied(2), applied(1), applied(1)}, []bool{true, false, false}},
+		{"same batch", []MigrationStatus{applied(2), applied(2)}, []bool{false, false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			markOutOfOrder(tt.statuses)
+			var got []bool
+			for _, st := range tt.statuses {
+				got = append(got, st.OutOfOrder)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestStatusDrift(t *testing.T) {
+	m, _ := newTestMigrator(t)
+	writeMigration(t, m, "001_create_users.zl", "users")
+	writeMigration(t, m, "002_create_posts.zl", "posts")
+	assert.NoError(t, m.Run())
+
+	drifted, err := m.Drift()
+	assert.NoError(t, err)
+	assert.Empty(t, drifted)
+
+	// File yang sudah diaplikasikan diedit, file lain dihapus, dan file baru diberi nama lebih awal
+	writeMigration(t, m, "001_create_users.zl", "members")
+	assert.NoError(t, os.Remove(filepath.Join(m.Dir, "002_create_posts.zl")))
+	writeMigration(t, m, "000_create_roles.zl", "roles")
+
+	statuses, err := m.Status()
+	assert.NoError(t, err)
+	if assert.Len(t, statuses, 3) {
+		assert.Equal(t, "000_create_roles.zl", statuses[0].File)
+		assert.False(t, statuses[0].Applied)
+		assert.True(t, statuses[0].OutOfOrder)
+
+		assert.Equal(t, "001_create_users.zl", statuses[1].File)
+		assert.True(t, statuses[1].Applied)
+		assert.Equal(t, 1, statuses[1].Batch)
+		assert.True(t, statuses[1].Modified)
+		assert.False(t, statuses[1].Missing)
+
+		assert.Equal(t, "002_create_posts.zl", statuses[2].File)
+		assert.True(t, statuses[2].Missing)
+		assert.False(t, statuses[2].Modified)
+	}
+
+	drifted, err = m.Drift()
+	assert.NoError(t, err)
+	var files []string
+	for _, st := range drifted {
+		files = append(files, st.File)
+	}
+	assert.Equal(t, []string{"000_create_roles.zl", "001_create_users.zl", "002_create_posts.zl"}, files)
+
+	// Mengembalikan isi file menghilangkan flag Modified
+	writeMigration(t, m, "001_create_users.zl", "users")
+	statuses, err = m.Status()
+	assert.NoError(t, err)
+	assert.False(t, statuses[1].Modified)
+}
@@ -0,0 +1,448 @@
+package migrator
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/nextcore/zeno-go/pkg/engine"
+	"github.com/nextcore/zenoengine/pkg/dbmanager"
+)
+
+// SchemaDumpFile mengembalikan lokasi default schema dump untuk sebuah koneksi
+func SchemaDumpFile(connName, format string) string {
+	return filepath.Join("database", "schema", fmt.Sprintf("%s-schema.%s", connName, format))
+}
+
+// appliedMigration adalah satu baris schema_migrations yang ikut di-dump
+type appliedMigration struct {
+	Version  string
+	Batch    int
+	Checksum string
+}
+
+// DumpSchema membaca struktur database dan menuliskannya sebagai migrasi .zl ("zl")
+// atau SQL biasa ("sql"). Riwayat schema_migrations ikut disertakan sehingga
+// database baru yang di-bootstrap dari dump tidak menjalankan ulang migrasi lama.
+func DumpSchema(ctx context.Context, db *sql.DB, dialect dbmanager.Dialect, format string) (string, error) {
+	names, err := dbmanager.ListTables(ctx, db, dialect)
+	if err != nil {
+		return "", err
+	}
+
+	var tables []*dbmanager.TableSchema
+	hasHistory := false
+	for _, name := range names {
+		if name == "zeno_locks" {
+			continue
+		}
+		if name == "schema_migrations" {
+			hasHistory = true
+			continue
+		}
+		ts, err := dbmanager.DescribeTable(ctx, db, dialect, name)
+		if err != nil {
+			return "", err
+		}
+		tables = append(tables, ts)
+	}
+	tables = sortByDependency(tables)
+
+	var history []appliedMigration
+	if hasHistory {
+		if history, err = appliedHistory(ctx, db); err != nil {
+			return "", err
+		}
+	}
+
+	switch format {
+	case "zl":
+		return renderSchemaZL(dialect, tables, history), nil
+	case "sql":
+		return renderSchemaSQL(dialect, tables, history), nil
+	}
+	return "", fmt.Errorf("unsupported schema format '%s' (use zl or sql)", format)
+}
+
+func appliedHistory(ctx context.Context, db *sql.DB) ([]appliedMigration, error) {
+	rows, err := db.QueryContext(ctx, "SELECT version, batch, checksum FROM schema_migrations ORDER BY batch, version")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	var history []appliedMigration
+	for rows.Next() {
+		var am appliedMigration
+		var sum sql.NullString
+		if err := rows.Scan(&am.Version, &am.Batch, &sum); err != nil {
+			return nil, err
+		}
+		am.Checksum = sum.String
+		history = append(history, am)
+	}
+	return history, rows.Err()
+}
+
+// sortByDependency mengurutkan tabel agar tabel yang direferensikan foreign key dibuat lebih dulu
+func sortByDependency(tables []*dbmanager.TableSchema) []*dbmanager.TableSchema {
+	byName := make(map[string]*dbmanager.TableSchema, len(tables))
+	for _, t := range tables {
+		byName[t.Name] = t
+	}
+
+	visited := make(map[string]bool, len(tables))
+	sorted := make([]*dbmanager.TableSchema, 0, len(tables))
+
+	var visit func(t *dbmanager.TableSchema)
+	visit = func(t *dbmanager.TableSchema) {
+		if visited[t.Name] {
+			return // Sudah diproses (atau siklus)
+		}
+		visited[t.Name] = true
+		for _, fk := range t.ForeignKeys {
+			if ref, ok := byName[fk.RefTable]; ok && ref != t {
+				visit(ref)
+			}
+		}
+		sorted = append(sorted, t)
+	}
+
+	for _, t := range tables {
+		visit(t)
+	}
+	return sorted
+}
+
+// primaryKey mengembalikan kolom primary key tabel
+func primaryKey(ts *dbmanager.TableSchema) []string {
+	for _, idx := range ts.Indexes {
+		if idx.Primary {
+			return idx.Columns
+		}
+	}
+	var cols []string
+	for _, c := range ts.Columns {
+		if c.PrimaryKey {
+			cols = append(cols, c.Name)
+		}
+	}
+	return cols
+}
+
+func hasAutoIncrement(ts *dbmanager.TableSchema) bool {
+	for _, c := range ts.Columns {
+		if c.AutoIncrement {
+			return true
+		}
+	}
+	return false
+}
+
+// isForeignKeyIndex mendeteksi index yang dibuat otomatis oleh database untuk foreign key (MySQL)
+func isForeignKeyIndex(ts *dbmanager.TableSchema, idx dbmanager.IndexInfo) bool {
+	for _, fk := range ts.ForeignKeys {
+		if fk.Name == idx.Name && strings.Join(fk.Columns, ",") == strings.Join(idx.Columns, ",") {
+			return true
+		}
+	}
+	return false
+}
+
+// --- Format .zl (Schema Builder) ---
+
+func zlQuote(s string) string {
+	if strings.Contains(s, "'") {
+		return `"` + s + `"`
+	}
+	return "'" + s + "'"
+}
+
+func renderSchemaZL(dialect dbmanager.Dialect, tables []*dbmanager.TableSchema, history []appliedMigration) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "// Schema dump (%s), generated by 'zeno schema:dump' at %s\n", dialect.Name(), time.Now().Format(time.RFC3339))
+	b.WriteString("// Loaded by 'zeno migrate' when the database has no migrations yet.\n\n")
+
+	b.WriteString("up {\n")
+	for _, ts := range tables {
+		writeTableZL(&b, ts)
+	}
+
+	if len(history) > 0 {
+		flat := strings.Join(strings.Fields(TrackingTableSQL(dialect.Name())), " ")
+		fmt.Fprintf(&b, "    db.execute: %s\n", zlQuote(flat))
+		for _, am := range history {
+			b.WriteString("    db.execute: 'INSERT INTO schema_migrations (version, batch, checksum) VALUES (?, ?, ?)' {\n")
+			b.WriteString("        bind: {\n")
+			fmt.Fprintf(&b, "            version: %s\n", zlQuote(am.Version))
+			fmt.Fprintf(&b, "            batch: %d\n", am.Batch)
+			fmt.Fprintf(&b, "            checksum: %s\n", zlQuote(am.Checksum))
+			b.WriteString("        }\n")
+			b.WriteString("    }\n")
+		}
+	}
+	b.WriteString("}\n\n")
+
+	b.WriteString("down {\n")
+	for i := len(tables) - 1; i >= 0; i-- {
+		fmt.Fprintf(&b, "    db.drop_table: %s\n", zlQuote(tables[i].Name))
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+var nativeTextPattern = regexp.MustCompile(`text|clob|max`)
+
+func writeTableZL(b *strings.Builder, ts *dbmanager.TableSchema) {
+	fmt.Fprintf(b, "    db.create_table: %s {\n", zlQuote(ts.Name))
+
+	pk := primaryKey(ts)
+	if len(pk) > 1 || (len(pk) == 1 && !hasAutoIncrement(ts)) {
+		fmt.Fprintf(b, "        // primary key (%s) cannot be expressed with the Schema Builder, use --format=sql\n", strings.Join(pk, ", "))
+	}
+
+	for _, c := range ts.Columns {
+		colType, limit, precision, scale := c.BuilderType()
+		if colType == "text" && !nativeTextPattern.MatchString(strings.ToLower(c.Type)) {
+			fmt.Fprintf(b, "        // native type: %s\n", c.Type)
+		}
+
+		var props []string
+		if colType != "id" {
+			if limit > 0 && limit != 255 {
+				props = append(props, fmt.Sprintf("limit: %d", limit))
+			}
+			if colType == "decimal" {
+				props = append(props, fmt.Sprintf("precision: %d", precision), fmt.Sprintf("scale: %d", scale))
+			}
+			if !c.Nullable {
+				props = append(props, "nullable: false")
+			}
+			if c.Default != nil && !c.AutoIncrement {
+				props = append(props, "default_raw: "+zlQuote(*c.Default))
+			}
+		}
+
+		if len(props) == 0 {
+			fmt.Fprintf(b, "        db.%s: %s\n", colType, zlQuote(c.Name))
+			continue
+		}
+		fmt.Fprintf(b, "        db.%s: %s {\n", colType, zlQuote(c.Name))
+		for _, p := range props {
+			fmt.Fprintf(b, "            %s\n", p)
+		}
+		b.WriteString("        }\n")
+	}
+
+	for _, idx := range ts.Indexes {
+		if idx.Primary || isForeignKeyIndex(ts, idx) {
+			continue
+		}
+		slot := "db.index"
+		if idx.Unique {
+			slot = "db.unique"
+		}
+		fmt.Fprintf(b, "        %s: %s { name: %s }\n", slot, zlQuote(strings.Join(idx.Columns, ", ")), zlQuote(idx.Name))
+	}
+
+	for _, fk := range ts.ForeignKeys {
+		if len(fk.Columns) != 1 {
+			fmt.Fprintf(b, "        // foreign key %s (%s) references %s (%s) spans multiple columns, use --format=sql\n",
+				fk.Name, strings.Join(fk.Columns, ", "), fk.RefTable, strings.Join(fk.RefColumns, ", "))
+			continue
+		}
+		onDelete := fk.OnDelete
+		if onDelete == "" {
+			onDelete = "NO ACTION"
+		}
+		fmt.Fprintf(b, "        db.foreign: %s {\n", zlQuote(fk.Columns[0]))
+		fmt.Fprintf(b, "            name: %s\n", zlQuote(fk.Name))
+		fmt.Fprintf(b, "            references: %s\n", zlQuote(fk.RefTable))
+		fmt.Fprintf(b, "            on: %s\n", zlQuote(fk.RefColumns[0]))
+		fmt.Fprintf(b, "            on_delete: %s\n", zlQuote(onDelete))
+		b.WriteString("        }\n")
+	}
+
+	b.WriteString("    }\n")
+}
+
+// --- Format SQL ---
+
+func renderSchemaSQL(dialect dbmanager.Dialect, tables []*dbmanager.TableSchema, history []appliedMigration) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "-- Schema dump (%s), generated by 'zeno schema:dump' at %s\n\n", dialect.Name(), time.Now().Format(time.RFC3339))
+
+	for _, ts := range tables {
+		writeTableSQL(&b, dialect, ts)
+	}
+
+	if len(history) > 0 {
+		b.WriteString(strings.ReplaceAll(strings.TrimSpace(TrackingTableSQL(dialect.Name())), "\n\t", "\n"))
+		b.WriteString("\n\n")
+		for _, am := range history {
+			fmt.Fprintf(&b, "INSERT INTO schema_migrations (version, batch, checksum) VALUES (%s, %d, %s);\n",
+				sqlString(am.Version), am.Batch, sqlString(am.Checksum))
+		}
+	}
+	return b.String()
+}
+
+func sqlString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+func quoteIdentifiers(dialect dbmanager.Dialect, cols []string) string {
+	quoted := make([]string, len(cols))
+	for i, c := range cols {
+		quoted[i] = dialect.QuoteIdentifier(c)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// autoIncrementSQL merender kolom auto increment sesuai dialect
+func autoIncrementSQL(dialect dbmanager.Dialect, c dbmanager.ColumnInfo) string {
+	name := dialect.QuoteIdentifier(c.Name)
+	switch dialect.Name() {
+	case "sqlite":
+		return name + " INTEGER PRIMARY KEY AUTOINCREMENT"
+	case "postgres":
+		if strings.HasPrefix(strings.ToLower(c.Type), "bigint") {
+			return name + " BIGSERIAL"
+		}
+		return name + " SERIAL"
+	case "mysql":
+		return name + " " + c.Type + " NOT NULL AUTO_INCREMENT"
+	case "sqlserver":
+		return name + " " + c.Type + " IDENTITY(1,1) NOT NULL"
+	}
+	return name + " " + c.Type
+}
+
+func writeTableSQL(b *strings.Builder, dialect dbmanager.Dialect, ts *dbmanager.TableSchema) {
+	var defs []string
+	pk := primaryKey(ts)
+	inlinePK := false
+
+	for _, c := range ts.Columns {
+		if c.AutoIncrement {
+			defs = append(defs, autoIncrementSQL(dialect, c))
+			inlinePK = dialect.Name() == "sqlite"
+			continue
+		}
+		def := dialect.QuoteIdentifier(c.Name) + " " + c.Type
+		if c.Default != nil {
+			def += " DEFAULT " + *c.Default
+		}
+		if !c.Nullable {
+			def += " NOT NULL"
+		}
+		defs = append(defs, def)
+	}
+
+	if len(pk) > 0 && !inlinePK {
+		defs = append(defs, fmt.Sprintf("PRIMARY KEY (%s)", quoteIdentifiers(dialect, pk)))
+	}
+
+	for _, fk := range ts.ForeignKeys {
+		def := fmt.Sprintf("CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s)",
+			dialect.QuoteIdentifier(fk.Name), quoteIdentifiers(dialect, fk.Columns),
+			dialect.QuoteIdentifier(fk.RefTable), quoteIdentifiers(dialect, fk.RefColumns))
+		if fk.OnDelete != "" && fk.OnDelete != "NO ACTION" {
+			def += " ON DELETE " + fk.OnDelete
+		}
+		if fk.OnUpdate != "" && fk.OnUpdate != "NO ACTION" {
+			def += " ON UPDATE " + fk.OnUpdate
+		}
+		defs = append(defs, def)
+	}
+
+	fmt.Fprintf(b, "CREATE TABLE %s (\n    %s\n);\n", dialect.QuoteIdentifier(ts.Name), strings.Join(defs, ",\n    "))
+
+	for _, idx := range ts.Indexes {
+		if idx.Primary || isForeignKeyIndex(ts, idx) {
+			continue
+		}
+		kind := "INDEX"
+		if idx.Unique {
+			kind = "UNIQUE INDEX"
+		}
+		fmt.Fprintf(b, "CREATE %s %s ON %s (%s);\n", kind, dialect.QuoteIdentifier(idx.Name),
+			dialect.QuoteIdentifier(ts.Name), quoteIdentifiers(dialect, idx.Columns))
+	}
+	b.WriteString("\n")
+}
+
+// SplitStatements memecah isi file SQL menjadi statement per baris yang diakhiri ';'
+func SplitStatements(content string) []string {
+	var statements []string
+	var current strings.Builder
+
+	for _, line := range strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, strings.TrimSuffix(strings.TrimSpace(current.String()), ";"))
+			current.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		statements = append(statements, rest)
+	}
+	return statements
+}
+
+// loadSchema menjalankan schema dump pada database yang belum pernah dimigrasi
+func (m *Migrator) loadSchema(ctx context.Context) error {
+	slog.Info("📦 Loading schema dump...", "file", m.SchemaFile)
+
+	if strings.HasSuffix(m.SchemaFile, ".sql") {
+		content, err := os.ReadFile(m.SchemaFile)
+		if err != nil {
+			return fmt.Errorf("failed to read schema dump: %w", err)
+		}
+		return m.execStatements(ctx, SplitStatements(string(content)))
+	}
+
+	root, err := engine.LoadScript(m.SchemaFile)
+	if err != nil {
+		return fmt.Errorf("failed to parse schema dump '%s': %w", m.SchemaFile, err)
+	}
+	scope := m.newScope(filepath.Base(m.SchemaFile), "up")
+	return m.apply(ctx, root, scope, func(execer) error { return nil })
+}
+
+// execStatements menjalankan statement SQL mentah, dalam satu transaksi bila dialect mendukung DDL transaksional
+func (m *Migrator) execStatements(ctx context.Context, statements []string) error {
+	var exec execer = m.DB
+	var tx *sql.Tx
+	if dbmanager.SupportsTransactionalDDL(m.Dialect) {
+		var err error
+		if tx, err = m.DB.BeginTx(ctx, nil); err != nil {
+			return fmt.Errorf("failed to begin transaction: %w", err)
+		}
+		exec = tx
+	}
+
+	for _, stmt := range statements {
+		if _, err := exec.ExecContext(ctx, stmt); err != nil {
+			if tx != nil {
+				tx.Rollback()
+			}
+			return fmt.Errorf("schema dump statement failed: %w\n%s", err, stmt)
+		}
+	}
+	if tx != nil {
+		return tx.Commit()
+	}
+	return nil
+}
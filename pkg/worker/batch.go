@@ -0,0 +1,309 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/nextcore/zenoengine/pkg/dbmanager"
+)
+
+// BatchQueue diimplementasikan queue yang bisa menyimpan progress batch (DBQueue)
+type BatchQueue interface {
+	JobQueue
+	Batches() *BatchStore
+}
+
+// batchColumns adalah struktur tabel job_batches
+var batchColumns = []queueColumn{
+	{Name: "id", Type: "id"},
+	{Name: "name", Type: "string", Null: true},
+	{Name: "queue", Type: "string"},
+	{Name: "total_jobs", Type: "int", Default: "0"},
+	{Name: "pending_jobs", Type: "int", Default: "0"},
+	{Name: "failed_jobs", Type: "int", Default: "0"},
+	{Name: "options", Type: "text", Null: true}, // JSON BatchOptions
+	{Name: "created_at", Type: "datetime", Null: true},
+	{Name: "failed_at", Type: "datetime", Null: true}, // Kegagalan pertama, saat catch dijalankan
+	{Name: "finished_at", Type: "datetime", Null: true},
+}
+
+// BatchOptions adalah callback batch dan data yang diteruskan ke callback. Setiap callback
+// berupa script, atau job.define pada field *Handler yang menggantikannya.
+type BatchOptions struct {
+	Then           string                 `json:"then,omitempty"`
+	ThenHandler    string                 `json:"then_handler,omitempty"`
+	Catch          string                 `json:"catch,omitempty"`
+	CatchHandler   string                 `json:"catch_handler,omitempty"`
+	Finally        string                 `json:"finally,omitempty"`
+	FinallyHandler string                 `json:"finally_handler,omitempty"`
+	Data           map[string]interface{} `json:"data,omitempty"`
+}
+
+// Batch adalah progress sekumpulan job yang berjalan paralel
+type Batch struct {
+	ID         int64
+	Name       string
+	Queue      string
+	Total      int
+	Pending    int
+	Failed     int
+	Options    BatchOptions
+	CreatedAt  time.Time
+	FinishedAt time.Time
+}
+
+// Processed adalah jumlah job yang sudah selesai, berhasil maupun gagal
+func (b *Batch) Processed() int {
+	return b.Total - b.Pending
+}
+
+// Progress adalah persentase job yang sudah diproses (0-100)
+func (b *Batch) Progress() int {
+	if b.Total == 0 {
+		return 100
+	}
+	return b.Processed() * 100 / b.Total
+}
+
+// ToMap mengubah batch menjadi map untuk scope ZenoLang
+func (b *Batch) ToMap() map[string]interface{} {
+	m := map[string]interface{}{
+		"id":             b.ID,
+		"name":           b.Name,
+		"queue":          b.Queue,
+		"total_jobs":     b.Total,
+		"pending_jobs":   b.Pending,
+		"failed_jobs":    b.Failed,
+		"processed_jobs": b.Processed(),
+		"progress":       b.Progress(),
+		"finished":       !b.FinishedAt.IsZero(),
+		"created_at":     b.CreatedAt,
+		"finished_at":    nil,
+	}
+	if !b.FinishedAt.IsZero() {
+		m["finished_at"] = b.FinishedAt
+	}
+	return m
+}
+
+// BatchStore menyimpan progress batch di tabel job_batches
+type BatchStore struct {
+	dbMgr    *dbmanager.DBManager
+	connName string
+
+	schemaMu    sync.Mutex
+	schemaReady bool
+}
+
+func NewBatchStore(dbMgr *dbmanager.DBManager, connName string) *BatchStore {
+	return &BatchStore{dbMgr: dbMgr, connName: connName}
+}
+
+func (s *BatchStore) ensureSchema(ctx context.Context) (*sql.DB, dbmanager.Dialect, error) {
+	db := s.dbMgr.GetConnection(s.connName)
+	if db == nil {
+		return nil, nil, fmt.Errorf("batch database connection '%s' not found", s.connName)
+	}
+	dialect := s.dbMgr.GetDialect(s.connName)
+
+	s.schemaMu.Lock()
+	defer s.schemaMu.Unlock()
+	if !s.schemaReady {
+		if err := ensureQueueTable(ctx, db, dialect, "job_batches", batchColumns); err != nil {
+			return nil, nil, err
+		}
+		s.schemaReady = true
+	}
+	return db, dialect, nil
+}
+
+// Create mencatat batch baru dengan total job yang akan di-push
+func (s *BatchStore) Create(ctx context.Context, name, queue string, total int, opts BatchOptions) (int64, error) {
+	db, dialect, err := s.ensureSchema(ctx)
+	if err != nil {
+		return 0, err
+	}
+	options, err := json.Marshal(opts)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal batch options: %v", err)
+	}
+
+	id, err := insertRowID(ctx, db, dialect, "job_batches",
+		[]string{"name", "queue", "total_jobs", "pending_jobs", "failed_jobs", "options", "created_at"},
+		[]interface{}{name, queue, total, total, 0, string(options), time.Now()})
+	if err != nil {
+		return 0, fmt.Errorf("failed to create batch: %w", err)
+	}
+	return id, nil
+}
+
+// Find mengembalikan batch, atau nil bila tidak ada
+func (s *BatchStore) Find(ctx context.Context, id int64) (*Batch, error) {
+	db, dialect, err := s.ensureSchema(ctx)
+	if err != nil {
+		return nil, err
+	}
+	query := fmt.Sprintf("SELECT %s, %s, %s, %s, %s, %s, %s, %s, %s FROM %s WHERE %s = %s",
+		dialect.QuoteIdentifier("id"), dialect.QuoteIdentifier("name"), dialect.QuoteIdentifier("queue"),
+		dialect.QuoteIdentifier("total_jobs"), dialect.QuoteIdentifier("pending_jobs"), dialect.QuoteIdentifier("failed_jobs"),
+		dialect.QuoteIdentifier("options"), dialect.QuoteIdentifier("created_at"), dialect.QuoteIdentifier("finished_at"),
+		dialect.QuoteIdentifier("job_batches"),
+		dialect.QuoteIdentifier("id"), dialect.Placeholder(1))
+
+	b := &Batch{}
+	var name, options sql.NullString
+	var createdAt, finishedAt interface{}
+	err = db.QueryRowContext(ctx, query, id).Scan(&b.ID, &name, &b.Queue, &b.Total, &b.Pending, &b.Failed, &options, &createdAt, &finishedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	b.Name = name.String
+	b.CreatedAt, b.FinishedAt = toTime(createdAt), toTime(finishedAt)
+	if options.String != "" {
+		json.Unmarshal([]byte(options.String), &b.Options)
+	}
+	return b, nil
+}
+
+// Discard mengurangi job batch yang ternyata tidak berhasil di-push. Job lain yang sudah di-push
+// bisa saja sudah selesai semua; finished bernilai true bila batch selesai karena pengurangan ini.
+func (s *BatchStore) Discard(ctx context.Context, id int64, count int) (batch *Batch, finished bool, err error) {
+	db, dialect, err := s.ensureSchema(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	query := fmt.Sprintf("UPDATE %s SET %s = %s - %s, %s = %s - %s WHERE %s = %s",
+		dialect.QuoteIdentifier("job_batches"),
+		dialect.QuoteIdentifier("total_jobs"), dialect.QuoteIdentifier("total_jobs"), dialect.Placeholder(1),
+		dialect.QuoteIdentifier("pending_jobs"), dialect.QuoteIdentifier("pending_jobs"), dialect.Placeholder(2),
+		dialect.QuoteIdentifier("id"), dialect.Placeholder(3))
+	if _, err = db.ExecContext(ctx, query, count, count, id); err != nil {
+		return nil, false, err
+	}
+	if finished, err = s.markOnce(ctx, db, dialect, id, "finished_at", fmt.Sprintf(" AND %s <= 0", dialect.QuoteIdentifier("pending_jobs"))); err != nil {
+		return nil, false, err
+	}
+	batch, err = s.Find(ctx, id)
+	if err == nil && batch == nil {
+		err = fmt.Errorf("batch %d not found", id)
+	}
+	return batch, finished, err
+}
+
+// markOnce mengisi kolom waktu bila masih NULL (dan cond terpenuhi); true bila pemanggil ini yang mengisinya
+func (s *BatchStore) markOnce(ctx context.Context, db *sql.DB, dialect dbmanager.Dialect, id int64, column, cond string) (bool, error) {
+	query := fmt.Sprintf("UPDATE %s SET %s = %s WHERE %s = %s AND %s IS NULL%s",
+		dialect.QuoteIdentifier("job_batches"),
+		dialect.QuoteIdentifier(column), dialect.Placeholder(1),
+		dialect.QuoteIdentifier("id"), dialect.Placeholder(2),
+		dialect.QuoteIdentifier(column), cond)
+	res, err := db.ExecContext(ctx, query, time.Now(), id)
+	if err != nil {
+		return false, err
+	}
+	affected, _ := res.RowsAffected()
+	return affected == 1, nil
+}
+
+// jobFinished mencatat satu job batch yang selesai. firstFailure bernilai true hanya untuk
+// kegagalan pertama, finished hanya untuk job terakhir; keduanya ditentukan lewat UPDATE
+// bersyarat sehingga callback tidak terpanggil dua kali oleh worker yang berbeda.
+func (s *BatchStore) jobFinished(ctx context.Context, id int64, failed bool) (batch *Batch, firstFailure, finished bool, err error) {
+	db, dialect, err := s.ensureSchema(ctx)
+	if err != nil {
+		return nil, false, false, err
+	}
+
+	failedInc := 0
+	if failed {
+		failedInc = 1
+	}
+	query := fmt.Sprintf("UPDATE %s SET %s = %s - 1, %s = %s + %s WHERE %s = %s",
+		dialect.QuoteIdentifier("job_batches"),
+		dialect.QuoteIdentifier("pending_jobs"), dialect.QuoteIdentifier("pending_jobs"),
+		dialect.QuoteIdentifier("failed_jobs"), dialect.QuoteIdentifier("failed_jobs"), dialect.Placeholder(1),
+		dialect.QuoteIdentifier("id"), dialect.Placeholder(2))
+	if _, err := db.ExecContext(ctx, query, failedInc, id); err != nil {
+		return nil, false, false, err
+	}
+
+	if failed {
+		if firstFailure, err = s.markOnce(ctx, db, dialect, id, "failed_at", ""); err != nil {
+			return nil, false, false, err
+		}
+	}
+	if finished, err = s.markOnce(ctx, db, dialect, id, "finished_at", fmt.Sprintf(" AND %s <= 0", dialect.QuoteIdentifier("pending_jobs"))); err != nil {
+		return nil, false, false, err
+	}
+
+	batch, err = s.Find(ctx, id)
+	if err == nil && batch == nil {
+		err = fmt.Errorf("batch %d not found", id)
+	}
+	return batch, firstFailure, finished, err
+}
+
+// DiscardBatchJobs mengeluarkan count job yang tidak berhasil di-push dari batch. Bila job yang
+// sudah di-push ternyata sudah selesai semua, callback then/finally di-push di sini.
+func DiscardBatchJobs(ctx context.Context, queue BatchQueue, id int64, count int) error {
+	batch, finished, err := queue.Batches().Discard(ctx, id, count)
+	if err != nil {
+		return err
+	}
+	// Batch yang tidak satu pun job-nya ter-push tidak menjalankan callback
+	if finished && batch.Total > 0 {
+		batchCallbacks(ctx, queue, batch, false, true, JobPayload{}, nil)
+	}
+	return nil
+}
+
+// finishBatchJob mencatat hasil job batch lalu mem-push callback then/catch/finally yang jatuh tempo
+func finishBatchJob(ctx context.Context, queue JobQueue, payload JobPayload, cause error) {
+	bq, ok := queue.(BatchQueue)
+	if !ok {
+		return
+	}
+	batch, firstFailure, finished, err := bq.Batches().jobFinished(ctx, payload.BatchID, cause != nil)
+	if err != nil {
+		slog.Error("❌ Failed to update batch", "batch", payload.BatchID, "error", err)
+		return
+	}
+	batchCallbacks(ctx, queue, batch, firstFailure, finished, payload, cause)
+}
+
+// batchCallbacks mem-push catch untuk kegagalan pertama, lalu then/finally bila batch selesai
+func batchCallbacks(ctx context.Context, queue JobQueue, batch *Batch, firstFailure, finished bool, payload JobPayload, cause error) {
+	callback := func(script, handler string, extra map[string]interface{}) {
+		if script == "" && handler == "" {
+			return
+		}
+		data := copyData(batch.Options.Data)
+		data["batch"] = batch.ToMap()
+		for k, v := range extra {
+			data[k] = v
+		}
+		job := JobPayload{ScriptPath: script, Handler: handler, Data: data}
+		if err := Enqueue(ctx, queue, batch.Queue, job, JobOptions{}); err != nil {
+			slog.Error("❌ Failed to push batch callback", "batch", batch.ID, "job", job.Name(), "error", err)
+		}
+	}
+
+	opts := batch.Options
+	if firstFailure {
+		callback(opts.Catch, opts.CatchHandler, failureData(payload, cause))
+	}
+	if finished {
+		slog.Info("📦 Batch finished", "batch", batch.ID, "total", batch.Total, "failed", batch.Failed)
+		if batch.Failed == 0 {
+			callback(opts.Then, opts.ThenHandler, nil)
+		}
+		callback(opts.Finally, opts.FinallyHandler, nil)
+	}
+}
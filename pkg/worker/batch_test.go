@@ -0,0 +1,52 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiscardBatchJobs(t *testing.T) {
+	ctx := context.Background()
+	queue, db := newTestQueue(t)
+	opts := BatchOptions{Then: "then.zl", Finally: "finally.zl"}
+
+	callbacks := func() []string {
+		rows, err := db.Query("SELECT payload FROM jobs ORDER BY id")
+		assert.NoError(t, err)
+		defer rows.Close()
+		var scripts []string
+		for rows.Next() {
+			var payload string
+			assert.NoError(t, rows.Scan(&payload))
+			var p JobPayload
+			json.Unmarshal([]byte(payload), &p)
+			scripts = append(scripts, p.ScriptPath)
+		}
+		return scripts
+	}
+
+	// Job pertama sudah selesai sebelum push job kedua gagal: batch selesai saat sisanya dikeluarkan
+	id, err := queue.Batches().Create(ctx, "import", "default", 3, opts)
+	assert.NoError(t, err)
+	finishBatchJob(ctx, queue, JobPayload{BatchID: id}, nil)
+	batch, err := queue.Batches().Find(ctx, id)
+	assert.NoError(t, err)
+	assert.True(t, batch.FinishedAt.IsZero())
+
+	assert.NoError(t, DiscardBatchJobs(ctx, queue, id, 2))
+	batch, err = queue.Batches().Find(ctx, id)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, batch.Total)
+	assert.Equal(t, 0, batch.Pending)
+	assert.False(t, batch.FinishedAt.IsZero())
+	assert.Equal(t, []string{"then.zl", "finally.zl"}, callbacks())
+
+	// Batch tanpa job yang ter-push tidak menjalankan callback
+	empty, err := queue.Batches().Create(ctx, "empty", "default", 2, opts)
+	assert.NoError(t, err)
+	assert.NoError(t, DiscardBatchJobs(ctx, queue, empty, 2))
+	assert.Len(t, callbacks(), 2)
+}
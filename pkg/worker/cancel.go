@@ -0,0 +1,135 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// CancelPollInterval adalah jeda worker memeriksa apakah job yang sedang berjalan dibatalkan
+var CancelPollInterval = time.Second
+
+// Cancel membatalkan job berdasarkan ID. Job yang belum berjalan (termasuk yang tertunda atau
+// menunggu retry) langsung dipindahkan ke failed_jobs: chain berhenti dan batch mencatat kegagalannya.
+// Job yang sedang berjalan hanya ditandai; worker-nya membatalkan context job dalam CancelPollInterval.
+func (q *DBQueue) Cancel(ctx context.Context, id int64) (bool, error) {
+	db, dialect, err := q.ensureSchema(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s = %s AND %s = %s",
+		jobColumns(dialect, ""),
+		dialect.QuoteIdentifier("jobs"),
+		dialect.QuoteIdentifier("id"), dialect.Placeholder(1),
+		dialect.QuoteIdentifier("status"), dialect.Placeholder(2))
+	job, err := scanJob(db.QueryRowContext(ctx, query, id, "pending"))
+	switch {
+	case err == nil:
+		cond := fmt.Sprintf("%s = %s AND %s = %s",
+			dialect.QuoteIdentifier("id"), dialect.Placeholder(1),
+			dialect.QuoteIdentifier("status"), dialect.Placeholder(2))
+		moved, err := q.moveToFailed(ctx, db, dialect, job, ErrJobCancelled.Error(), "", cond, []interface{}{id, "pending"})
+		if err != nil {
+			return false, err
+		}
+		if moved {
+			var payload JobPayload
+			if json.Unmarshal(job.Payload, &payload) == nil {
+				afterJob(ctx, q, job, payload, ErrJobCancelled)
+			}
+			return true, nil
+		}
+		// Baru saja di-claim worker: ditandai seperti job yang sedang berjalan
+	case !errors.Is(err, sql.ErrNoRows):
+		return false, err
+	}
+
+	query = fmt.Sprintf("UPDATE %s SET %s = 1 WHERE %s = %s AND %s = %s",
+		dialect.QuoteIdentifier("jobs"),
+		dialect.QuoteIdentifier("cancelled"),
+		dialect.QuoteIdentifier("id"), dialect.Placeholder(1),
+		dialect.QuoteIdentifier("status"), dialect.Placeholder(2))
+	res, err := db.ExecContext(ctx, query, id, "processing")
+	if err != nil {
+		return false, err
+	}
+	affected, _ := res.RowsAffected()
+	return affected == 1, nil
+}
+
+// Cancelled mengembalikan ID job di ids yang ditandai oleh Cancel
+func (q *DBQueue) Cancelled(ctx context.Context, ids []int64) ([]int64, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	db, dialect, err := q.conn()
+	if err != nil {
+		return nil, err
+	}
+
+	a := &sqlArgs{dialect: dialect}
+	placeholders := make([]string, len(ids))
+	for i, id := range ids {
+		placeholders[i] = a.add(id)
+	}
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s = 1 AND %s IN (%s)",
+		dialect.QuoteIdentifier("id"),
+		dialect.QuoteIdentifier("jobs"),
+		dialect.QuoteIdentifier("cancelled"),
+		dialect.QuoteIdentifier("id"), strings.Join(placeholders, ","))
+	rows, err := db.QueryContext(ctx, query, a.values...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var cancelled []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		cancelled = append(cancelled, id)
+	}
+	return cancelled, rows.Err()
+}
+
+// watchCancelled membatalkan context job yang sedang berjalan bila job-nya ditandai oleh Cancel,
+// sampai ctx dibatalkan. running mengembalikan ID job yang sedang berjalan beserta fungsi pembatalnya.
+func watchCancelled(ctx context.Context, queue CancellableQueue, running func() map[int64]context.CancelCauseFunc) {
+	ticker := time.NewTicker(CancelPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		active := running()
+		if len(active) == 0 {
+			continue
+		}
+		ids := make([]int64, 0, len(active))
+		for id := range active {
+			ids = append(ids, id)
+		}
+		cancelled, err := queue.Cancelled(ctx, ids)
+		if err != nil {
+			if ctx.Err() == nil {
+				slog.Error("❌ Failed to check cancelled jobs", "error", err)
+			}
+			continue
+		}
+		for _, id := range cancelled {
+			slog.Warn("🛑 Cancelling job", "id", id)
+			active[id](ErrJobCancelled)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// ChainStep adalah satu job dalam chain atau batch yang belum di-push
+type ChainStep struct {
+	Queue      string                 `json:"queue,omitempty"`
+	ScriptPath string                 `json:"script_path,omitempty"`
+	Handler    string                 `json:"handler,omitempty"` // Nama job.define, menggantikan ScriptPath
+	Data       map[string]interface{} `json:"data,omitempty"`
+	Tries      int                    `json:"tries,omitempty"`
+	Backoff    string                 `json:"backoff,omitempty"`
+	Timeout    int                    `json:"timeout,omitempty"` // Detik
+}
+
+// Options mengembalikan opsi retry langkah ini
+func (s ChainStep) Options() JobOptions {
+	opts := JobOptions{Tries: s.Tries, Timeout: time.Duration(s.Timeout) * time.Second}
+	if b, err := ParseBackoff(s.Backoff); err == nil {
+		opts.Backoff = b
+	}
+	return opts
+}
+
+// Payload mengembalikan payload job untuk langkah ini
+func (s ChainStep) Payload() JobPayload {
+	return JobPayload{ScriptPath: s.ScriptPath, Handler: s.Handler, Data: s.Data}
+}
+
+// NewChainStep menyimpan opsi retry ke bentuk yang bisa diserialisasi di payload
+func NewChainStep(queue, script string, data map[string]interface{}, opts JobOptions) ChainStep {
+	step := ChainStep{Queue: queue, ScriptPath: script, Data: data, Tries: opts.Tries, Timeout: int(opts.Timeout / time.Second)}
+	if opts.Backoff != (Backoff{}) {
+		step.Backoff = opts.Backoff.String()
+	}
+	return step
+}
+
+// Enqueue menyimpan payload ke queue. Opsi retry hanya didukung ReliableQueue.
+func Enqueue(ctx context.Context, queue JobQueue, queueName string, payload JobPayload, opts JobOptions) error {
+	_, err := EnqueueID(ctx, queue, queueName, payload, opts)
+	return err
+}
+
+// EnqueueID seperti Enqueue, lalu mengembalikan ID job baru (0 untuk queue yang tidak melacak job)
+func EnqueueID(ctx context.Context, queue JobQueue, queueName string, payload JobPayload, opts JobOptions) (int64, error) {
+	if payload.CreatedAt.IsZero() {
+		payload.CreatedAt = time.Now()
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal job payload: %v", err)
+	}
+	if reliable, ok := queue.(ReliableQueue); ok {
+		return reliable.PushJob(ctx, queueName, data, opts)
+	}
+	if opts.Tries > 1 || opts.Timeout > 0 || !opts.AvailableAt.IsZero() || opts.Backoff != (Backoff{}) || opts.UniqueKey != "" || opts.RateKey != "" {
+		return 0, fmt.Errorf("this queue does not support tries/backoff/timeout/delay/unique_key/rate_key")
+	}
+	return 0, queue.Push(ctx, queueName, data)
+}
+
+// afterJob dijalankan sekali setelah hasil akhir job diketahui (sukses, atau gagal tanpa sisa percobaan):
+// melanjutkan atau menghentikan chain, lalu mencatat progress batch.
+func afterJob(ctx context.Context, queue JobQueue, job *Job, payload JobPayload, cause error) {
+	continueChain(ctx, queue, job, payload, cause)
+	if payload.BatchID != 0 {
+		finishBatchJob(ctx, queue, payload, cause)
+	}
+}
+
+// continueChain mem-push job berikutnya di chain, atau script catch bila job gagal
+func continueChain(ctx context.Context, queue JobQueue, job *Job, payload JobPayload, cause error) {
+	if cause == nil && len(payload.Chain) > 0 {
+		next := payload.Chain[0]
+		if next.Queue == "" {
+			next.Queue = job.Queue
+		}
+		nextPayload := next.Payload()
+		nextPayload.Chain = payload.Chain[1:]
+		nextPayload.ChainCatch = payload.ChainCatch
+		nextPayload.ChainCatchHandler = payload.ChainCatchHandler
+		if err := Enqueue(ctx, queue, next.Queue, nextPayload, next.Options()); err != nil {
+			slog.Error("❌ Failed to push next job of chain", "job", nextPayload.Name(), "error", err)
+		}
+	}
+
+	// Chain berhenti pada job yang gagal; langkah berikutnya tidak pernah di-push
+	if cause != nil && (payload.ChainCatch != "" || payload.ChainCatchHandler != "") {
+		data := copyData(payload.Data)
+		for k, v := range failureData(payload, cause) {
+			data[k] = v
+		}
+		catch := JobPayload{ScriptPath: payload.ChainCatch, Handler: payload.ChainCatchHandler, Data: data}
+		if err := Enqueue(ctx, queue, job.Queue, catch, JobOptions{}); err != nil {
+			slog.Error("❌ Failed to push chain catch job", "job", catch.Name(), "error", err)
+		}
+	}
+}
+
+// failureData adalah variabel untuk callback catch: $error, dan $failed_script atau
+// $failed_handler dari job yang gagal (yang lain berisi string kosong)
+func failureData(payload JobPayload, cause error) map[string]interface{} {
+	return map[string]interface{}{
+		"error":          cause.Error(),
+		"failed_script":  payload.ScriptPath,
+		"failed_handler": payload.Handler,
+	}
+}
+
+func copyData(data map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(data)+2)
+	for k, v := range data {
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,271 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/nextcore/zenoengine/pkg/dbmanager"
+)
+
+// sqlArgs mengumpulkan argumen query sesuai urutan kemunculannya di SQL.
+// Placeholder "?" (SQLite/MySQL) bersifat posisional, jadi add harus dipanggil berurutan.
+type sqlArgs struct {
+	dialect dbmanager.Dialect
+	values  []interface{}
+}
+
+func (a *sqlArgs) add(v interface{}) string {
+	a.values = append(a.values, v)
+	return a.dialect.Placeholder(len(a.values))
+}
+
+// jobColumns adalah kolom yang dibaca untuk membentuk Job, dengan prefix opsional (mis. "inserted.")
+func jobColumns(dialect dbmanager.Dialect, prefix string) string {
+	cols := []string{"id", "queue", "payload", "attempts", "max_tries", "backoff", "timeout", "rate_key", "cancelled"}
+	for i, c := range cols {
+		cols[i] = prefix + dialect.QuoteIdentifier(c)
+	}
+	return strings.Join(cols, ", ")
+}
+
+// rowScanner adalah *sql.Row atau *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanJob membaca baris jobColumns
+func scanJob(row rowScanner) (*Job, error) {
+	job := &Job{}
+	var backoff, rateKey sql.NullString
+	var timeout, cancelled int
+	if err := row.Scan(&job.ID, &job.Queue, &job.Payload, &job.Attempts, &job.Options.Tries, &backoff, &timeout, &rateKey, &cancelled); err != nil {
+		return nil, err
+	}
+	job.Cancelled = cancelled != 0
+	job.Options.RateKey = rateKey.String
+	job.Options.Timeout = time.Duration(timeout) * time.Second
+	if b, err := ParseBackoff(backoff.String); err == nil {
+		job.Options.Backoff = b
+	}
+	return job, nil
+}
+
+// availableFilter adalah kondisi WHERE untuk job pending yang sudah tersedia di queues,
+// tanpa job dari queue atau dengan RateKey yang rate limit-nya sedang habis (exhausted)
+func availableFilter(dialect dbmanager.Dialect, a *sqlArgs, queues []string, exhausted []string, now time.Time) string {
+	return fmt.Sprintf("%s = %s AND %s <= %s%s%s",
+		dialect.QuoteIdentifier("status"), a.add("pending"),
+		dialect.QuoteIdentifier("available_at"), a.add(now.Unix()),
+		queueFilter(dialect, a, queues), rateFilter(dialect, a, exhausted))
+}
+
+// rateFilter melewati job yang queue atau RateKey-nya ada di exhausted
+func rateFilter(dialect dbmanager.Dialect, a *sqlArgs, exhausted []string) string {
+	if len(exhausted) == 0 {
+		return ""
+	}
+	names := func() string {
+		placeholders := make([]string, len(exhausted))
+		for i, name := range exhausted {
+			placeholders[i] = a.add(name)
+		}
+		return strings.Join(placeholders, ",")
+	}
+	queueNames := names()
+	keyNames := names()
+	return fmt.Sprintf(" AND %s NOT IN (%s) AND (%s IS NULL OR %s NOT IN (%s))",
+		dialect.QuoteIdentifier("queue"), queueNames,
+		dialect.QuoteIdentifier("rate_key"), dialect.QuoteIdentifier("rate_key"), keyNames)
+}
+
+// queueFilter membatasi ke queues; kosong berarti semua queue
+func queueFilter(dialect dbmanager.Dialect, a *sqlArgs, queues []string) string {
+	if len(queues) == 0 {
+		return ""
+	}
+	placeholders := make([]string, len(queues))
+	for i, qName := range queues {
+		placeholders[i] = a.add(qName)
+	}
+	return fmt.Sprintf(" AND %s IN (%s)", dialect.QuoteIdentifier("queue"), strings.Join(placeholders, ","))
+}
+
+// claimOrder mengurutkan job: berdasarkan posisi queue bila prioritized
+// (CASE queue WHEN 'high' THEN 0 WHEN 'default' THEN 1 END), lalu job terlama
+func claimOrder(dialect dbmanager.Dialect, a *sqlArgs, queues []string, prioritized bool) string {
+	orderBy := dialect.QuoteIdentifier("id") + " ASC"
+	if prioritized && len(queues) > 1 {
+		cases := make([]string, len(queues))
+		for i, qName := range queues {
+			cases[i] = fmt.Sprintf("WHEN %s THEN %d", a.add(qName), i)
+		}
+		orderBy = fmt.Sprintf("CASE %s %s END, %s", dialect.QuoteIdentifier("queue"), strings.Join(cases, " "), orderBy)
+	}
+	return orderBy
+}
+
+// claimSet adalah SET untuk menandai job sebagai sedang diproses
+func claimSet(dialect dbmanager.Dialect, a *sqlArgs, now time.Time, lease time.Duration) string {
+	return fmt.Sprintf("%s = %s, %s = %s, %s = %s, %s = %s + 1",
+		dialect.QuoteIdentifier("status"), a.add("processing"),
+		dialect.QuoteIdentifier("processed_at"), a.add(now),
+		dialect.QuoteIdentifier("reserved_until"), a.add(reservedUntil(now, lease)),
+		dialect.QuoteIdentifier("attempts"), dialect.QuoteIdentifier("attempts"))
+}
+
+// claim mengambil satu job pending yang sudah tersedia secara atomik, atau nil bila tidak ada.
+// prioritized mengurutkan berdasarkan posisi queue di daftar sebelum id; exhausted adalah rate limit yang habis.
+// lease 0 berarti tanpa reservasi (reserved_until = 0).
+func (q *DBQueue) claim(ctx context.Context, queues []string, prioritized bool, lease time.Duration, exhausted []string) (*Job, error) {
+	db, dialect, err := q.conn()
+	if err != nil {
+		return nil, err
+	}
+
+	var job *Job
+	switch dialect.Name() {
+	case "sqlite", "postgres":
+		job, err = claimReturning(ctx, db, dialect, queues, prioritized, lease, exhausted)
+	case "sqlserver":
+		job, err = claimReadPast(ctx, db, dialect, queues, prioritized, lease, exhausted)
+	case "mysql":
+		if q.noSkipLocked.Load() {
+			job, err = claimOptimistic(ctx, db, dialect, queues, prioritized, lease, exhausted)
+			break
+		}
+		job, err = claimSkipLocked(ctx, db, dialect, queues, prioritized, lease, exhausted)
+		// MySQL < 8.0 / MariaDB < 10.6 belum mengenal SKIP LOCKED
+		var myErr *mysql.MySQLError
+		if errors.As(err, &myErr) && myErr.Number == 1064 {
+			q.noSkipLocked.Store(true)
+			job, err = claimOptimistic(ctx, db, dialect, queues, prioritized, lease, exhausted)
+		}
+	default:
+		job, err = claimOptimistic(ctx, db, dialect, queues, prioritized, lease, exhausted)
+	}
+	if err != nil || job == nil {
+		return nil, err
+	}
+	job.Lease = lease
+	return job, nil
+}
+
+// claimReturning: SQLite & Postgres memilih dan menandai job dalam satu UPDATE ... RETURNING.
+// Postgres melewati baris yang sedang di-claim worker lain (FOR UPDATE SKIP LOCKED);
+// SQLite menjalankan satu penulisan pada satu waktu, jadi UPDATE-nya sudah atomik.
+func claimReturning(ctx context.Context, db *sql.DB, dialect dbmanager.Dialect, queues []string, prioritized bool, lease time.Duration, exhausted []string) (*Job, error) {
+	now := time.Now()
+	a := &sqlArgs{dialect: dialect}
+	set := claimSet(dialect, a, now, lease)
+	filter := availableFilter(dialect, a, queues, exhausted, now)
+	order := claimOrder(dialect, a, queues, prioritized)
+	lock := ""
+	if dialect.Name() == "postgres" {
+		lock = " FOR UPDATE SKIP LOCKED"
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE %s = (SELECT %s FROM %s WHERE %s ORDER BY %s%s%s) RETURNING %s",
+		dialect.QuoteIdentifier("jobs"), set,
+		dialect.QuoteIdentifier("id"), dialect.QuoteIdentifier("id"), dialect.QuoteIdentifier("jobs"),
+		filter, order, dialect.Limit(1, 0), lock,
+		jobColumns(dialect, ""))
+
+	job, err := scanJob(db.QueryRowContext(ctx, query, a.values...))
+	if err == sql.ErrNoRows {
+		return nil, nil // Tidak ada tugas
+	}
+	return job, err
+}
+
+// claimReadPast: SQL Server mengunci satu baris lewat CTE dan melewati baris yang
+// terkunci worker lain (READPAST), lalu mengembalikan hasilnya dengan OUTPUT.
+func claimReadPast(ctx context.Context, db *sql.DB, dialect dbmanager.Dialect, queues []string, prioritized bool, lease time.Duration, exhausted []string) (*Job, error) {
+	now := time.Now()
+	a := &sqlArgs{dialect: dialect}
+	filter := availableFilter(dialect, a, queues, exhausted, now)
+	order := claimOrder(dialect, a, queues, prioritized)
+	set := claimSet(dialect, a, now, lease)
+
+	query := fmt.Sprintf("WITH next_job AS (SELECT TOP (1) * FROM %s WITH (UPDLOCK, READPAST, ROWLOCK) WHERE %s ORDER BY %s) UPDATE next_job SET %s OUTPUT %s",
+		dialect.QuoteIdentifier("jobs"), filter, order, set, jobColumns(dialect, "inserted."))
+
+	job, err := scanJob(db.QueryRowContext(ctx, query, a.values...))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return job, err
+}
+
+// claimSkipLocked: MySQL 8 mengunci baris dengan SELECT ... FOR UPDATE SKIP LOCKED lalu
+// menandainya di transaksi yang sama; worker lain langsung mengambil baris berikutnya.
+func claimSkipLocked(ctx context.Context, db *sql.DB, dialect dbmanager.Dialect, queues []string, prioritized bool, lease time.Duration, exhausted []string) (*Job, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	a := &sqlArgs{dialect: dialect}
+	filter := availableFilter(dialect, a, queues, exhausted, now)
+	order := claimOrder(dialect, a, queues, prioritized)
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s ORDER BY %s%s FOR UPDATE SKIP LOCKED",
+		jobColumns(dialect, ""), dialect.QuoteIdentifier("jobs"), filter, order, dialect.Limit(1, 0))
+
+	job, err := scanJob(tx.QueryRowContext(ctx, query, a.values...))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	u := &sqlArgs{dialect: dialect}
+	update := fmt.Sprintf("UPDATE %s SET %s WHERE %s = %s",
+		dialect.QuoteIdentifier("jobs"), claimSet(dialect, u, now, lease), dialect.QuoteIdentifier("id"), u.add(job.ID))
+	if _, err := tx.ExecContext(ctx, update, u.values...); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	job.Attempts++
+	return job, nil
+}
+
+// claimOptimistic untuk database tanpa SKIP LOCKED: SELECT lalu UPDATE bersyarat status.
+// Bila worker lain lebih dulu meng-claim job yang sama, UPDATE tidak mengubah baris apa pun.
+func claimOptimistic(ctx context.Context, db *sql.DB, dialect dbmanager.Dialect, queues []string, prioritized bool, lease time.Duration, exhausted []string) (*Job, error) {
+	now := time.Now()
+	a := &sqlArgs{dialect: dialect}
+	filter := availableFilter(dialect, a, queues, exhausted, now)
+	order := claimOrder(dialect, a, queues, prioritized)
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s ORDER BY %s%s",
+		jobColumns(dialect, ""), dialect.QuoteIdentifier("jobs"), filter, order, dialect.Limit(1, 0))
+
+	job, err := scanJob(db.QueryRowContext(ctx, query, a.values...))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	u := &sqlArgs{dialect: dialect}
+	update := fmt.Sprintf("UPDATE %s SET %s WHERE %s = %s AND %s = %s",
+		dialect.QuoteIdentifier("jobs"), claimSet(dialect, u, now, lease),
+		dialect.QuoteIdentifier("id"), u.add(job.ID),
+		dialect.QuoteIdentifier("status"), u.add("pending"))
+	res, err := db.ExecContext(ctx, update, u.values...)
+	if err != nil {
+		return nil, err
+	}
+	if affected, _ := res.RowsAffected(); affected != 1 {
+		return nil, nil
+	}
+	job.Attempts++
+	return job, nil
+}
@@ -0,0 +1,57 @@
+package worker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCron(t *testing.T) {
+	at := func(s string) time.Time {
+		v, err := time.Parse("2006-01-02 15:04", s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+
+	cases := []struct {
+		spec, from, want string
+	}{
+		{"0 2 * * *", "2026-03-10 01:30", "2026-03-10 02:00"},
+		{"0 2 * * *", "2026-03-10 02:00", "2026-03-11 02:00"},
+		{"*/15 * * * *", "2026-03-10 10:07", "2026-03-10 10:15"},
+		{"0 9 * * mon-fri", "2026-03-14 08:00", "2026-03-16 09:00"}, // Sabtu -> Senin
+		{"0 0 1,15 * *", "2026-03-02 00:00", "2026-03-15 00:00"},
+		{"0 0 13 * 5", "2026-03-01 00:00", "2026-03-06 00:00"}, // tanggal 13 ATAU hari Jumat
+		{"0 0 * * 7", "2026-03-14 12:00", "2026-03-15 00:00"},  // 7 = Minggu
+		{"30 4 * jan,jul *", "2026-03-10 00:00", "2026-07-01 04:30"},
+		{"0 0 29 2 *", "2026-03-01 00:00", "2028-02-29 00:00"},
+		{"@monthly", "2026-03-10 00:00", "2026-04-01 00:00"},
+		{"@hourly", "2026-03-10 10:59", "2026-03-10 11:00"},
+	}
+	for _, c := range cases {
+		s, err := ParseCron(c.spec, time.UTC)
+		if !assert.NoError(t, err, c.spec) {
+			continue
+		}
+		assert.Equal(t, at(c.want), s.Next(at(c.from)), "%s from %s", c.spec, c.from)
+	}
+
+	// Ekspresi dihitung di zona waktunya: 02:00 WIB = 19:00 UTC hari sebelumnya
+	wib := time.FixedZone("WIB", 7*3600)
+	s, err := ParseCron("0 2 * * *", wib)
+	assert.NoError(t, err)
+	assert.True(t, at("2026-03-10 19:00").Equal(s.Next(at("2026-03-10 00:00"))))
+
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * * 8", "5-1 * * * *", "*/0 * * * *", "abc * * * *", "@often"} {
+		_, err := ParseCron(spec, time.UTC)
+		assert.Error(t, err, spec)
+	}
+
+	// Every diselaraskan ke kelipatan interval
+	every := Every(5 * time.Minute)
+	assert.Equal(t, at("2026-03-10 10:10"), every.Next(at("2026-03-10 10:07").Add(30*time.Second)))
+	assert.Equal(t, at("2026-03-10 10:15"), every.Next(at("2026-03-10 10:10")))
+}
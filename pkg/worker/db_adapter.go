@@ -3,118 +3,742 @@ package worker
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
+	"github.com/lib/pq"
 	"github.com/nextcore/zenoengine/pkg/dbmanager"
 )
 
+// DefaultVisibilityTimeout adalah lama reservasi job sebelum dianggap ditinggal worker yang mati
+const DefaultVisibilityTimeout = time.Minute
+
+// DefaultPollInterval adalah jeda pemeriksaan job baru dari proses lain.
+// Job yang di-Push di proses yang sama membangunkan worker tanpa menunggu jeda ini.
+const DefaultPollInterval = time.Second
+
+// QueueConnection mengembalikan nama koneksi tempat tabel jobs disimpan: QUEUE_CONNECTION,
+// atau "internal" (SQLite lokal). Gunakan koneksi bersama (mis. Postgres) bila worker
+// berjalan di beberapa server.
+func QueueConnection() string {
+	if name := os.Getenv("QUEUE_CONNECTION"); name != "" {
+		return name
+	}
+	return "internal"
+}
+
 type DBQueue struct {
 	dbMgr    *dbmanager.DBManager
 	connName string
+
+	// VisibilityTimeout adalah masa berlaku reservasi (reserved_until). Worker memperpanjangnya
+	// lewat Heartbeat selama job berjalan; yang kedaluwarsa dikembalikan oleh ReleaseExpired.
+	VisibilityTimeout time.Duration
+
+	// PollInterval adalah jeda Reserve memeriksa ulang tabel jobs saat kosong
+	// (ListenPollInterval bila Postgres LISTEN aktif)
+	PollInterval time.Duration
+
+	// Tabel queue dibuat/di-upgrade sekali, saat pertama dipakai
+	schemaMu    sync.Mutex
+	schemaReady bool
+
+	// Reserve yang menunggu dibangunkan oleh Push di proses ini atau NOTIFY Postgres
+	wake       wakeup
+	listenOnce sync.Once
+	listener   *pq.Listener
+
+	// MySQL lama tanpa SKIP LOCKED memakai claim optimistic
+	noSkipLocked atomic.Bool
+
+	batchesOnce sync.Once
+	batches     *BatchStore
+
+	// Rate limit per queue / RateKey yang diperiksa saat claim (lihat SetRateLimits)
+	rateMu     sync.RWMutex
+	rateLimits map[string]Rate
 }
 
 func NewDBQueue(dbMgr *dbmanager.DBManager, connName string) *DBQueue {
 	return &DBQueue{
-		dbMgr:    dbMgr,
-		connName: connName,
+		dbMgr:             dbMgr,
+		connName:          connName,
+		VisibilityTimeout: DefaultVisibilityTimeout,
+		PollInterval:      DefaultPollInterval,
 	}
 }
 
-func (q *DBQueue) Push(ctx context.Context, queue string, payload []byte) error {
+// FailedJob adalah job yang dipindahkan ke tabel failed_jobs
+type FailedJob struct {
+	ID       int64     `json:"id"`
+	Queue    string    `json:"queue"`
+	Payload  string    `json:"payload"`
+	Attempts int       `json:"attempts"`
+	Error    string    `json:"error"`
+	Stack    string    `json:"stack"`
+	FailedAt time.Time `json:"failed_at"`
+}
+
+// Batches mengembalikan penyimpanan progress batch di koneksi yang sama dengan tabel jobs
+func (q *DBQueue) Batches() *BatchStore {
+	q.batchesOnce.Do(func() {
+		q.batches = NewBatchStore(q.dbMgr, q.connName)
+	})
+	return q.batches
+}
+
+func (q *DBQueue) conn() (*sql.DB, dbmanager.Dialect, error) {
 	db := q.dbMgr.GetConnection(q.connName)
-	dialect := q.dbMgr.GetDialect(q.connName)
+	if db == nil {
+		return nil, nil, fmt.Errorf("queue database connection '%s' not found", q.connName)
+	}
+	return db, q.dbMgr.GetDialect(q.connName), nil
+}
+
+// ensureSchema membuat tabel jobs, failed_jobs, job_locks dan job_rate_limits bila belum ada
+func (q *DBQueue) ensureSchema(ctx context.Context) (*sql.DB, dbmanager.Dialect, error) {
+	db, dialect, err := q.conn()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	q.schemaMu.Lock()
+	defer q.schemaMu.Unlock()
+	if q.schemaReady {
+		return db, dialect, nil
+	}
+	if err := ensureQueueTable(ctx, db, dialect, "jobs", jobsColumns, jobsIndexes...); err != nil {
+		return nil, nil, err
+	}
+	if err := ensureQueueTable(ctx, db, dialect, "failed_jobs", failedJobsColumns); err != nil {
+		return nil, nil, err
+	}
+	if err := ensureQueueTable(ctx, db, dialect, "job_locks", jobLocksColumns); err != nil {
+		return nil, nil, err
+	}
+	if err := ensureQueueTable(ctx, db, dialect, "job_rate_limits", jobRateLimitsColumns); err != nil {
+		return nil, nil, err
+	}
+	q.schemaReady = true
+	return db, dialect, nil
+}
+
+func (q *DBQueue) Push(ctx context.Context, queue string, payload []byte) error {
+	_, err := q.PushJob(ctx, queue, payload, JobOptions{})
+	return err
+}
+
+// PushJob menyimpan job baru beserta opsi retry-nya dan mengembalikan ID-nya (untuk Cancel)
+func (q *DBQueue) PushJob(ctx context.Context, queue string, payload []byte, opts JobOptions) (int64, error) {
+	db, dialect, err := q.ensureSchema(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if opts.Tries < 1 {
+		opts.Tries = 1
+	}
+	availableAt := time.Now()
+	if !opts.AvailableAt.IsZero() {
+		availableAt = opts.AvailableAt
+	}
+
+	cols := []string{"queue", "payload", "status", "created_at", "attempts", "max_tries", "backoff", "timeout", "available_at", "unique_key", "rate_key"}
+	args := []interface{}{queue, string(payload), "pending", time.Now(), 0, opts.Tries, backoffValue(opts.Backoff), int(opts.Timeout / time.Second), availableAt.Unix(),
+		emptyToNull(opts.UniqueKey), emptyToNull(opts.RateKey)}
+	var id int64
+	if opts.UniqueKey != "" {
+		id, err = q.pushUnique(ctx, db, dialect, opts, cols, args)
+	} else {
+		id, err = insertRowID(ctx, db, dialect, "jobs", cols, args)
+	}
+	if err != nil {
+		return 0, err
+	}
+	if !availableAt.After(time.Now()) {
+		q.announce(ctx, db, dialect, queue)
+	}
+	return id, nil
+}
+
+func emptyToNull(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+func backoffValue(b Backoff) interface{} {
+	if b == (Backoff{}) {
+		return nil
+	}
+	return b.String()
+}
+
+func insertRow(ctx context.Context, db interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}, dialect dbmanager.Dialect, table string, cols []string, args []interface{}) error {
+	quoted := make([]string, len(cols))
+	placeholders := make([]string, len(cols))
+	for i, c := range cols {
+		quoted[i] = dialect.QuoteIdentifier(c)
+		placeholders[i] = dialect.Placeholder(i + 1)
+	}
 
 	// Gunakan parameterized query standard
-	query := fmt.Sprintf("INSERT INTO %s (%s, %s, %s, %s) VALUES (%s, %s, %s, %s)",
-		dialect.QuoteIdentifier("jobs"),
-		dialect.QuoteIdentifier("queue"),
-		dialect.QuoteIdentifier("payload"),
-		dialect.QuoteIdentifier("status"),
-		dialect.QuoteIdentifier("created_at"),
-		dialect.Placeholder(1),
-		dialect.Placeholder(2),
-		dialect.Placeholder(3),
-		dialect.Placeholder(4))
-
-	// SQLite support datetime standard time.Time go
-	_, err := db.ExecContext(ctx, query, queue, payload, "pending", time.Now())
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
+		dialect.QuoteIdentifier(table), strings.Join(quoted, ", "), strings.Join(placeholders, ", "))
+	_, err := db.ExecContext(ctx, query, args...)
 	return err
 }
 
+// insertRowID seperti insertRow, lalu mengembalikan ID baris baru:
+// RETURNING (Postgres), OUTPUT (SQL Server), LastInsertId (lainnya)
+func insertRowID(ctx context.Context, db interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}, dialect dbmanager.Dialect, table string, cols []string, args []interface{}) (int64, error) {
+	quoted := make([]string, len(cols))
+	placeholders := make([]string, len(cols))
+	for i, c := range cols {
+		quoted[i] = dialect.QuoteIdentifier(c)
+		placeholders[i] = dialect.Placeholder(i + 1)
+	}
+	into := fmt.Sprintf("INSERT INTO %s (%s)", dialect.QuoteIdentifier(table), strings.Join(quoted, ", "))
+	values := fmt.Sprintf("VALUES (%s)", strings.Join(placeholders, ", "))
+
+	var id int64
+	switch dialect.Name() {
+	case "postgres":
+		err := db.QueryRowContext(ctx, fmt.Sprintf("%s %s RETURNING %s", into, values, dialect.QuoteIdentifier("id")), args...).Scan(&id)
+		return id, err
+	case "sqlserver":
+		err := db.QueryRowContext(ctx, fmt.Sprintf("%s OUTPUT INSERTED.%s %s", into, dialect.QuoteIdentifier("id"), values), args...).Scan(&id)
+		return id, err
+	default:
+		res, err := db.ExecContext(ctx, into+" "+values, args...)
+		if err != nil {
+			return 0, err
+		}
+		return res.LastInsertId()
+	}
+}
+
+// Pop mengambil job tanpa melacak hasilnya (dipakai consumer lama). Job tetap berstatus
+// processing tanpa reservasi, jadi tidak dikembalikan oleh ReleaseExpired; worker memakai
+// Reserve/Heartbeat/Complete/Release/Fail.
 func (q *DBQueue) Pop(ctx context.Context, queues []string) (string, []byte, error) {
-	db := q.dbMgr.GetConnection(q.connName)
-	dialect := q.dbMgr.GetDialect(q.connName)
+	job, err := q.reserve(ctx, queues, false, 0, nil)
+	if err != nil {
+		return "", nil, err
+	}
+	return job.Queue, job.Payload, nil
+}
+
+// Reserve menunggu (polling) sampai ada job yang tersedia lalu meng-claim-nya selama VisibilityTimeout
+func (q *DBQueue) Reserve(ctx context.Context, queues []string, prioritized bool) (*Job, error) {
+	return q.ReserveWake(ctx, queues, prioritized, nil)
+}
 
-	// Polling Interval
-	ticker := time.NewTicker(1 * time.Second)
-	defer ticker.Stop()
+// ReserveWake sama dengan Reserve, tetapi berhenti dengan ErrWoken bila wake menerima sinyal
+// saat sedang menunggu. Claim yang sedang berjalan tidak pernah diputus.
+func (q *DBQueue) ReserveWake(ctx context.Context, queues []string, prioritized bool, wake <-chan struct{}) (*Job, error) {
+	lease := q.VisibilityTimeout
+	if lease <= 0 {
+		lease = DefaultVisibilityTimeout
+	}
+	return q.reserve(ctx, queues, prioritized, lease, wake)
+}
+
+func (q *DBQueue) reserve(ctx context.Context, queues []string, prioritized bool, lease time.Duration, wake <-chan struct{}) (*Job, error) {
+	db, dialect, err := q.ensureSchema(ctx)
+	if err != nil {
+		return nil, err
+	}
+	listening := q.listen()
 
 	for {
-		select {
-		case <-ctx.Done():
-			return "", nil, ctx.Err()
-		case <-ticker.C:
-			// OPTIMISTIC LOCKING STRATEGY
-			// 1. Ambil 1 job pending
-			var id int
-			var queueName string
-			var payload []byte
-
-			// Build Filter Queue
-			whereQueue := ""
-			args := []interface{}{}
-			if len(queues) > 0 {
-				placeholders := make([]string, len(queues))
-				for i, qName := range queues {
-					placeholders[i] = dialect.Placeholder(i + 1)
-					args = append(args, qName)
-				}
-				whereQueue = fmt.Sprintf("AND %s IN (%s)", dialect.QuoteIdentifier("queue"), strings.Join(placeholders, ","))
+		// Ambil channel sebelum claim agar Push di antara claim dan penantian tidak terlewat
+		woken := q.wake.wait()
+
+		// Queue & RateKey yang jatahnya habis di window ini dilewati sampai window berikutnya
+		limits := q.currentRateLimits()
+		var exhausted []string
+		var reopens time.Time
+		if len(limits) > 0 {
+			if exhausted, reopens, err = rateExhausted(ctx, db, dialect, limits, time.Now()); err != nil {
+				return nil, err
 			}
+		}
 
-			querySelect := fmt.Sprintf("SELECT %s, %s, %s FROM %s WHERE %s = %s %s ORDER BY %s ASC %s",
-				dialect.QuoteIdentifier("id"),
-				dialect.QuoteIdentifier("queue"),
-				dialect.QuoteIdentifier("payload"),
-				dialect.QuoteIdentifier("jobs"),
-				dialect.QuoteIdentifier("status"),
-				dialect.Placeholder(len(args)+1),
-				whereQueue,
-				dialect.QuoteIdentifier("created_at"),
-				dialect.Limit(1, 0))
-
-			fullArgs := append(args, "pending")
-			err := db.QueryRowContext(ctx, querySelect, fullArgs...).Scan(&id, &queueName, &payload)
-			if err == sql.ErrNoRows {
-				continue // Tidak ada tugas, tunggu tick berikutnya
-			} else if err != nil {
-				return "", nil, err // Error DB serius
+		job, err := q.claim(ctx, queues, prioritized, lease, exhausted)
+		if err != nil {
+			return nil, err // Error DB serius
+		}
+		if job != nil {
+			taken, err := takeRates(ctx, db, dialect, limits, job)
+			if err == nil && taken {
+				return job, nil
+			}
+			// Worker lain menghabiskan jatahnya di antara pemeriksaan dan claim
+			if errUnclaim := q.unclaim(ctx, db, dialect, job); errUnclaim != nil && err == nil {
+				err = errUnclaim
+			}
+			if err != nil {
+				return nil, err
 			}
+			continue
+		}
+
+		delay := q.pollDelay(ctx, queues, listening)
+		if !reopens.IsZero() && time.Until(reopens) < delay {
+			delay = max(time.Until(reopens), 0)
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-wake:
+			timer.Stop()
+			return nil, ErrWoken
+		case <-woken:
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+}
+
+// pollDelay adalah lama menunggu sebelum claim berikutnya bila tidak ada yang membangunkan.
+// Dengan LISTEN, jedanya lebih panjang tetapi tidak melewati job tertunda berikutnya.
+func (q *DBQueue) pollDelay(ctx context.Context, queues []string, listening bool) time.Duration {
+	interval := q.PollInterval
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	if !listening {
+		return interval
+	}
+	interval = ListenPollInterval
+	if next := q.nextAvailable(ctx, queues); !next.IsZero() {
+		if until := time.Until(next); until < interval {
+			return max(until, 0)
+		}
+	}
+	return interval
+}
+
+func reservedUntil(now time.Time, lease time.Duration) int64 {
+	if lease <= 0 {
+		return 0
+	}
+	// Dibulatkan ke atas agar reservasi tidak pernah lebih pendek dari lease
+	return now.Add(lease + time.Second - 1).Unix()
+}
+
+// Heartbeat memperpanjang reservasi job yang masih berjalan. Mengembalikan ErrLeaseLost bila
+// reservasinya sudah kedaluwarsa dan job dikembalikan ke antrian.
+func (q *DBQueue) Heartbeat(ctx context.Context, job *Job) error {
+	db, dialect, err := q.conn()
+	if err != nil {
+		return err
+	}
+	cond, condArgs := ownedCond(dialect, job, 1)
+	query := fmt.Sprintf("UPDATE %s SET %s = %s WHERE %s",
+		dialect.QuoteIdentifier("jobs"),
+		dialect.QuoteIdentifier("reserved_until"), dialect.Placeholder(1), cond)
+	res, err := db.ExecContext(ctx, query, append([]interface{}{reservedUntil(time.Now(), job.Lease)}, condArgs...)...)
+	if err != nil {
+		return err
+	}
+	if affected, _ := res.RowsAffected(); affected != 1 {
+		return ErrLeaseLost
+	}
+	return nil
+}
+
+// ReleaseExpired mengembalikan job yang reservasinya kedaluwarsa (worker mati di tengah job) ke antrian.
+// Percobaan itu tetap dihitung: job yang sudah kehabisan percobaan dipindahkan ke failed_jobs.
+// Baris processing tanpa reservasi (reserved_until = 0, dari Pop atau versi lama) tidak disentuh.
+// Lock unique_key yang sudah tidak berlaku ikut dibersihkan.
+func (q *DBQueue) ReleaseExpired(ctx context.Context) (int, error) {
+	db, dialect, err := q.ensureSchema(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if err := purgeLocks(ctx, db, dialect); err != nil {
+		return 0, err
+	}
 
-			// 2. Coba claim (Atomic Update)
-			updateQuery := fmt.Sprintf("UPDATE %s SET %s = %s, %s = %s WHERE %s = %s AND %s = %s",
+	now := time.Now().Unix()
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s = %s AND %s > 0 AND %s < %s",
+		jobColumns(dialect, ""),
+		dialect.QuoteIdentifier("jobs"),
+		dialect.QuoteIdentifier("status"), dialect.Placeholder(1),
+		dialect.QuoteIdentifier("reserved_until"),
+		dialect.QuoteIdentifier("reserved_until"), dialect.Placeholder(2))
+	rows, err := db.QueryContext(ctx, query, "processing", now)
+	if err != nil {
+		return 0, err
+	}
+	var expired []*Job
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			rows.Close()
+			return 0, err
+		}
+		expired = append(expired, job)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	cause := errors.New("job reservation expired: the worker stopped during this attempt")
+	released := 0
+	for _, job := range expired {
+		// Kondisi diulang di UPDATE/DELETE agar dua reaper tidak memproses job yang sama.
+		// Placeholder "?" bersifat posisional, jadi nomor kondisi mengikuti argumen sebelumnya.
+		expiredCond := func(offset int) string {
+			return fmt.Sprintf("%s = %s AND %s = %s AND %s > 0 AND %s < %s",
+				dialect.QuoteIdentifier("id"), dialect.Placeholder(offset+1),
+				dialect.QuoteIdentifier("status"), dialect.Placeholder(offset+2),
+				dialect.QuoteIdentifier("reserved_until"),
+				dialect.QuoteIdentifier("reserved_until"), dialect.Placeholder(offset+3))
+		}
+		condArgs := []interface{}{job.ID, "processing", now}
+
+		if job.CanRetry() {
+			query := fmt.Sprintf("UPDATE %s SET %s = %s, %s = %s, %s = 0, %s = %s WHERE ",
 				dialect.QuoteIdentifier("jobs"),
 				dialect.QuoteIdentifier("status"), dialect.Placeholder(1),
-				dialect.QuoteIdentifier("processed_at"), dialect.Placeholder(2),
-				dialect.QuoteIdentifier("id"), dialect.Placeholder(3),
-				dialect.QuoteIdentifier("status"), dialect.Placeholder(4))
-
-			res, err := db.ExecContext(ctx, updateQuery, "processing", time.Now(), id, "pending")
+				dialect.QuoteIdentifier("available_at"), dialect.Placeholder(2),
+				dialect.QuoteIdentifier("reserved_until"),
+				dialect.QuoteIdentifier("last_error"), dialect.Placeholder(3)) + expiredCond(3)
+			args := append([]interface{}{"pending", time.Now().Add(job.Options.Backoff.After(job.Attempts)).Unix(), cause.Error()}, condArgs...)
+			res, err := db.ExecContext(ctx, query, args...)
 			if err != nil {
-				return "", nil, err
+				return released, err
 			}
+			if affected, _ := res.RowsAffected(); affected == 1 {
+				released++
+			}
+			continue
+		}
+
+		moved, err := q.moveToFailed(ctx, db, dialect, job, cause.Error(), "", expiredCond(0), condArgs)
+		if err != nil {
+			return released, err
+		}
+		if moved {
+			released++
+		}
+	}
+	return released, nil
+}
+
+// ownedCond adalah kondisi baris job yang masih dipegang percobaan ini: job yang reservasinya
+// kedaluwarsa dan diambil worker lain sudah berstatus pending atau memiliki attempts yang lebih besar.
+// offset adalah jumlah placeholder sebelum kondisi.
+func ownedCond(dialect dbmanager.Dialect, job *Job, offset int) (string, []interface{}) {
+	cond := fmt.Sprintf("%s = %s AND %s = %s AND %s = %s",
+		dialect.QuoteIdentifier("id"), dialect.Placeholder(offset+1),
+		dialect.QuoteIdentifier("status"), dialect.Placeholder(offset+2),
+		dialect.QuoteIdentifier("attempts"), dialect.Placeholder(offset+3))
+	return cond, []interface{}{job.ID, "processing", job.Attempts}
+}
 
-			affected, _ := res.RowsAffected()
-			if affected == 1 {
-				return queueName, payload, nil
+// Complete menghapus job yang sudah selesai. Mengembalikan ErrLeaseLost bila job sudah diambil worker lain.
+func (q *DBQueue) Complete(ctx context.Context, job *Job) error {
+	db, dialect, err := q.conn()
+	if err != nil {
+		return err
+	}
+	cond, args := ownedCond(dialect, job, 0)
+	res, err := db.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE %s", dialect.QuoteIdentifier("jobs"), cond), args...)
+	if err != nil {
+		return err
+	}
+	if affected, _ := res.RowsAffected(); affected != 1 {
+		return ErrLeaseLost
+	}
+	return nil
+}
+
+// Release mengembalikan job ke antrian, tersedia lagi setelah delay.
+// Mengembalikan ErrLeaseLost bila job sudah diambil worker lain.
+func (q *DBQueue) Release(ctx context.Context, job *Job, delay time.Duration, cause error) error {
+	db, dialect, err := q.conn()
+	if err != nil {
+		return err
+	}
+
+	var lastError interface{}
+	if cause != nil {
+		lastError = cause.Error()
+	}
+	cond, condArgs := ownedCond(dialect, job, 3)
+	query := fmt.Sprintf("UPDATE %s SET %s = %s, %s = %s, %s = 0, %s = %s WHERE %s",
+		dialect.QuoteIdentifier("jobs"),
+		dialect.QuoteIdentifier("status"), dialect.Placeholder(1),
+		dialect.QuoteIdentifier("available_at"), dialect.Placeholder(2),
+		dialect.QuoteIdentifier("reserved_until"),
+		dialect.QuoteIdentifier("last_error"), dialect.Placeholder(3), cond)
+	args := append([]interface{}{"pending", time.Now().Add(delay).Unix(), lastError}, condArgs...)
+	res, err := db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if affected, _ := res.RowsAffected(); affected != 1 {
+		return ErrLeaseLost
+	}
+	if delay <= 0 {
+		q.announce(ctx, db, dialect, job.Queue)
+	}
+	return nil
+}
+
+// Requeue mengembalikan job yang dihentikan karena worker dimatikan ke antrian.
+// Percobaan itu tidak dihitung, karena job-nya sendiri tidak gagal.
+func (q *DBQueue) Requeue(ctx context.Context, job *Job) error {
+	db, dialect, err := q.conn()
+	if err != nil {
+		return err
+	}
+	if err := q.unclaim(ctx, db, dialect, job); err != nil {
+		return err
+	}
+	q.announce(ctx, db, dialect, job.Queue)
+	return nil
+}
+
+// Fail memindahkan job ke failed_jobs dalam satu transaksi.
+// Mengembalikan ErrLeaseLost bila job sudah diambil worker lain.
+func (q *DBQueue) Fail(ctx context.Context, job *Job, cause error, stack string) error {
+	db, dialect, err := q.ensureSchema(ctx)
+	if err != nil {
+		return err
+	}
+
+	errMsg := ""
+	if cause != nil {
+		errMsg = cause.Error()
+	}
+	cond, condArgs := ownedCond(dialect, job, 0)
+	moved, err := q.moveToFailed(ctx, db, dialect, job, errMsg, stack, cond, condArgs)
+	if err != nil {
+		return err
+	}
+	if !moved {
+		return ErrLeaseLost
+	}
+	return nil
+}
+
+// moveToFailed menghapus job dari jobs dan menyimpannya di failed_jobs dalam satu transaksi.
+// Job hanya dipindahkan jika baris jobs-nya masih memenuhi cond.
+func (q *DBQueue) moveToFailed(ctx context.Context, db *sql.DB, dialect dbmanager.Dialect, job *Job, errMsg, stack, cond string, condArgs []interface{}) (bool, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE %s", dialect.QuoteIdentifier("jobs"), cond), condArgs...)
+	if err != nil {
+		return false, err
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return false, nil
+	}
+
+	cols := []string{"queue", "payload", "attempts", "max_tries", "backoff", "timeout", "error", "stack", "failed_at", "rate_key"}
+	args := []interface{}{job.Queue, string(job.Payload), job.Attempts, job.Options.Tries, backoffValue(job.Options.Backoff),
+		int(job.Options.Timeout / time.Second), errMsg, stack, time.Now(), emptyToNull(job.Options.RateKey)}
+	if err := insertRow(ctx, tx, dialect, "failed_jobs", cols, args); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// FailedJobs mengembalikan isi failed_jobs, terbaru lebih dulu. queue kosong berarti semua queue.
+func (q *DBQueue) FailedJobs(ctx context.Context, queue string) ([]FailedJob, error) {
+	db, dialect, err := q.ensureSchema(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf("SELECT %s, %s, %s, %s, %s, %s, %s FROM %s",
+		dialect.QuoteIdentifier("id"), dialect.QuoteIdentifier("queue"), dialect.QuoteIdentifier("payload"),
+		dialect.QuoteIdentifier("attempts"), dialect.QuoteIdentifier("error"), dialect.QuoteIdentifier("stack"),
+		dialect.QuoteIdentifier("failed_at"), dialect.QuoteIdentifier("failed_jobs"))
+	var args []interface{}
+	if queue != "" {
+		query += fmt.Sprintf(" WHERE %s = %s", dialect.QuoteIdentifier("queue"), dialect.Placeholder(1))
+		args = append(args, queue)
+	}
+	query += fmt.Sprintf(" ORDER BY %s DESC", dialect.QuoteIdentifier("id"))
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []FailedJob
+	for rows.Next() {
+		var f FailedJob
+		var errMsg, stack sql.NullString
+		var failedAt interface{}
+		if err := rows.Scan(&f.ID, &f.Queue, &f.Payload, &f.Attempts, &errMsg, &stack, &failedAt); err != nil {
+			return nil, err
+		}
+		f.Error, f.Stack = errMsg.String, stack.String
+		f.FailedAt = toTime(failedAt)
+		result = append(result, f)
+	}
+	return result, rows.Err()
+}
+
+// toTime mengubah nilai DATETIME dari driver (time.Time atau string) menjadi time.Time
+func toTime(v interface{}) time.Time {
+	switch t := v.(type) {
+	case time.Time:
+		return t
+	case []byte:
+		return toTime(string(t))
+	case string:
+		for _, layout := range []string{"2006-01-02 15:04:05.999999999-07:00", time.RFC3339Nano, "2006-01-02 15:04:05.999999999", "2006-01-02 15:04:05"} {
+			if parsed, err := time.Parse(layout, t); err == nil {
+				return parsed
 			}
+		}
+	}
+	return time.Time{}
+}
 
-			continue
+// RetryFailed memasukkan kembali failed job ke antrian dengan percobaan dari nol.
+// ids kosong berarti semua failed job. Mengembalikan jumlah job yang di-retry.
+func (q *DBQueue) RetryFailed(ctx context.Context, ids []int64) (int, error) {
+	db, dialect, err := q.ensureSchema(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	query := fmt.Sprintf("SELECT %s, %s, %s, %s, %s, %s, %s FROM %s",
+		dialect.QuoteIdentifier("id"), dialect.QuoteIdentifier("queue"), dialect.QuoteIdentifier("payload"),
+		dialect.QuoteIdentifier("max_tries"), dialect.QuoteIdentifier("backoff"), dialect.QuoteIdentifier("timeout"),
+		dialect.QuoteIdentifier("rate_key"), dialect.QuoteIdentifier("failed_jobs"))
+	var args []interface{}
+	if len(ids) > 0 {
+		placeholders := make([]string, len(ids))
+		for i, id := range ids {
+			placeholders[i] = dialect.Placeholder(i + 1)
+			args = append(args, id)
+		}
+		query += fmt.Sprintf(" WHERE %s IN (%s)", dialect.QuoteIdentifier("id"), strings.Join(placeholders, ","))
+	}
+	query += fmt.Sprintf(" ORDER BY %s ASC", dialect.QuoteIdentifier("id"))
+
+	type failedRow struct {
+		id      int64
+		queue   string
+		payload []byte
+		tries   int
+		backoff sql.NullString
+		timeout int
+		rateKey sql.NullString
+	}
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	var failed []failedRow
+	for rows.Next() {
+		var f failedRow
+		if err := rows.Scan(&f.id, &f.queue, &f.payload, &f.tries, &f.backoff, &f.timeout, &f.rateKey); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		failed = append(failed, f)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, f := range failed {
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return 0, err
+		}
+		cols := []string{"queue", "payload", "status", "created_at", "attempts", "max_tries", "backoff", "timeout", "available_at", "rate_key"}
+		vals := []interface{}{f.queue, string(f.payload), "pending", time.Now(), 0, f.tries, nullString(f.backoff), f.timeout, time.Now().Unix(), nullString(f.rateKey)}
+		if err := insertRow(ctx, tx, dialect, "jobs", cols, vals); err != nil {
+			tx.Rollback()
+			return 0, err
 		}
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE %s = %s",
+			dialect.QuoteIdentifier("failed_jobs"), dialect.QuoteIdentifier("id"), dialect.Placeholder(1)), f.id); err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+		if err := tx.Commit(); err != nil {
+			return 0, err
+		}
+		q.announce(ctx, db, dialect, f.queue)
+	}
+	return len(failed), nil
+}
+
+func nullString(s sql.NullString) interface{} {
+	if !s.Valid {
+		return nil
+	}
+	return s.String
+}
+
+// ForgetFailed menghapus satu failed job
+func (q *DBQueue) ForgetFailed(ctx context.Context, id int64) (bool, error) {
+	db, dialect, err := q.ensureSchema(ctx)
+	if err != nil {
+		return false, err
+	}
+	res, err := db.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE %s = %s",
+		dialect.QuoteIdentifier("failed_jobs"), dialect.QuoteIdentifier("id"), dialect.Placeholder(1)), id)
+	if err != nil {
+		return false, err
 	}
+	affected, _ := res.RowsAffected()
+	return affected > 0, nil
+}
+
+// FlushFailed menghapus semua failed job. queue kosong berarti semua queue.
+func (q *DBQueue) FlushFailed(ctx context.Context, queue string) (int64, error) {
+	db, dialect, err := q.ensureSchema(ctx)
+	if err != nil {
+		return 0, err
+	}
+	query := fmt.Sprintf("DELETE FROM %s", dialect.QuoteIdentifier("failed_jobs"))
+	var args []interface{}
+	if queue != "" {
+		query += fmt.Sprintf(" WHERE %s = %s", dialect.QuoteIdentifier("queue"), dialect.Placeholder(1))
+		args = append(args, queue)
+	}
+	res, err := db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
 }
 
 func (q *DBQueue) Close() error {
+	if q.listener != nil {
+		return q.listener.Close()
+	}
 	return nil // DB connections closed by main app
 }
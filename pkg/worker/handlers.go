@@ -0,0 +1,87 @@
+package worker
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/nextcore/zeno-go/pkg/engine"
+)
+
+// Handler adalah job handler yang didefinisikan dengan job.define. Job yang di-dispatch
+// hanya menyimpan namanya, jadi memindahkan file script tidak memutus job yang sudah di-queue.
+type Handler struct {
+	Name string
+	// Queue dan Options adalah default untuk job.dispatch
+	Queue   string
+	Options JobOptions
+	Body    []*engine.Node
+	// Source adalah lokasi definisi (file:line) untuk pesan error
+	Source string
+}
+
+var handlerNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.:-]+$`)
+
+// Handlers menyimpan job handler per nama. Aman dipakai bersamaan oleh web server dan worker.
+type Handlers struct {
+	mu     sync.RWMutex
+	byName map[string]*Handler
+}
+
+func NewHandlers() *Handlers {
+	return &Handlers{byName: make(map[string]*Handler)}
+}
+
+// Define mendaftarkan handler. Nama yang sudah didefinisikan di lokasi lain ditolak.
+func (h *Handlers) Define(handler *Handler) error {
+	if !handlerNamePattern.MatchString(handler.Name) {
+		return fmt.Errorf("invalid job handler name '%s' (use letters, digits, _ . : -)", handler.Name)
+	}
+	if len(handler.Body) == 0 {
+		return fmt.Errorf("job handler '%s' has an empty body", handler.Name)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if existing, ok := h.byName[handler.Name]; ok && existing.Source != handler.Source {
+		return fmt.Errorf("job handler '%s' is already defined at %s", handler.Name, existing.Source)
+	}
+	h.byName[handler.Name] = handler
+	return nil
+}
+
+// Get mengembalikan handler dengan nama tersebut
+func (h *Handlers) Get(name string) (*Handler, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	handler, ok := h.byName[name]
+	return handler, ok
+}
+
+// Names mengembalikan nama semua handler, terurut
+func (h *Handlers) Names() []string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	names := make([]string, 0, len(h.byName))
+	for name := range h.byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Replace mengganti seluruh isi dengan handler dari other (mis. setelah main.zl dimuat ulang).
+// Job yang sedang berjalan tetap memakai body lama.
+func (h *Handlers) Replace(other *Handlers) {
+	other.mu.RLock()
+	byName := make(map[string]*Handler, len(other.byName))
+	for name, handler := range other.byName {
+		byName[name] = handler
+	}
+	other.mu.RUnlock()
+
+	h.mu.Lock()
+	h.byName = byName
+	h.mu.Unlock()
+}
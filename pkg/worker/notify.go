@@ -0,0 +1,110 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/nextcore/zenoengine/pkg/dbmanager"
+)
+
+// NotifyChannel adalah channel LISTEN/NOTIFY Postgres yang dipakai saat job baru tersedia
+const NotifyChannel = "zeno_jobs"
+
+// ListenPollInterval menggantikan PollInterval saat DBQueue menerima NOTIFY dari Postgres.
+// Polling hanya menjadi jaring pengaman untuk notifikasi yang hilang saat koneksi terputus.
+var ListenPollInterval = 5 * time.Second
+
+// wakeup membangunkan semua Reserve yang sedang menunggu di proses ini.
+// Setiap penantian mengambil channel dari wait; notify menutupnya lalu membuat yang baru.
+type wakeup struct {
+	mu sync.Mutex
+	ch chan struct{}
+}
+
+func (w *wakeup) wait() <-chan struct{} {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.ch == nil {
+		w.ch = make(chan struct{})
+	}
+	return w.ch
+}
+
+func (w *wakeup) notify() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.ch != nil {
+		close(w.ch)
+		w.ch = nil
+	}
+}
+
+// announce memberi tahu worker bahwa ada job baru: langsung untuk Reserve di proses ini,
+// dan lewat NOTIFY untuk worker Postgres di proses lain
+func (q *DBQueue) announce(ctx context.Context, db *sql.DB, dialect dbmanager.Dialect, queue string) {
+	q.wake.notify()
+	if dialect.Name() != "postgres" {
+		return
+	}
+	if _, err := db.ExecContext(ctx, "SELECT pg_notify($1, $2)", NotifyChannel, queue); err != nil {
+		slog.Warn("⚠️  Failed to notify workers", "error", err)
+	}
+}
+
+// listen memulai LISTEN pada koneksi Postgres (sekali per DBQueue). Mengembalikan true bila aktif.
+func (q *DBQueue) listen() bool {
+	q.listenOnce.Do(func() {
+		if dialect := q.dbMgr.GetDialect(q.connName); dialect == nil || dialect.Name() != "postgres" {
+			return
+		}
+		dsn := q.dbMgr.GetDSN(q.connName)
+		if dsn == "" {
+			return
+		}
+
+		listener := pq.NewListener(dsn, time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+			if err != nil {
+				slog.Warn("⚠️  Job notification listener", "event", ev, "error", err)
+			}
+		})
+		if err := listener.Listen(NotifyChannel); err != nil {
+			slog.Warn("⚠️  LISTEN unavailable, falling back to polling", "error", err)
+			listener.Close()
+			return
+		}
+		q.listener = listener
+
+		go func() {
+			// nil dikirim setelah koneksi tersambung ulang; notifikasi di antaranya bisa hilang, jadi periksa ulang
+			for range listener.Notify {
+				q.wake.notify()
+			}
+		}()
+	})
+	return q.listener != nil
+}
+
+// nextAvailable mengembalikan waktu job tertunda (delay/backoff) berikutnya di queues, atau zero bila tidak ada
+func (q *DBQueue) nextAvailable(ctx context.Context, queues []string) time.Time {
+	db, dialect, err := q.conn()
+	if err != nil {
+		return time.Time{}
+	}
+	a := &sqlArgs{dialect: dialect}
+	query := fmt.Sprintf("SELECT MIN(%s) FROM %s WHERE %s = %s AND %s > %s%s",
+		dialect.QuoteIdentifier("available_at"), dialect.QuoteIdentifier("jobs"),
+		dialect.QuoteIdentifier("status"), a.add("pending"),
+		dialect.QuoteIdentifier("available_at"), a.add(time.Now().Unix()),
+		queueFilter(dialect, a, queues))
+
+	var next sql.NullInt64
+	if err := db.QueryRowContext(ctx, query, a.values...).Scan(&next); err != nil || !next.Valid {
+		return time.Time{}
+	}
+	return time.Unix(next.Int64, 0)
+}
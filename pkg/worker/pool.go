@@ -0,0 +1,226 @@
+package worker
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultConcurrency adalah ukuran worker pool bila tidak dikonfigurasi
+const DefaultConcurrency = 5
+
+// DefaultShutdownGrace adalah lama worker menunggu job yang sedang berjalan saat dimatikan
+const DefaultShutdownGrace = 30 * time.Second
+
+// Mode prioritas antar queue
+const (
+	// PriorityFIFO mengambil job dari semua queue sesuai urutan masuk (default)
+	PriorityFIFO = "fifo"
+	// PriorityStrict selalu mendahulukan queue yang disebut lebih dulu
+	PriorityStrict = "strict"
+	// PriorityWeighted memilih queue secara acak sebanding dengan bobotnya
+	PriorityWeighted = "weighted"
+)
+
+// Options mengatur worker pool
+type Options struct {
+	Queues []string
+	// Concurrency membatasi jumlah job yang berjalan bersamaan (0 = DefaultConcurrency)
+	Concurrency int
+	// Priority: fifo, strict atau weighted (kosong = fifo)
+	Priority string
+	// Weights adalah bobot queue untuk PriorityWeighted (default 1)
+	Weights map[string]int
+	// Limits membatasi job yang berjalan bersamaan per queue. Hanya berlaku untuk queue di Queues.
+	Limits map[string]int
+	// RateLimits membatasi job yang di-claim per periode, per queue atau per RateKey job
+	RateLimits map[string]Rate
+	// Handlers adalah job.define yang dijalankan untuk job dengan JobPayload.Handler
+	Handlers *Handlers
+	// Timeout membatasi durasi satu percobaan job tanpa timeout sendiri (0 = tanpa batas)
+	Timeout time.Duration
+	// Timeouts adalah Timeout per queue. Timeout job sendiri (job.enqueue timeout:) tetap didahulukan.
+	Timeouts map[string]time.Duration
+	// ShutdownGrace adalah lama menunggu job yang sedang berjalan saat worker dimatikan. Setelahnya
+	// context job dibatalkan dan job dikembalikan ke antrian (0 = DefaultShutdownGrace).
+	ShutdownGrace time.Duration
+}
+
+// Validate checks the priority mode, weights, limits and rate limits
+func (o Options) Validate() error {
+	switch o.Priority {
+	case "", PriorityFIFO, PriorityStrict, PriorityWeighted:
+	default:
+		return fmt.Errorf("invalid worker priority '%s' (use fifo, strict or weighted)", o.Priority)
+	}
+	if o.Concurrency < 0 {
+		return fmt.Errorf("worker concurrency must not be negative")
+	}
+	for q, w := range o.Weights {
+		if w < 1 {
+			return fmt.Errorf("weight of queue '%s' must be at least 1", q)
+		}
+	}
+	for q, l := range o.Limits {
+		if l < 0 {
+			return fmt.Errorf("limit of queue '%s' must not be negative", q)
+		}
+	}
+	for name, r := range o.RateLimits {
+		if r.Limit < 1 || r.Per <= 0 {
+			return fmt.Errorf("rate limit '%s' must allow at least 1 job per period", name)
+		}
+	}
+	if o.Timeout < 0 || o.ShutdownGrace < 0 {
+		return fmt.Errorf("worker timeout and shutdown grace must not be negative")
+	}
+	for q, d := range o.Timeouts {
+		if d < 0 {
+			return fmt.Errorf("timeout of queue '%s' must not be negative", q)
+		}
+	}
+	if len(o.Limits) > 0 && len(o.Queues) == 0 {
+		return fmt.Errorf("per-queue limits need an explicit list of queues")
+	}
+	return nil
+}
+
+func (o Options) poolSize() int {
+	if o.Concurrency > 0 {
+		return o.Concurrency
+	}
+	return DefaultConcurrency
+}
+
+func (o Options) shutdownGrace() time.Duration {
+	if o.ShutdownGrace > 0 {
+		return o.ShutdownGrace
+	}
+	return DefaultShutdownGrace
+}
+
+// timeoutFor mengembalikan batas durasi percobaan job: timeout job, lalu timeout queue-nya, lalu Timeout
+func (o Options) timeoutFor(job *Job) time.Duration {
+	if job.Options.Timeout > 0 {
+		return job.Options.Timeout
+	}
+	if d, ok := o.Timeouts[job.Queue]; ok && d > 0 {
+		return d
+	}
+	return o.Timeout
+}
+
+// eligibleQueues mengembalikan queue yang belum mencapai limit-nya, dalam urutan konfigurasi
+func (o Options) eligibleQueues(running map[string]int) []string {
+	if len(o.Limits) == 0 {
+		return o.Queues
+	}
+	eligible := make([]string, 0, len(o.Queues))
+	for _, q := range o.Queues {
+		if limit := o.Limits[q]; limit == 0 || running[q] < limit {
+			eligible = append(eligible, q)
+		}
+	}
+	return eligible
+}
+
+// order menentukan urutan queue untuk satu reservasi; strict berarti urutan itu adalah prioritas
+func (o Options) order(queues []string, rnd *rand.Rand) (ordered []string, strict bool) {
+	switch o.Priority {
+	case PriorityStrict:
+		return queues, true
+	case PriorityWeighted:
+		return weightedOrder(queues, o.Weights, rnd), true
+	default:
+		return queues, false
+	}
+}
+
+// weightedOrder mengacak urutan queue: peluang sebuah queue berada di depan sebanding dengan bobotnya
+func weightedOrder(queues []string, weights map[string]int, rnd *rand.Rand) []string {
+	remaining := append([]string(nil), queues...)
+	ordered := make([]string, 0, len(queues))
+	for len(remaining) > 0 {
+		total := 0
+		for _, q := range remaining {
+			total += queueWeight(weights, q)
+		}
+		pick := rnd.Intn(total)
+		for i, q := range remaining {
+			if pick -= queueWeight(weights, q); pick < 0 {
+				ordered = append(ordered, q)
+				remaining = append(remaining[:i], remaining[i+1:]...)
+				break
+			}
+		}
+	}
+	return ordered
+}
+
+func queueWeight(weights map[string]int, queue string) int {
+	if w := weights[queue]; w > 0 {
+		return w
+	}
+	return 1
+}
+
+// ParseQueueList reads "high:3,default,low:1" into the queue names and their weights
+func ParseQueueList(value string) ([]string, map[string]int, error) {
+	var queues []string
+	weights := make(map[string]int)
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, weight, hasWeight := strings.Cut(part, ":")
+		name = strings.TrimSpace(name)
+		queues = append(queues, name)
+		if hasWeight {
+			w, err := strconv.Atoi(strings.TrimSpace(weight))
+			if err != nil || w < 1 {
+				return nil, nil, fmt.Errorf("invalid weight for queue '%s'", name)
+			}
+			weights[name] = w
+		}
+	}
+	return queues, weights, nil
+}
+
+// ParseQueueLimits reads "sms:2,email:5" into per-queue limits
+func ParseQueueLimits(value string) (map[string]int, error) {
+	limits := make(map[string]int)
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, limit, ok := strings.Cut(part, ":")
+		n, err := strconv.Atoi(strings.TrimSpace(limit))
+		if !ok || err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid queue limit '%s' (use queue:number)", part)
+		}
+		limits[strings.TrimSpace(name)] = n
+	}
+	return limits, nil
+}
+
+// ParseQueueTimeouts reads "sms:30s,reports:10m" into per-queue timeouts; a bare number means seconds
+func ParseQueueTimeouts(value string) (map[string]time.Duration, error) {
+	timeouts := make(map[string]time.Duration)
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, timeout, ok := strings.Cut(part, ":")
+		d, err := ParseDelay(timeout)
+		if !ok || err != nil {
+			return nil, fmt.Errorf("invalid queue timeout '%s' (use queue:30s)", part)
+		}
+		timeouts[strings.TrimSpace(name)] = d
+	}
+	return timeouts, nil
+}
@@ -1,6 +1,13 @@
 package worker
 
-import "context"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
 
 // JobQueue defines the interface for a background job queue
 type JobQueue interface {
@@ -14,3 +21,202 @@ type JobQueue interface {
 	// Close cleans up resources
 	Close() error
 }
+
+// ReliableQueue is implemented by queues that track every job until it is acknowledged,
+// so failed jobs can be retried and finally moved to the failed jobs store
+type ReliableQueue interface {
+	JobQueue
+
+	// PushJob adds a job with retry options and returns its ID
+	PushJob(ctx context.Context, queue string, payload []byte, opts JobOptions) (int64, error)
+
+	// Reserve blocks until a job is available and claims it (counting the attempt).
+	// With prioritized, queues are listed by priority and an earlier queue always wins;
+	// otherwise the oldest job of any of the queues is taken.
+	Reserve(ctx context.Context, queues []string, prioritized bool) (*Job, error)
+
+	// Complete removes a job that finished successfully
+	Complete(ctx context.Context, job *Job) error
+
+	// Release puts a failed job back on its queue, available again after delay
+	Release(ctx context.Context, job *Job, delay time.Duration, cause error) error
+
+	// Fail moves a job that ran out of attempts to the failed jobs store
+	Fail(ctx context.Context, job *Job, cause error, stack string) error
+
+	// Heartbeat extends the reservation of a running job (see Job.Lease)
+	Heartbeat(ctx context.Context, job *Job) error
+
+	// ReleaseExpired returns jobs whose reservation expired, because their worker died,
+	// to the queue. The lost attempt counts against the job's tries.
+	ReleaseExpired(ctx context.Context) (int, error)
+
+	// Requeue puts a job that was interrupted by a worker shutdown back on its queue,
+	// without counting the attempt
+	Requeue(ctx context.Context, job *Job) error
+}
+
+// CancellableQueue is implemented by queues that can cancel a job by ID
+type CancellableQueue interface {
+	// Cancel moves a pending job to the failed jobs store, or flags a running job so its
+	// worker stops it. It reports false when no pending or running job has that ID.
+	Cancel(ctx context.Context, id int64) (bool, error)
+
+	// Cancelled returns which of the given running jobs were flagged by Cancel
+	Cancelled(ctx context.Context, ids []int64) ([]int64, error)
+}
+
+// RateLimitedQueue is implemented by queues that enforce rate limits while claiming jobs.
+// A limit applies to the queue with the same name and to jobs pushed with that RateKey.
+type RateLimitedQueue interface {
+	SetRateLimits(limits map[string]Rate)
+}
+
+// WakeableQueue is implemented by queues whose Reserve can be interrupted while it waits for a job.
+// The worker uses it to retry with a different set of queues once a queue limit frees up.
+type WakeableQueue interface {
+	// ReserveWake is Reserve, but returns ErrWoken when wake fires while no job is available.
+	// A claim that is already running is never interrupted.
+	ReserveWake(ctx context.Context, queues []string, prioritized bool, wake <-chan struct{}) (*Job, error)
+}
+
+// ErrWoken is returned by WakeableQueue.ReserveWake when it was woken before a job was claimed
+var ErrWoken = errors.New("reserve woken before a job was claimed")
+
+// ErrLeaseLost is returned by Heartbeat, Complete, Release and Fail when the reservation already
+// expired and the job was reclaimed, so the attempt no longer owns the job
+var ErrLeaseLost = errors.New("job reservation lost")
+
+// ErrJobCancelled is the failure of a job stopped by CancellableQueue.Cancel. Cancelled jobs are not retried.
+var ErrJobCancelled = errors.New("job cancelled")
+
+// ErrDuplicateJob is returned by PushJob when a job with the same UniqueKey is still queued
+var ErrDuplicateJob = errors.New("a job with the same unique key is already queued")
+
+// JobOptions mengatur perilaku retry sebuah job
+type JobOptions struct {
+	// Tries adalah jumlah maksimal percobaan (Default: 1, tanpa retry)
+	Tries int
+	// Backoff menentukan jeda sebelum percobaan berikutnya
+	Backoff Backoff
+	// Timeout membatasi durasi satu percobaan (0 = tanpa batas)
+	Timeout time.Duration
+	// AvailableAt menunda job sampai waktu tersebut (zero = langsung tersedia)
+	AvailableAt time.Time
+	// UniqueKey menolak job kedua dengan key yang sama selama key masih terkunci (lihat UniqueFor)
+	UniqueKey string
+	// UniqueFor mengunci key selama durasi ini sejak push, juga setelah job selesai
+	// (0 = sampai job selesai atau dipindahkan ke failed_jobs)
+	UniqueFor time.Duration
+	// ReplaceUnique mengganti job pending dengan key yang sama, alih-alih melewati job baru
+	ReplaceUnique bool
+	// RateKey menghitung job ini pada rate limit dengan nama tersebut, selain limit queue-nya
+	RateKey string
+}
+
+// Job is a job reserved from a ReliableQueue
+type Job struct {
+	ID       int64
+	Queue    string
+	Payload  []byte
+	Attempts int // Termasuk percobaan yang sedang berjalan
+	Options  JobOptions
+	// Lease adalah masa reservasi yang harus diperpanjang lewat Heartbeat (0 = tanpa reservasi)
+	Lease time.Duration
+	// Cancelled berarti job dibatalkan saat percobaan sebelumnya berjalan; job tidak dijalankan lagi
+	Cancelled bool
+}
+
+// CanRetry reports whether the job has attempts left
+func (j *Job) CanRetry() bool {
+	return j.Attempts < j.Options.Tries
+}
+
+// Backoff adalah jeda antar percobaan: tetap, atau eksponensial (Delay, 2x Delay, 4x Delay, ...)
+type Backoff struct {
+	Exponential bool
+	Delay       time.Duration
+	// Max membatasi jeda eksponensial (0 = tanpa batas)
+	Max time.Duration
+}
+
+// After returns the delay before the next try, after the given number of attempts
+func (b Backoff) After(attempts int) time.Duration {
+	if !b.Exponential || attempts <= 1 {
+		return b.Delay
+	}
+	d := b.Delay
+	for i := 1; i < attempts; i++ {
+		d *= 2
+		if b.Max > 0 && d >= b.Max {
+			return b.Max
+		}
+	}
+	return d
+}
+
+// String encodes the backoff for storage, e.g. "30s" or "exponential:10s:1h0m0s"
+func (b Backoff) String() string {
+	if !b.Exponential {
+		return b.Delay.String()
+	}
+	s := "exponential:" + b.Delay.String()
+	if b.Max > 0 {
+		s += ":" + b.Max.String()
+	}
+	return s
+}
+
+// ParseBackoff reads a backoff from its String form. A bare number is a delay in seconds
+// and "exponential" without a delay starts at 1s.
+func ParseBackoff(s string) (Backoff, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Backoff{}, nil
+	}
+
+	var b Backoff
+	parts := strings.Split(s, ":")
+	if parts[0] == "exponential" || parts[0] == "fixed" {
+		b.Exponential = parts[0] == "exponential"
+		parts = parts[1:]
+		if b.Exponential && len(parts) == 0 {
+			b.Delay = time.Second
+		}
+	}
+	if len(parts) > 2 || (!b.Exponential && len(parts) > 1) {
+		return Backoff{}, fmt.Errorf("invalid backoff '%s'", s)
+	}
+
+	if len(parts) > 0 {
+		d, err := ParseDelay(parts[0])
+		if err != nil {
+			return Backoff{}, fmt.Errorf("invalid backoff '%s': %v", s, err)
+		}
+		b.Delay = d
+	}
+	if len(parts) > 1 {
+		d, err := ParseDelay(parts[1])
+		if err != nil {
+			return Backoff{}, fmt.Errorf("invalid backoff '%s': %v", s, err)
+		}
+		b.Max = d
+	}
+	return b, nil
+}
+
+// ParseDelay reads a duration like "90s" or "10m"; a bare number means seconds
+func ParseDelay(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	d, err := time.ParseDuration(s)
+	if seconds, errNum := strconv.ParseFloat(s, 64); errNum == nil {
+		d, err = time.Duration(seconds*float64(time.Second)), nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("duration must not be negative")
+	}
+	return d, nil
+}
@@ -0,0 +1,261 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nextcore/zenoengine/pkg/dbmanager"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestQueue membuat DBQueue di atas koneksi "internal" SQLite in-memory
+func newTestQueue(t *testing.T) (*DBQueue, *sql.DB) {
+	dbMgr := dbmanager.NewDBManager()
+	if err := dbMgr.AddConnection("internal", "sqlite", ":memory:", 1, 1); err != nil {
+		t.Fatalf("Failed to create in-memory db: %v", err)
+	}
+	t.Cleanup(func() { dbMgr.Close() })
+	return NewDBQueue(dbMgr, "internal"), dbMgr.GetConnection("internal")
+}
+
+func TestParseBackoff(t *testing.T) {
+	b, err := ParseBackoff("30")
+	assert.NoError(t, err)
+	assert.Equal(t, 30*time.Second, b.After(3))
+
+	b, err = ParseBackoff("exponential:10s:1m")
+	assert.NoError(t, err)
+	assert.Equal(t, 10*time.Second, b.After(1))
+	assert.Equal(t, 40*time.Second, b.After(3))
+	assert.Equal(t, time.Minute, b.After(5))
+
+	parsed, err := ParseBackoff(b.String())
+	assert.NoError(t, err)
+	assert.Equal(t, b, parsed)
+
+	_, err = ParseBackoff("sometimes")
+	assert.Error(t, err)
+}
+
+func TestReservationExpiry(t *testing.T) {
+	queue, db := newTestQueue(t)
+	ctx := context.Background()
+
+	id, err := queue.PushJob(ctx, "default", []byte(`{}`), JobOptions{Tries: 2})
+	assert.NoError(t, err)
+	job, err := queue.Reserve(ctx, nil, false)
+	assert.NoError(t, err)
+	assert.Equal(t, id, job.ID)
+	assert.Equal(t, DefaultVisibilityTimeout, job.Lease)
+
+	// Reservasi yang masih berlaku tidak disentuh reaper
+	n, err := queue.ReleaseExpired(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+	assert.NoError(t, queue.Heartbeat(ctx, job))
+
+	// Worker mati: reservasi kedaluwarsa, job kembali ke antrian dengan percobaan terhitung
+	_, err = db.Exec("UPDATE jobs SET reserved_until = 1")
+	assert.NoError(t, err)
+	n, err = queue.ReleaseExpired(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.ErrorIs(t, queue.Heartbeat(ctx, job), ErrLeaseLost)
+
+	stale := job
+	job, err = queue.Reserve(ctx, nil, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, job.Attempts)
+
+	// Worker lama tidak bisa meng-ack job yang sekarang dijalankan worker lain
+	assert.ErrorIs(t, queue.Complete(ctx, stale), ErrLeaseLost)
+	assert.ErrorIs(t, queue.Release(ctx, stale, 0, nil), ErrLeaseLost)
+	assert.ErrorIs(t, queue.Fail(ctx, stale, errors.New("boom"), ""), ErrLeaseLost)
+	assert.NoError(t, queue.Heartbeat(ctx, job))
+	failed, err := queue.FailedJobs(ctx, "")
+	assert.NoError(t, err)
+	assert.Empty(t, failed)
+
+	// Percobaan terakhir yang ditinggal worker pindah ke failed_jobs
+	_, err = db.Exec("UPDATE jobs SET reserved_until = 1")
+	assert.NoError(t, err)
+	n, err = queue.ReleaseExpired(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	failed, err = queue.FailedJobs(ctx, "")
+	assert.NoError(t, err)
+	if assert.Len(t, failed, 1) {
+		assert.Contains(t, failed[0].Error, "reservation expired")
+	}
+
+	// Job dari Pop (tanpa reservasi) tidak pernah dikembalikan
+	assert.NoError(t, queue.Push(ctx, "default", []byte(`{}`)))
+	_, _, err = queue.Pop(ctx, nil)
+	assert.NoError(t, err)
+	n, err = queue.ReleaseExpired(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+}
+
+func TestQueuePriority(t *testing.T) {
+	queue, _ := newTestQueue(t)
+	ctx := context.Background()
+
+	// Job low lebih tua, tapi high didahulukan bila prioritas aktif
+	assert.NoError(t, queue.Push(ctx, "low", []byte(`{"script_path":"low.zl"}`)))
+	assert.NoError(t, queue.Push(ctx, "high", []byte(`{"script_path":"high.zl"}`)))
+	assert.NoError(t, queue.Push(ctx, "low", []byte(`{"script_path":"low.zl"}`)))
+
+	job, err := queue.Reserve(ctx, []string{"high", "low"}, true)
+	assert.NoError(t, err)
+	assert.Equal(t, "high", job.Queue)
+
+	job, err = queue.Reserve(ctx, []string{"high", "low"}, true)
+	assert.NoError(t, err)
+	assert.Equal(t, "low", job.Queue)
+
+	// Tanpa prioritas: job terlama lebih dulu
+	assert.NoError(t, queue.Push(ctx, "high", []byte(`{"script_path":"high.zl"}`)))
+	job, err = queue.Reserve(ctx, []string{"high", "low"}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "low", job.Queue)
+}
+
+func TestParseQueueList(t *testing.T) {
+	queues, weights, err := ParseQueueList("high:3, default ,low:1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"high", "default", "low"}, queues)
+	assert.Equal(t, map[string]int{"high": 3, "low": 1}, weights)
+
+	_, _, err = ParseQueueList("high:0")
+	assert.Error(t, err)
+
+	limits, err := ParseQueueLimits("sms:2,email:5")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"sms": 2, "email": 5}, limits)
+
+	_, err = ParseQueueLimits("sms")
+	assert.Error(t, err)
+}
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Rate
+	}{
+		{"10/s", Rate{Limit: 10, Per: time.Second}},
+		{"300/m", Rate{Limit: 300, Per: time.Minute}},
+		{"1000 / hour", Rate{Limit: 1000, Per: time.Hour}},
+		{"5/10s", Rate{Limit: 5, Per: 10 * time.Second}},
+	}
+	for _, tt := range tests {
+		got, err := ParseRate(tt.input)
+		assert.NoError(t, err, tt.input)
+		assert.Equal(t, tt.want, got, tt.input)
+	}
+	for _, bad := range []string{"10", "0/s", "ten/s", "10/fortnight", "10/-1s"} {
+		_, err := ParseRate(bad)
+		assert.Error(t, err, bad)
+	}
+
+	limits, err := ParseRateLimits("sms:10/s, mailgun:300/m")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]Rate{"sms": {Limit: 10, Per: time.Second}, "mailgun": {Limit: 300, Per: time.Minute}}, limits)
+}
+
+func TestPushWakesReserve(t *testing.T) {
+	queue, _ := newTestQueue(t)
+	queue.PollInterval = time.Minute
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		queue.Push(context.Background(), "mail", []byte(`{"script_path":"mail.zl"}`))
+	}()
+
+	// Tanpa wakeup, Reserve baru memeriksa ulang setelah PollInterval (1 menit)
+	start := time.Now()
+	job, err := queue.Reserve(ctx, []string{"mail"}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "mail", job.Queue)
+	assert.Equal(t, 1, job.Attempts)
+	assert.Less(t, time.Since(start), 2*time.Second)
+}
+
+func TestClaimIsExclusive(t *testing.T) {
+	queue, _ := newTestQueue(t)
+	ctx := context.Background()
+	for i := 0; i < 20; i++ {
+		assert.NoError(t, queue.Push(ctx, "default", []byte(`{"script_path":"a.zl"}`)))
+	}
+
+	claimed := make(chan int64, 40)
+	done := make(chan struct{})
+	for w := 0; w < 5; w++ {
+		go func() {
+			defer func() { done <- struct{}{} }()
+			for {
+				short, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+				job, err := queue.Reserve(short, []string{"default"}, false)
+				cancel()
+				if err != nil {
+					return
+				}
+				claimed <- job.ID
+			}
+		}()
+	}
+	for w := 0; w < 5; w++ {
+		<-done
+	}
+	close(claimed)
+
+	seen := make(map[int64]bool)
+	for id := range claimed {
+		assert.False(t, seen[id], "job %d claimed twice", id)
+		seen[id] = true
+	}
+	assert.Len(t, seen, 20)
+}
+
+func TestJobsIndex(t *testing.T) {
+	queue, db := newTestQueue(t)
+	ctx := context.Background()
+	assert.NoError(t, queue.Push(ctx, "default", []byte(`{}`)))
+
+	var sqlText string
+	assert.NoError(t, db.QueryRow("SELECT sql FROM sqlite_master WHERE type = 'index' AND name = 'jobs_status_queue_available_at_index'").Scan(&sqlText))
+	assert.Contains(t, sqlText, `("status", "queue", "available_at")`)
+
+	// Tabel yang sudah ada (worker yang dijalankan ulang) tidak gagal karena index-nya sudah ada
+	_, dialect, err := queue.conn()
+	assert.NoError(t, err)
+	assert.NoError(t, ensureQueueTable(ctx, db, dialect, "jobs", jobsColumns, jobsIndexes...))
+}
+
+func TestReserveWake(t *testing.T) {
+	queue, db := newTestQueue(t)
+	queue.PollInterval = time.Minute
+	ctx := context.Background()
+
+	wake := make(chan struct{})
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		close(wake)
+	}()
+	_, err := queue.ReserveWake(ctx, []string{"default"}, false, wake)
+	assert.ErrorIs(t, err, ErrWoken)
+
+	// Job yang tersedia tetap di-claim walaupun wake sudah ditutup
+	assert.NoError(t, queue.Push(ctx, "default", []byte(`{}`)))
+	job, err := queue.ReserveWake(ctx, []string{"default"}, false, wake)
+	assert.NoError(t, err)
+	var status string
+	assert.NoError(t, db.QueryRow("SELECT status FROM jobs WHERE id = ?", job.ID).Scan(&status))
+	assert.NotEqual(t, "pending", status)
+}
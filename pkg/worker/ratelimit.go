@@ -0,0 +1,234 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nextcore/zenoengine/pkg/dbmanager"
+)
+
+// Rate membatasi jumlah job yang di-claim per periode, mis. 10 job per detik
+type Rate struct {
+	Limit int
+	Per   time.Duration
+}
+
+// String encodes the rate as "10/1s"
+func (r Rate) String() string {
+	return fmt.Sprintf("%d/%s", r.Limit, r.Per)
+}
+
+// ParseRate reads "10/s", "300/m", "1000/h" or "5/10s"
+func ParseRate(s string) (Rate, error) {
+	limit, per, ok := strings.Cut(strings.TrimSpace(s), "/")
+	n, err := strconv.Atoi(strings.TrimSpace(limit))
+	if !ok || err != nil || n < 1 {
+		return Rate{}, fmt.Errorf("invalid rate '%s' (use e.g. 10/s, 300/m or 5/10s)", s)
+	}
+
+	per = strings.TrimSpace(per)
+	var d time.Duration
+	switch per {
+	case "s", "sec", "second":
+		d = time.Second
+	case "m", "min", "minute":
+		d = time.Minute
+	case "h", "hour":
+		d = time.Hour
+	case "d", "day":
+		d = 24 * time.Hour
+	default:
+		if d, err = time.ParseDuration(per); err != nil || d <= 0 {
+			return Rate{}, fmt.Errorf("invalid rate '%s' (use e.g. 10/s, 300/m or 5/10s)", s)
+		}
+	}
+	return Rate{Limit: n, Per: d}, nil
+}
+
+// ParseRateLimits reads "sms:10/s,mailgun:300/m" into rate limits by queue or rate key
+func ParseRateLimits(value string) (map[string]Rate, error) {
+	limits := make(map[string]Rate)
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, rate, ok := strings.Cut(part, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid rate limit '%s' (use name:10/s)", part)
+		}
+		r, err := ParseRate(rate)
+		if err != nil {
+			return nil, err
+		}
+		limits[strings.TrimSpace(name)] = r
+	}
+	return limits, nil
+}
+
+// SetRateLimits mengatur rate limit yang diperiksa Reserve. Window dan jumlah claim disimpan di
+// tabel job_rate_limits, jadi limit berlaku untuk semua worker yang memakai tabel jobs yang sama.
+func (q *DBQueue) SetRateLimits(limits map[string]Rate) {
+	q.rateMu.Lock()
+	defer q.rateMu.Unlock()
+	q.rateLimits = limits
+}
+
+func (q *DBQueue) currentRateLimits() map[string]Rate {
+	q.rateMu.RLock()
+	defer q.rateMu.RUnlock()
+	return q.rateLimits
+}
+
+// rateExhausted mengembalikan rate limit yang jatahnya habis di window saat ini,
+// dan kapan window tercepat di antaranya berakhir
+func rateExhausted(ctx context.Context, db *sql.DB, dialect dbmanager.Dialect, limits map[string]Rate, now time.Time) ([]string, time.Time, error) {
+	names := make([]string, 0, len(limits))
+	for name := range limits {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	a := &sqlArgs{dialect: dialect}
+	placeholders := make([]string, len(names))
+	for i, name := range names {
+		placeholders[i] = a.add(name)
+	}
+	query := fmt.Sprintf("SELECT %s, %s, %s FROM %s WHERE %s IN (%s)",
+		dialect.QuoteIdentifier("name"), dialect.QuoteIdentifier("window_start"), dialect.QuoteIdentifier("claimed"),
+		dialect.QuoteIdentifier("job_rate_limits"), dialect.QuoteIdentifier("name"), strings.Join(placeholders, ","))
+	rows, err := db.QueryContext(ctx, query, a.values...)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	defer rows.Close()
+
+	var exhausted []string
+	var reopens time.Time
+	for rows.Next() {
+		var name string
+		var windowStart int64
+		var claimed int
+		if err := rows.Scan(&name, &windowStart, &claimed); err != nil {
+			return nil, time.Time{}, err
+		}
+		rate := limits[name]
+		end := time.UnixMilli(windowStart).Add(rate.Per)
+		if claimed >= rate.Limit && end.After(now) {
+			exhausted = append(exhausted, name)
+			if reopens.IsZero() || end.Before(reopens) {
+				reopens = end
+			}
+		}
+	}
+	return exhausted, reopens, rows.Err()
+}
+
+// takeRates mengambil satu jatah dari limit queue job dan limit RateKey-nya.
+// Bila salah satu sudah habis, jatah yang telanjur diambil dikembalikan.
+func takeRates(ctx context.Context, db *sql.DB, dialect dbmanager.Dialect, limits map[string]Rate, job *Job) (bool, error) {
+	names := []string{job.Queue}
+	if key := job.Options.RateKey; key != "" && key != job.Queue {
+		names = append(names, key)
+	}
+
+	var taken []string
+	for _, name := range names {
+		rate, ok := limits[name]
+		if !ok {
+			continue
+		}
+		got, err := takeRate(ctx, db, dialect, name, rate, time.Now())
+		if err == nil && got {
+			taken = append(taken, name)
+			continue
+		}
+		for _, t := range taken {
+			giveRate(ctx, db, dialect, t)
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// takeRate menambah jumlah claim window saat ini bila masih di bawah limit.
+// Window dimulai dari claim pertama dan berganti setelah rate.Per berlalu.
+func takeRate(ctx context.Context, db *sql.DB, dialect dbmanager.Dialect, name string, rate Rate, now time.Time) (bool, error) {
+	table := dialect.QuoteIdentifier("job_rate_limits")
+	nowMs := now.UnixMilli()
+
+	// Window yang sudah lewat dimulai ulang. Kondisinya membuat hanya satu worker yang mereset.
+	reset := fmt.Sprintf("UPDATE %s SET %s = %s, %s = 0 WHERE %s = %s AND %s <= %s",
+		table,
+		dialect.QuoteIdentifier("window_start"), dialect.Placeholder(1),
+		dialect.QuoteIdentifier("claimed"),
+		dialect.QuoteIdentifier("name"), dialect.Placeholder(2),
+		dialect.QuoteIdentifier("window_start"), dialect.Placeholder(3))
+	if _, err := db.ExecContext(ctx, reset, nowMs, name, nowMs-rate.Per.Milliseconds()); err != nil {
+		return false, err
+	}
+
+	increment := func() (bool, error) {
+		query := fmt.Sprintf("UPDATE %s SET %s = %s + 1 WHERE %s = %s AND %s < %s",
+			table,
+			dialect.QuoteIdentifier("claimed"), dialect.QuoteIdentifier("claimed"),
+			dialect.QuoteIdentifier("name"), dialect.Placeholder(1),
+			dialect.QuoteIdentifier("claimed"), dialect.Placeholder(2))
+		res, err := db.ExecContext(ctx, query, name, rate.Limit)
+		if err != nil {
+			return false, err
+		}
+		affected, _ := res.RowsAffected()
+		return affected == 1, nil
+	}
+	if ok, err := increment(); err != nil || ok {
+		return ok, err
+	}
+
+	// Belum ada baris untuk limit ini (atau jatahnya habis)
+	if exists, err := rateRowExists(ctx, db, dialect, name); err != nil || exists {
+		return false, err
+	}
+	if err := insertRow(ctx, db, dialect, "job_rate_limits", []string{"name", "window_start", "claimed"}, []interface{}{name, nowMs, 1}); err != nil {
+		// Worker lain baru saja membuat barisnya
+		return increment()
+	}
+	return true, nil
+}
+
+func rateRowExists(ctx context.Context, db *sql.DB, dialect dbmanager.Dialect, name string) (bool, error) {
+	var count int
+	query := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s = %s",
+		dialect.QuoteIdentifier("job_rate_limits"), dialect.QuoteIdentifier("name"), dialect.Placeholder(1))
+	err := db.QueryRowContext(ctx, query, name).Scan(&count)
+	return count > 0, err
+}
+
+// giveRate mengembalikan jatah yang diambil untuk job yang batal di-claim
+func giveRate(ctx context.Context, db *sql.DB, dialect dbmanager.Dialect, name string) {
+	query := fmt.Sprintf("UPDATE %s SET %s = %s - 1 WHERE %s = %s AND %s > 0",
+		dialect.QuoteIdentifier("job_rate_limits"),
+		dialect.QuoteIdentifier("claimed"), dialect.QuoteIdentifier("claimed"),
+		dialect.QuoteIdentifier("name"), dialect.Placeholder(1),
+		dialect.QuoteIdentifier("claimed"))
+	db.ExecContext(ctx, query, name)
+}
+
+// unclaim mengembalikan job yang di-claim ke antrian tanpa menghitung percobaannya
+// (rate limit ternyata habis, atau worker dimatikan di tengah job, lihat Requeue)
+func (q *DBQueue) unclaim(ctx context.Context, db *sql.DB, dialect dbmanager.Dialect, job *Job) error {
+	query := fmt.Sprintf("UPDATE %s SET %s = %s, %s = %s - 1, %s = 0 WHERE %s = %s AND %s = %s",
+		dialect.QuoteIdentifier("jobs"),
+		dialect.QuoteIdentifier("status"), dialect.Placeholder(1),
+		dialect.QuoteIdentifier("attempts"), dialect.QuoteIdentifier("attempts"),
+		dialect.QuoteIdentifier("reserved_until"),
+		dialect.QuoteIdentifier("id"), dialect.Placeholder(2),
+		dialect.QuoteIdentifier("status"), dialect.Placeholder(3))
+	_, err := db.ExecContext(ctx, query, "pending", job.ID, "processing")
+	return err
+}
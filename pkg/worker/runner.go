@@ -3,7 +3,12 @@ package worker
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
+	"math/rand"
+	"runtime/debug"
+	"strings"
 	"sync"
 	"time"
 	"github.com/nextcore/zeno-go/pkg/engine"
@@ -11,94 +16,503 @@ import (
 
 // Struktur Data Tugas
 type JobPayload struct {
-	ScriptPath string                 `json:"script_path"`
+	ScriptPath string                 `json:"script_path,omitempty"`
 	Data       map[string]interface{} `json:"data"`
 	CreatedAt  time.Time              `json:"created_at"`
+
+	// Handler adalah nama job.define yang dijalankan, menggantikan ScriptPath
+	Handler string `json:"handler,omitempty"`
+
+	// Chain berisi job berikutnya, di-push satu per satu setelah job ini berhasil
+	Chain []ChainStep `json:"chain,omitempty"`
+	// ChainCatch adalah script yang di-push bila salah satu job chain gagal permanen
+	ChainCatch string `json:"chain_catch,omitempty"`
+	// ChainCatchHandler adalah job.define yang di-push sebagai catch, menggantikan ChainCatch
+	ChainCatchHandler string `json:"chain_catch_handler,omitempty"`
+	// BatchID menghubungkan job dengan progress-nya di job_batches
+	BatchID int64 `json:"batch_id,omitempty"`
 }
 
+// errShutdown membatalkan job yang masih berjalan setelah ShutdownGrace; job-nya dikembalikan ke antrian
+var errShutdown = errors.New("worker stopped before the job finished")
+
 // Fungsi Utama Worker (Berjalan di Background)
 func Start(ctx context.Context, eng *engine.Engine, queue JobQueue, queues []string) {
+	Run(ctx, eng, queue, Options{Queues: queues})
+}
+
+// Run menjalankan worker sampai ctx dibatalkan, lalu menunggu job yang sedang berjalan
+// paling lama ShutdownGrace sebelum mengembalikannya ke antrian
+func Run(ctx context.Context, eng *engine.Engine, queue JobQueue, opts Options) {
 	// 1. CEK: Jika Queue Nil, matikan worker
 	if queue == nil {
 		slog.Info("🚫 Worker disabled: Queue not available")
 		return
 	}
+	if err := opts.Validate(); err != nil {
+		slog.Error("❌ Invalid worker configuration", "error", err)
+		return
+	}
 
-	slog.Info("👷 Background Worker Started", "queues", queues)
+	slog.Info("👷 Background Worker Started", "queues", opts.Queues, "concurrency", opts.poolSize(), "priority", opts.Priority, "limits", opts.Limits, "rate_limits", opts.RateLimits, "timeout", opts.Timeout, "timeouts", opts.Timeouts)
+
+	// Rate limit diperiksa oleh queue saat claim, agar berlaku untuk semua worker sekaligus
+	if len(opts.RateLimits) > 0 {
+		if limited, ok := queue.(RateLimitedQueue); ok {
+			limited.SetRateLimits(opts.RateLimits)
+		} else {
+			slog.Warn("⚠️  This queue does not support rate limits; ignoring them", "rate_limits", opts.RateLimits)
+		}
+	}
+
+	// Queue yang mencapai limit-nya dicoba lagi setelah job-nya selesai dengan membangunkan Reserve
+	if len(opts.Limits) > 0 {
+		if _, ok := queue.(WakeableQueue); !ok {
+			slog.Warn("⚠️  This queue cannot be woken while waiting; a queue that reached its limit is only picked up again after the next job arrives", "limits", opts.Limits)
+		}
+	}
 
 	var wg sync.WaitGroup
 
+	// Queue yang melacak job (DBQueue) mendukung retry, prioritas & limit per queue; queue lain hanya Pop
+	reliable, _ := queue.(ReliableQueue)
+
+	// Slot kosong diambil SEBELUM Reserve, sehingga job tetap pending (bisa diambil proses lain) saat pool penuh
+	slots := make(chan struct{}, opts.poolSize())
+
+	// Jumlah job yang sedang berjalan per queue, untuk opts.Limits. Menutup wake membangunkan
+	// Reserve yang sedang menunggu saat queue yang penuh mendapat slot lagi. Context Reserve sendiri
+	// tidak dibatalkan: claim yang sudah di-commit tidak boleh ditinggalkan.
+	var mu sync.Mutex
+	running := make(map[string]int)
+	var wake chan struct{}
+	rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	// Pembatal context setiap job yang sedang berjalan, untuk job.cancel dan shutdown
+	active := make(map[*Job]context.CancelCauseFunc)
+
+	// Pemeriksaan job yang dibatalkan tetap berjalan selama masa tenggang shutdown
+	watchCtx, stopWatch := context.WithCancel(context.Background())
+	defer stopWatch()
+	if cancellable, ok := queue.(CancellableQueue); ok && reliable != nil {
+		go watchCancelled(watchCtx, cancellable, func() map[int64]context.CancelCauseFunc {
+			mu.Lock()
+			defer mu.Unlock()
+			byID := make(map[int64]context.CancelCauseFunc, len(active))
+			for job, cancel := range active {
+				byID[job.ID] = cancel
+			}
+			return byID
+		})
+	}
+
+	stop := func() {
+		grace := opts.shutdownGrace()
+		slog.Info("👷 Worker Stopping... Waiting for active jobs to finish", "grace", grace)
+		finished := make(chan struct{})
+		go func() {
+			wg.Wait()
+			close(finished)
+		}()
+		select {
+		case <-finished:
+		case <-time.After(grace):
+			mu.Lock()
+			slog.Warn("⏱️  Shutdown grace period over; cancelling running jobs", "count", len(active))
+			for _, cancel := range active {
+				cancel(errShutdown)
+			}
+			mu.Unlock()
+			<-finished
+		}
+		slog.Info("👷 Worker Fully Stopped")
+	}
+
+	// Reaper: kembalikan job milik worker yang mati (reservasi kedaluwarsa) ke antrian
+	if reliable != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			reapExpired(ctx, reliable)
+		}()
+	}
+
 	for {
 		select {
 		case <-ctx.Done():
-			slog.Info("👷 Worker Stopping... Waiting for active jobs to finish")
-			wg.Wait()
-			slog.Info("👷 Worker Fully Stopped")
+			stop()
 			return
-		default:
+		case slots <- struct{}{}:
+		}
+		release := func() { <-slots }
+
+		// Queue yang sudah mencapai limit-nya dilewati sampai salah satu job-nya selesai
+		var woken chan struct{}
+		mu.Lock()
+		eligible := opts.eligibleQueues(running)
+		if len(opts.Limits) > 0 {
+			woken = make(chan struct{})
+			wake = woken
+		}
+		mu.Unlock()
+
+		var job *Job
+		var err error
+		if len(eligible) == 0 && len(opts.Queues) > 0 {
+			select {
+			case <-ctx.Done():
+				err = ctx.Err()
+			case <-woken:
+				err = ErrWoken
+			}
+		} else {
 			// 1. Ambil Tugas dari Queue (Blocking/Polling)
-			queueName, payloadBytes, err := queue.Pop(ctx, queues)
-
-			if err != nil {
-				// Cek jika error bukan context cancelled
-				if ctx.Err() != nil {
-					slog.Info("👷 Worker Stopping... Waiting for active jobs to finish")
-					wg.Wait()
-					slog.Info("👷 Worker Fully Stopped")
-					return
-				}
-				// If error, log and retry
-				slog.Error("❌ Worker Queue Error", "error", err)
-				time.Sleep(1 * time.Second)
+			ordered, prioritized := opts.order(eligible, rnd)
+			job, err = reserve(ctx, queue, reliable, ordered, prioritized, woken)
+		}
+		mu.Lock()
+		wake = nil
+		mu.Unlock()
+
+		if err != nil {
+			release()
+			// Cek jika error bukan context cancelled
+			if ctx.Err() != nil {
+				stop()
+				return
+			}
+			if errors.Is(err, ErrWoken) {
 				continue
 			}
+			// If error, log and retry
+			slog.Error("❌ Worker Queue Error", "error", err)
+			time.Sleep(1 * time.Second)
+			continue
+		}
 
-			payloadStr := string(payloadBytes)
-
-			slog.Info("⚡ New Job Received", "queue", queueName)
+		slog.Info("⚡ New Job Received", "queue", job.Queue, "id", job.ID, "attempt", job.Attempts)
 
-			// 2. Parse Payload
-			var job JobPayload
-			if err := json.Unmarshal([]byte(payloadStr), &job); err != nil {
-				slog.Error("❌ Invalid Job Payload", "error", err)
-				continue
+		// 2. Parse Payload
+		var payload JobPayload
+		if err := json.Unmarshal(job.Payload, &payload); err != nil {
+			release()
+			slog.Error("❌ Invalid Job Payload", "error", err)
+			// Payload rusak tidak akan berhasil di percobaan berikutnya
+			if reliable != nil {
+				if err := reliable.Fail(context.Background(), job, fmt.Errorf("invalid job payload: %v", err), ""); err != nil {
+					slog.Error("❌ Failed to store failed job", "id", job.ID, "error", err)
+				}
 			}
+			continue
+		}
 
-			// 3. Eksekusi Script Zenolang
-			wg.Add(1)
-			go func() {
-				defer wg.Done()
-				executeJob(eng, job)
+		// Context job tidak diturunkan dari ctx: job yang berjalan diberi ShutdownGrace untuk selesai
+		jobCtx, cancelJob := context.WithCancelCause(context.Background())
+		mu.Lock()
+		running[job.Queue]++
+		active[job] = cancelJob
+		mu.Unlock()
+
+		// 3. Eksekusi Script Zenolang
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer release()
+			defer func() {
+				mu.Lock()
+				defer mu.Unlock()
+				if limit := opts.Limits[job.Queue]; limit > 0 && running[job.Queue] == limit && wake != nil {
+					close(wake)
+					wake = nil
+				}
+				running[job.Queue]--
+				delete(active, job)
+				cancelJob(nil)
 			}()
+			processJob(jobCtx, eng, opts, queue, reliable, job, payload)
+		}()
+	}
+}
+
+// reserve mengambil job berikutnya; queue yang bukan ReliableQueue dibungkus sebagai job satu percobaan.
+// wake (boleh nil) menghentikan penantian dengan ErrWoken pada queue yang mendukungnya.
+func reserve(ctx context.Context, queue JobQueue, reliable ReliableQueue, queues []string, prioritized bool, wake <-chan struct{}) (*Job, error) {
+	if wakeable, ok := queue.(WakeableQueue); ok && reliable != nil && wake != nil {
+		return wakeable.ReserveWake(ctx, queues, prioritized, wake)
+	}
+	if reliable != nil {
+		return reliable.Reserve(ctx, queues, prioritized)
+	}
+	queueName, payload, err := queue.Pop(ctx, queues)
+	if err != nil {
+		return nil, err
+	}
+	return &Job{Queue: queueName, Payload: payload, Attempts: 1, Options: JobOptions{Tries: 1}}, nil
+}
+
+// ReapInterval adalah jeda antar pemeriksaan reservasi yang kedaluwarsa
+var ReapInterval = 30 * time.Second
+
+func reapExpired(ctx context.Context, queue ReliableQueue) {
+	ticker := time.NewTicker(ReapInterval)
+	defer ticker.Stop()
+	for {
+		if n, err := queue.ReleaseExpired(ctx); err != nil {
+			if ctx.Err() == nil {
+				slog.Error("❌ Failed to release expired jobs", "error", err)
+			}
+		} else if n > 0 {
+			slog.Warn("♻️  Recovered jobs from a stopped worker", "count", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
 		}
 	}
 }
 
-func executeJob(eng *engine.Engine, job JobPayload) {
-	start := time.Now()
+// heartbeat memperpanjang reservasi job setiap sepertiga lease sampai done ditutup
+func heartbeat(queue ReliableQueue, job *Job, done <-chan struct{}) {
+	ticker := time.NewTicker(job.Lease / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if err := queue.Heartbeat(context.Background(), job); err == ErrLeaseLost {
+				slog.Warn("⚠️  Job reservation expired while running; it may run again", "id", job.ID)
+				return
+			} else if err != nil {
+				slog.Error("❌ Job heartbeat failed", "id", job.ID, "error", err)
+			}
+		}
+	}
+}
+
+// processJob menjalankan job lalu melaporkan hasilnya ke queue: selesai, dicoba lagi, gagal permanen,
+// atau dikembalikan ke antrian bila worker dimatikan sebelum job selesai
+func processJob(jobCtx context.Context, eng *engine.Engine, opts Options, queue JobQueue, reliable ReliableQueue, job *Job, payload JobPayload) {
+	if reliable != nil && job.Lease > 0 {
+		done := make(chan struct{})
+		defer close(done)
+		go heartbeat(reliable, job, done)
+	}
+
+	var stack string
+	var err error
+	if job.Cancelled {
+		// Dibatalkan saat percobaan sebelumnya berjalan (mis. worker-nya mati setelah job.cancel)
+		err = ErrJobCancelled
+	} else {
+		stack, err = executeJob(jobCtx, eng, opts.Handlers, job, payload, opts.timeoutFor(job))
+	}
+
+	// Hasil tetap dicatat walaupun worker sedang dimatikan
+	ctx := context.Background()
+	if errors.Is(err, errShutdown) {
+		requeue(ctx, queue, reliable, job)
+		return
+	}
+	if reliable == nil {
+		// Queue tanpa pelacakan hanya menjalankan job sekali
+		afterJob(ctx, queue, job, payload, err)
+		return
+	}
+
+	switch {
+	case err == nil:
+		// Job berikutnya di chain di-push sebelum Complete: bila worker mati di antaranya,
+		// job ini diulang, tetapi chain tidak terputus. Batch dicatat setelahnya agar tidak terhitung dua kali.
+		// Reservasi diperpanjang lebih dulu, sehingga job yang sudah diambil worker lain tidak melanjutkan chain.
+		if job.Lease > 0 {
+			if errAck := reliable.Heartbeat(ctx, job); errAck != nil {
+				if !leaseLost(job, errAck) {
+					slog.Error("❌ Failed to complete job", "id", job.ID, "error", errAck)
+				}
+				return
+			}
+		}
+		continueChain(ctx, queue, job, payload, nil)
+		if errAck := reliable.Complete(ctx, job); errAck != nil {
+			if !leaseLost(job, errAck) {
+				slog.Error("❌ Failed to complete job", "id", job.ID, "error", errAck)
+			}
+			return
+		}
+		if payload.BatchID != 0 {
+			finishBatchJob(ctx, queue, payload, nil)
+		}
+	case job.CanRetry() && !errors.Is(err, ErrJobCancelled):
+		delay := job.Options.Backoff.After(job.Attempts)
+		slog.Warn("🔁 Job will be retried", "id", job.ID, "attempt", job.Attempts, "tries", job.Options.Tries, "delay", delay)
+		if errAck := reliable.Release(ctx, job, delay, err); errAck != nil && !leaseLost(job, errAck) {
+			slog.Error("❌ Failed to release job", "id", job.ID, "error", errAck)
+		}
+	default:
+		if errors.Is(err, ErrJobCancelled) {
+			slog.Warn("🛑 Job cancelled; moved to failed jobs", "id", job.ID)
+		} else {
+			slog.Error("💀 Job moved to failed jobs", "id", job.ID, "attempts", job.Attempts, "error", err)
+		}
+		if errAck := reliable.Fail(ctx, job, err, stack); errAck != nil {
+			if leaseLost(job, errAck) {
+				return
+			}
+			slog.Error("❌ Failed to store failed job", "id", job.ID, "error", errAck)
+		}
+		afterJob(ctx, queue, job, payload, err)
+	}
+}
+
+// leaseLost melaporkan apakah job sudah diambil worker lain setelah reservasinya kedaluwarsa.
+// Hasil percobaan ini diabaikan: worker lain yang mencatatnya.
+func leaseLost(job *Job, err error) bool {
+	if !errors.Is(err, ErrLeaseLost) {
+		return false
+	}
+	slog.Warn("⚠️  Job was taken over by another worker after its reservation expired; result discarded", "id", job.ID, "attempt", job.Attempts)
+	return true
+}
 
-	// Load Script
-	root, err := engine.LoadScript(job.ScriptPath)
+// requeue mengembalikan job yang dihentikan saat shutdown ke antrian. Queue tanpa pelacakan menerima payload-nya lagi.
+func requeue(ctx context.Context, queue JobQueue, reliable ReliableQueue, job *Job) {
+	var err error
+	if reliable != nil {
+		err = reliable.Requeue(ctx, job)
+	} else {
+		err = queue.Push(ctx, job.Queue, job.Payload)
+	}
 	if err != nil {
-		slog.Error("❌ Job Script Not Found", "path", job.ScriptPath, "error", err)
+		slog.Error("❌ Failed to release job back to the queue", "id", job.ID, "error", err)
 		return
 	}
+	slog.Warn("↩️  Job released back to the queue", "id", job.ID, "queue", job.Queue)
+}
+
+// executeJob menjalankan script atau handler job dengan batas waktu timeout (0 = tanpa batas);
+// bila gagal, mengembalikan stack (lokasi script / stack panic) beserta error
+func executeJob(ctx context.Context, eng *engine.Engine, handlers *Handlers, job *Job, payload JobPayload, timeout time.Duration) (string, error) {
+	start := time.Now()
+	name := payload.Name()
+
+	root, err := jobRoot(handlers, payload)
+	if err != nil {
+		slog.Error("❌ Job Not Found", "job", name, "error", err)
+		return name, err
+	}
 
 	// Siapkan Scope (Inject Data)
 	scope := engine.NewScope(nil)
-	for k, v := range job.Data {
+	for k, v := range payload.Data {
 		scope.Set(k, v)
 	}
 
 	// Set variabel standar job
-	scope.Set("job_created_at", job.CreatedAt)
+	scope.Set("job_created_at", payload.CreatedAt)
+	scope.Set("job_id", job.ID)
+	scope.Set("job_attempt", job.Attempts)
+	if payload.BatchID != 0 {
+		scope.Set("job_batch_id", payload.BatchID)
+	}
 
 	// Execute
-	// Gunakan Background Context (agar tidak putus jika request http putus)
-	ctx := context.Background()
-	if err := eng.Execute(ctx, root, scope); err != nil {
-		slog.Error("❌ Job Failed", "path", job.ScriptPath, "error", err)
-	} else {
-		slog.Info("✅ Job Completed", "path", job.ScriptPath, "duration", time.Since(start))
+	// ctx dibatalkan oleh job.cancel atau shutdown; tidak terikat ke request http yang meng-enqueue job
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, timeout, fmt.Errorf("job timed out after %s", timeout))
+		defer cancel()
+	}
+
+	panicStack, err := runScript(ctx, eng, root, scope)
+	if errors.Is(err, errShutdown) {
+		return "", err
+	}
+	if err != nil {
+		slog.Error("❌ Job Failed", "job", name, "error", err)
+		return jobStack(name, err, panicStack), err
+	}
+	slog.Info("✅ Job Completed", "job", name, "duration", time.Since(start))
+	return "", nil
+}
+
+// stuckScriptWarning adalah jeda setelah pembatalan sebelum script yang belum juga selesai dicatat di log
+var stuckScriptWarning = time.Second
+
+// runScript menjalankan root sampai selesai. Bila ctx dibatalkan, error-nya context.Cause, tetapi
+// runScript tetap menunggu script-nya kembali. Panic dikembalikan sebagai error beserta stack-nya.
+func runScript(ctx context.Context, eng *engine.Engine, root *engine.Node, scope *engine.Scope) (string, error) {
+	type result struct {
+		err   error
+		stack string
+	}
+	done := make(chan result, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- result{fmt.Errorf("panic: %v", r), string(debug.Stack())}
+			}
+		}()
+		done <- result{err: eng.Execute(ctx, root, scope)}
+	}()
+
+	select {
+	case res := <-done:
+		return res.stack, res.err
+	case <-ctx.Done():
+	}
+
+	// Script yang tidak memeriksa context tidak bisa dihentikan dari luar. Slot worker, reservasi job
+	// dan lock tugas terjadwal tetap dipegang sampai script benar-benar selesai, agar run yang sama
+	// tidak berjalan dua kali bersamaan.
+	var res result
+	select {
+	case res = <-done:
+	case <-time.After(stuckScriptWarning):
+		slog.Warn("⏳ Script ignores cancellation; keeping its slot until it returns", "cause", context.Cause(ctx))
+		res = <-done
+	}
+	if res.err == nil {
+		// Pekerjaannya tetap selesai: tidak dijalankan ulang
+		return "", nil
+	}
+	return "", context.Cause(ctx)
+}
+
+// Name menamai job untuk log dan failed_jobs.stack: "handler: send_welcome" atau "script: src/jobs/x.zl"
+func (p JobPayload) Name() string {
+	if p.Handler != "" {
+		return "handler: " + p.Handler
+	}
+	return "script: " + p.ScriptPath
+}
+
+// jobRoot mengembalikan node yang dijalankan: body handler, atau script yang dimuat dari ScriptPath
+func jobRoot(handlers *Handlers, payload JobPayload) (*engine.Node, error) {
+	if payload.Handler == "" {
+		return engine.LoadScript(payload.ScriptPath)
+	}
+	if handlers == nil {
+		return nil, fmt.Errorf("job handler '%s' cannot run: this worker has no job.define handlers loaded", payload.Handler)
+	}
+	handler, ok := handlers.Get(payload.Handler)
+	if !ok {
+		return nil, fmt.Errorf("job handler '%s' is not defined (job.define: '%s' { ... })", payload.Handler, payload.Handler)
+	}
+	return &engine.Node{Name: "root", Children: handler.Body}, nil
+}
+
+// jobStack menyusun informasi lokasi error untuk failed_jobs.stack
+func jobStack(name string, err error, panicStack string) string {
+	lines := []string{name}
+	var diag engine.Diagnostic
+	if errors.As(err, &diag) && diag.Filename != "" {
+		lines = append(lines, fmt.Sprintf("at %s:%d:%d", diag.Filename, diag.Line, diag.Col))
+	}
+	if panicStack != "" {
+		lines = append(lines, panicStack)
 	}
+	return strings.Join(lines, "\n")
 }
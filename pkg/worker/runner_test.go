@@ -0,0 +1,332 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nextcore/zeno-go/pkg/engine"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testValue membaca nilai slot test: '$nama' diambil dari scope, selain itu string tanpa tanda kutip
+func testValue(node *engine.Node, scope *engine.Scope) string {
+	s := strings.TrimSpace(fmt.Sprint(node.Value))
+	if strings.HasPrefix(s, "$") {
+		v, _ := scope.Get(s[1:])
+		return fmt.Sprint(v)
+	}
+	return strings.Trim(s, `'"`)
+}
+
+// newTestEngine membuat engine dengan slot test.record (mencatat nilai) dan test.fail (selalu error),
+// beserta pembuat file script job
+func newTestEngine(t *testing.T) (*engine.Engine, func() []string, func(string) string) {
+	eng := engine.NewEngine()
+
+	var mu sync.Mutex
+	var records []string
+	eng.Register("test.record", func(ctx context.Context, node *engine.Node, scope *engine.Scope) error {
+		mu.Lock()
+		defer mu.Unlock()
+		records = append(records, testValue(node, scope))
+		return nil
+	}, engine.SlotMeta{})
+	eng.Register("test.fail", func(ctx context.Context, node *engine.Node, scope *engine.Scope) error {
+		return errors.New(testValue(node, scope))
+	}, engine.SlotMeta{})
+
+	dir := t.TempDir()
+	script := func(body string) string {
+		path := filepath.Join(dir, fmt.Sprintf("job_%d.zl", time.Now().UnixNano()))
+		if err := os.WriteFile(path, []byte(body+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	get := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), records...)
+	}
+	return eng, get, script
+}
+
+// registerWait mendaftarkan test.wait, yang menunggu sampai context job dibatalkan
+func registerWait(eng *engine.Engine) <-chan struct{} {
+	started := make(chan struct{}, 10)
+	eng.Register("test.wait", func(ctx context.Context, node *engine.Node, scope *engine.Scope) error {
+		started <- struct{}{}
+		<-ctx.Done()
+		return ctx.Err()
+	}, engine.SlotMeta{})
+	return started
+}
+
+// runUntil menjalankan worker sampai done terpenuhi (maksimal 5 detik)
+func runUntil(t *testing.T, eng *engine.Engine, queue JobQueue, opts Options, done func() bool) {
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		Run(ctx, eng, queue, opts)
+		close(stopped)
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for !done() && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+	}
+	// Beri kesempatan job yang tidak diharapkan (mis. percobaan ulang) untuk muncul
+	time.Sleep(200 * time.Millisecond)
+	cancel()
+	<-stopped
+}
+
+func failedErrors(t *testing.T, db *sql.DB) []string {
+	rows, err := db.Query("SELECT error FROM failed_jobs ORDER BY id")
+	assert.NoError(t, err)
+	defer rows.Close()
+	var errs []string
+	for rows.Next() {
+		var e string
+		rows.Scan(&e)
+		errs = append(errs, e)
+	}
+	return errs
+}
+
+func TestRateLimit(t *testing.T) {
+	ctx := context.Background()
+	queue, db := newTestQueue(t)
+	eng, records, script := newTestEngine(t)
+
+	sms := script("test.record: 'sms'")
+	mail := script("test.record: 'mail'")
+	for i := 0; i < 4; i++ {
+		assert.NoError(t, Enqueue(ctx, queue, "sms", JobPayload{ScriptPath: sms}, JobOptions{}))
+	}
+	// Limit per key berlaku lintas queue
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, Enqueue(ctx, queue, "default", JobPayload{ScriptPath: mail}, JobOptions{RateKey: "mailgun"}))
+	}
+
+	opts := Options{
+		Queues:      []string{"sms", "default"},
+		Concurrency: 3,
+		RateLimits: map[string]Rate{
+			"sms":     {Limit: 2, Per: time.Hour},
+			"mailgun": {Limit: 1, Per: time.Hour},
+		},
+	}
+	runUntil(t, eng, queue, opts, func() bool { return len(records()) == 3 })
+	assert.ElementsMatch(t, []string{"sms", "sms", "mail"}, records())
+
+	var pending, attempts int
+	assert.NoError(t, db.QueryRow("SELECT COUNT(*), COALESCE(SUM(attempts), 0) FROM jobs WHERE status = 'pending'").Scan(&pending, &attempts))
+	assert.Equal(t, 4, pending)
+	assert.Equal(t, 0, attempts, "jobs held back by the rate limit must not lose an attempt")
+
+	// Window berikutnya membuka jatah lagi
+	_, err := db.Exec("UPDATE job_rate_limits SET window_start = ?", time.Now().Add(-time.Hour).UnixMilli())
+	assert.NoError(t, err)
+	runUntil(t, eng, queue, opts, func() bool { return len(records()) == 6 })
+	assert.Len(t, records(), 6)
+}
+
+func TestTimeout(t *testing.T) {
+	ctx := context.Background()
+	queue, db := newTestQueue(t)
+	eng, _, script := newTestEngine(t)
+	registerWait(eng)
+
+	wait := script("test.wait: true")
+	assert.NoError(t, Enqueue(ctx, queue, "reports", JobPayload{ScriptPath: wait}, JobOptions{}))
+	opts := Options{
+		Queues:      []string{"reports"},
+		Concurrency: 1,
+		Timeout:     time.Hour,
+		Timeouts:    map[string]time.Duration{"reports": 100 * time.Millisecond},
+	}
+	runUntil(t, eng, queue, opts, func() bool { return len(failedErrors(t, db)) == 1 })
+	assert.Equal(t, []string{"job timed out after 100ms"}, failedErrors(t, db))
+
+	// Timeout job sendiri didahulukan dari timeout queue
+	assert.NoError(t, Enqueue(ctx, queue, "reports", JobPayload{ScriptPath: wait}, JobOptions{Timeout: time.Second}))
+	runUntil(t, eng, queue, opts, func() bool { return len(failedErrors(t, db)) == 2 })
+	assert.Equal(t, "job timed out after 1s", failedErrors(t, db)[1])
+}
+
+func TestShutdownGrace(t *testing.T) {
+	ctx := context.Background()
+	opts := Options{Queues: []string{"default"}, Concurrency: 1, ShutdownGrace: 200 * time.Millisecond}
+
+	// startWorker menjalankan worker sampai job pertama dimulai, lalu mematikannya
+	startWorker := func(eng *engine.Engine, queue JobQueue, started <-chan struct{}) <-chan struct{} {
+		runCtx, cancel := context.WithCancel(ctx)
+		stopped := make(chan struct{})
+		go func() {
+			Run(runCtx, eng, queue, opts)
+			close(stopped)
+		}()
+		select {
+		case <-started:
+		case <-time.After(5 * time.Second):
+			t.Fatal("job did not start")
+		}
+		cancel()
+		return stopped
+	}
+
+	t.Run("requeue", func(t *testing.T) {
+		queue, db := newTestQueue(t)
+		eng, _, script := newTestEngine(t)
+		started := registerWait(eng)
+		assert.NoError(t, Enqueue(ctx, queue, "default", JobPayload{ScriptPath: script("test.wait: true")}, JobOptions{}))
+
+		begin := time.Now()
+		select {
+		case <-startWorker(eng, queue, started):
+		case <-time.After(5 * time.Second):
+			t.Fatal("worker did not stop after the grace period")
+		}
+		assert.GreaterOrEqual(t, time.Since(begin), 200*time.Millisecond)
+
+		var status string
+		var attempts int
+		assert.NoError(t, db.QueryRow("SELECT status, attempts FROM jobs").Scan(&status, &attempts))
+		assert.Equal(t, "pending", status)
+		assert.Equal(t, 0, attempts, "a job interrupted by shutdown must not lose an attempt")
+		assert.Empty(t, failedErrors(t, db))
+	})
+
+	t.Run("script ignores cancellation", func(t *testing.T) {
+		queue, db := newTestQueue(t)
+		eng, _, script := newTestEngine(t)
+
+		// Job yang mengabaikan context, seperti panggilan HTTP yang menggantung
+		started := make(chan struct{}, 1)
+		unblock := make(chan struct{})
+		eng.Register("test.hang", func(ctx context.Context, node *engine.Node, scope *engine.Scope) error {
+			started <- struct{}{}
+			<-unblock
+			return nil
+		}, engine.SlotMeta{})
+		assert.NoError(t, Enqueue(ctx, queue, "default", JobPayload{ScriptPath: script("test.hang: true")}, JobOptions{}))
+
+		// Job tidak dikembalikan ke antrian selama script-nya masih berjalan
+		stopped := startWorker(eng, queue, started)
+		select {
+		case <-stopped:
+			t.Fatal("worker stopped while the script was still running")
+		case <-time.After(500 * time.Millisecond):
+		}
+		var status string
+		assert.NoError(t, db.QueryRow("SELECT status FROM jobs").Scan(&status))
+		assert.NotEqual(t, "pending", status, "the job must stay reserved while its script runs")
+
+		close(unblock)
+		select {
+		case <-stopped:
+		case <-time.After(5 * time.Second):
+			t.Fatal("worker did not stop after the script returned")
+		}
+		var count int
+		assert.NoError(t, db.QueryRow("SELECT COUNT(*) FROM jobs").Scan(&count))
+		assert.Equal(t, 0, count, "a script that finished after all counts as completed")
+	})
+}
+
+func TestTimeoutKeepsSlot(t *testing.T) {
+	ctx := context.Background()
+	queue, db := newTestQueue(t)
+	eng, _, script := newTestEngine(t)
+
+	// Script yang mengabaikan timeout dan tetap gagal; percobaan ulangnya tidak boleh tumpang tindih
+	var mu sync.Mutex
+	var runs, active, maxActive int
+	eng.Register("test.slow", func(ctx context.Context, node *engine.Node, scope *engine.Scope) error {
+		mu.Lock()
+		runs++
+		active++
+		maxActive = max(maxActive, active)
+		mu.Unlock()
+		time.Sleep(300 * time.Millisecond)
+		mu.Lock()
+		active--
+		mu.Unlock()
+		return errors.New("upstream down")
+	}, engine.SlotMeta{})
+	assert.NoError(t, Enqueue(ctx, queue, "default", JobPayload{ScriptPath: script("test.slow: true")}, JobOptions{Tries: 2}))
+
+	runUntil(t, eng, queue, Options{Queues: []string{"default"}, Concurrency: 2, Timeout: 50 * time.Millisecond}, func() bool { return len(failedErrors(t, db)) == 1 })
+	assert.Equal(t, []string{"job timed out after 50ms"}, failedErrors(t, db))
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 2, runs)
+	assert.Equal(t, 1, maxActive, "a retry must not start while the timed-out run is still going")
+}
+
+func TestCancelRunning(t *testing.T) {
+	defer func(d time.Duration) { CancelPollInterval = d }(CancelPollInterval)
+	CancelPollInterval = 20 * time.Millisecond
+	ctx := context.Background()
+
+	queue, db := newTestQueue(t)
+	eng, _, script := newTestEngine(t)
+	started := registerWait(eng)
+
+	id, err := EnqueueID(ctx, queue, "default", JobPayload{ScriptPath: script("test.wait: true")}, JobOptions{Tries: 3})
+	assert.NoError(t, err)
+	go func() {
+		<-started
+		found, err := queue.Cancel(ctx, id)
+		assert.NoError(t, err)
+		assert.True(t, found)
+	}()
+
+	runUntil(t, eng, queue, Options{Queues: []string{"default"}, Concurrency: 1}, func() bool { return len(failedErrors(t, db)) == 1 })
+	assert.Equal(t, []string{"job cancelled"}, failedErrors(t, db), "a cancelled job is not retried")
+	var count int
+	assert.NoError(t, db.QueryRow("SELECT COUNT(*) FROM jobs").Scan(&count))
+	assert.Equal(t, 0, count)
+}
+
+func TestQueueLimitWakesReserve(t *testing.T) {
+	ctx := context.Background()
+	queue, _ := newTestQueue(t)
+	queue.PollInterval = time.Minute
+	eng, records, script := newTestEngine(t)
+
+	var mu sync.Mutex
+	var active, maxActive int
+	eng.Register("test.busy", func(ctx context.Context, node *engine.Node, scope *engine.Scope) error {
+		mu.Lock()
+		active++
+		maxActive = max(maxActive, active)
+		mu.Unlock()
+		time.Sleep(100 * time.Millisecond)
+		mu.Lock()
+		active--
+		mu.Unlock()
+		return nil
+	}, engine.SlotMeta{})
+	sms := script("test.busy: true\ntest.record: 'sms'")
+	for i := 0; i < 2; i++ {
+		assert.NoError(t, Enqueue(ctx, queue, "sms", JobPayload{ScriptPath: sms}, JobOptions{}))
+	}
+
+	// Job sms kedua diambil segera setelah yang pertama selesai, tanpa menunggu PollInterval
+	opts := Options{Queues: []string{"sms", "default"}, Concurrency: 3, Limits: map[string]int{"sms": 1}}
+	runUntil(t, eng, queue, opts, func() bool { return len(records()) == 2 })
+	assert.Equal(t, []string{"sms", "sms"}, records())
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 1, maxActive)
+}
@@ -0,0 +1,316 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/nextcore/zeno-go/pkg/engine"
+	"github.com/nextcore/zenoengine/pkg/dbmanager"
+)
+
+// ScheduledTask adalah tugas periodik yang didefinisikan dengan schedule.cron atau schedule.every
+type ScheduledTask struct {
+	Name string
+	// Spec adalah jadwal seperti ditulis di script ('0 2 * * *' atau '5m'), untuk schedule:list
+	Spec     string
+	Schedule Schedule
+	Body     []*engine.Node
+	// Overlap mengizinkan run baru dimulai walaupun run sebelumnya belum selesai
+	Overlap bool
+	// Timeout membatasi durasi satu run (0 = tanpa batas)
+	Timeout time.Duration
+	// Source adalah lokasi definisi (file:line) untuk pesan error
+	Source string
+}
+
+// ScheduledTasks menyimpan tugas terjadwal per nama. Aman dipakai bersamaan oleh scheduler dan reload script.
+type ScheduledTasks struct {
+	mu     sync.RWMutex
+	byName map[string]*ScheduledTask
+}
+
+func NewScheduledTasks() *ScheduledTasks {
+	return &ScheduledTasks{byName: make(map[string]*ScheduledTask)}
+}
+
+// Define mendaftarkan tugas. Nama yang sudah didefinisikan di lokasi lain ditolak,
+// karena lock di database dipegang per nama.
+func (t *ScheduledTasks) Define(task *ScheduledTask) error {
+	if task.Name == "" {
+		return fmt.Errorf("scheduled task name must not be empty")
+	}
+	if len(task.Body) == 0 {
+		return fmt.Errorf("scheduled task '%s' has an empty body", task.Name)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if existing, ok := t.byName[task.Name]; ok && existing.Source != task.Source {
+		return fmt.Errorf("scheduled task '%s' is already defined at %s (give one of them a different name:)", task.Name, existing.Source)
+	}
+	t.byName[task.Name] = task
+	return nil
+}
+
+// Get mengembalikan tugas dengan nama tersebut
+func (t *ScheduledTasks) Get(name string) (*ScheduledTask, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	task, ok := t.byName[name]
+	return task, ok
+}
+
+// List mengembalikan semua tugas, terurut berdasarkan nama
+func (t *ScheduledTasks) List() []*ScheduledTask {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	tasks := make([]*ScheduledTask, 0, len(t.byName))
+	for _, task := range t.byName {
+		tasks = append(tasks, task)
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].Name < tasks[j].Name })
+	return tasks
+}
+
+// Replace mengganti seluruh isi dengan tugas dari other (mis. setelah main.zl dimuat ulang).
+// Run yang sedang berjalan tetap memakai body lama.
+func (t *ScheduledTasks) Replace(other *ScheduledTasks) {
+	other.mu.RLock()
+	byName := make(map[string]*ScheduledTask, len(other.byName))
+	for name, task := range other.byName {
+		byName[name] = task
+	}
+	other.mu.RUnlock()
+
+	t.mu.Lock()
+	t.byName = byName
+	t.mu.Unlock()
+}
+
+// ScheduleConnection mengembalikan nama koneksi tempat lock tugas terjadwal disimpan:
+// SCHEDULE_CONNECTION, atau "internal". Instance yang memakai koneksi yang sama
+// berbagi lock, sehingga setiap tick hanya dijalankan satu instance.
+func ScheduleConnection() string {
+	if name := os.Getenv("SCHEDULE_CONNECTION"); name != "" {
+		return name
+	}
+	return "internal"
+}
+
+// ScheduleLockLease adalah lama lock overlap berlaku tanpa heartbeat. Run milik instance
+// yang mati tidak menghalangi run berikutnya lebih lama dari ini.
+var ScheduleLockLease = time.Minute
+
+// scheduledTasksColumns menyimpan tick terakhir yang sudah di-claim, lock overlap, dan hasil run terakhir
+var scheduledTasksColumns = []queueColumn{
+	{Name: "name", Type: "key"},
+	{Name: "last_tick", Type: "bigint", Default: "0"},    // Unix timestamp tick terakhir yang di-claim
+	{Name: "locked_until", Type: "bigint", Default: "0"}, // Unix timestamp, 0 = tidak sedang berjalan
+	{Name: "lock_owner", Type: "string", Null: true},
+	{Name: "last_started_at", Type: "datetime", Null: true},
+	{Name: "last_finished_at", Type: "datetime", Null: true},
+	{Name: "last_duration_ms", Type: "bigint", Default: "0"},
+	{Name: "last_error", Type: "text", Null: true},
+}
+
+// TaskStatus adalah status tugas terjadwal di tabel scheduled_tasks, untuk schedule:list
+type TaskStatus struct {
+	Name           string
+	LastStartedAt  time.Time
+	LastFinishedAt time.Time
+	LastDuration   time.Duration
+	LastError      string
+	Running        bool
+}
+
+// ScheduleStore menyimpan tick dan lock tugas terjadwal di tabel scheduled_tasks
+type ScheduleStore struct {
+	dbMgr    *dbmanager.DBManager
+	connName string
+
+	schemaMu    sync.Mutex
+	schemaReady bool
+}
+
+func NewScheduleStore(dbMgr *dbmanager.DBManager, connName string) *ScheduleStore {
+	return &ScheduleStore{dbMgr: dbMgr, connName: connName}
+}
+
+func (s *ScheduleStore) ensureSchema(ctx context.Context) (*sql.DB, dbmanager.Dialect, error) {
+	db := s.dbMgr.GetConnection(s.connName)
+	if db == nil {
+		return nil, nil, fmt.Errorf("schedule database connection '%s' not found", s.connName)
+	}
+	dialect := s.dbMgr.GetDialect(s.connName)
+
+	s.schemaMu.Lock()
+	defer s.schemaMu.Unlock()
+	if !s.schemaReady {
+		if err := ensureQueueTable(ctx, db, dialect, "scheduled_tasks", scheduledTasksColumns); err != nil {
+			return nil, nil, err
+		}
+		s.schemaReady = true
+	}
+	return db, dialect, nil
+}
+
+// ensureRow membuat baris tugas bila belum ada. Insert yang kalah dari instance lain diabaikan.
+func (s *ScheduleStore) ensureRow(ctx context.Context, db *sql.DB, dialect dbmanager.Dialect, name string) error {
+	var count int
+	query := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s = %s",
+		dialect.QuoteIdentifier("scheduled_tasks"), dialect.QuoteIdentifier("name"), dialect.Placeholder(1))
+	if err := db.QueryRowContext(ctx, query, name).Scan(&count); err != nil || count > 0 {
+		return err
+	}
+	if err := insertRow(ctx, db, dialect, "scheduled_tasks", []string{"name"}, []interface{}{name}); err != nil {
+		// Instance lain baru saja membuat barisnya
+		if errCheck := db.QueryRowContext(ctx, query, name).Scan(&count); errCheck == nil && count > 0 {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// ClaimTick mencatat bahwa tick (waktu jadwal) tugas ini dijalankan. Hanya satu instance
+// yang mendapat true untuk tick yang sama; instance lain melewatinya.
+func (s *ScheduleStore) ClaimTick(ctx context.Context, name string, tick time.Time) (bool, error) {
+	db, dialect, err := s.ensureSchema(ctx)
+	if err != nil {
+		return false, err
+	}
+	if err := s.ensureRow(ctx, db, dialect, name); err != nil {
+		return false, err
+	}
+	query := fmt.Sprintf("UPDATE %s SET %s = %s WHERE %s = %s AND %s < %s",
+		dialect.QuoteIdentifier("scheduled_tasks"),
+		dialect.QuoteIdentifier("last_tick"), dialect.Placeholder(1),
+		dialect.QuoteIdentifier("name"), dialect.Placeholder(2),
+		dialect.QuoteIdentifier("last_tick"), dialect.Placeholder(3))
+	res, err := db.ExecContext(ctx, query, tick.Unix(), name, tick.Unix())
+	if err != nil {
+		return false, err
+	}
+	affected, _ := res.RowsAffected()
+	return affected == 1, nil
+}
+
+// Start mengambil lock run untuk owner. Tanpa overlap, lock gagal diambil (false) selama
+// run sebelumnya masih berjalan dan lease-nya belum habis.
+func (s *ScheduleStore) Start(ctx context.Context, name, owner string, overlap bool) (bool, error) {
+	db, dialect, err := s.ensureSchema(ctx)
+	if err != nil {
+		return false, err
+	}
+	if err := s.ensureRow(ctx, db, dialect, name); err != nil {
+		return false, err
+	}
+
+	now := time.Now()
+	a := &sqlArgs{dialect: dialect}
+	query := fmt.Sprintf("UPDATE %s SET %s = %s, %s = %s, %s = %s WHERE %s = %s",
+		dialect.QuoteIdentifier("scheduled_tasks"),
+		dialect.QuoteIdentifier("locked_until"), a.add(now.Add(ScheduleLockLease).Unix()),
+		dialect.QuoteIdentifier("lock_owner"), a.add(owner),
+		dialect.QuoteIdentifier("last_started_at"), a.add(now),
+		dialect.QuoteIdentifier("name"), a.add(name))
+	if !overlap {
+		query += fmt.Sprintf(" AND %s <= %s", dialect.QuoteIdentifier("locked_until"), a.add(now.Unix()))
+	}
+	res, err := db.ExecContext(ctx, query, a.values...)
+	if err != nil {
+		return false, err
+	}
+	affected, _ := res.RowsAffected()
+	return affected == 1, nil
+}
+
+// Heartbeat memperpanjang lock selama run milik owner masih berjalan
+func (s *ScheduleStore) Heartbeat(ctx context.Context, name, owner string) error {
+	db, dialect, err := s.ensureSchema(ctx)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf("UPDATE %s SET %s = %s WHERE %s = %s AND %s = %s",
+		dialect.QuoteIdentifier("scheduled_tasks"),
+		dialect.QuoteIdentifier("locked_until"), dialect.Placeholder(1),
+		dialect.QuoteIdentifier("name"), dialect.Placeholder(2),
+		dialect.QuoteIdentifier("lock_owner"), dialect.Placeholder(3))
+	_, err = db.ExecContext(ctx, query, time.Now().Add(ScheduleLockLease).Unix(), name, owner)
+	return err
+}
+
+// Finish mencatat hasil run dan melepas lock bila masih dipegang owner
+// (dengan overlap, run yang lebih baru mungkin sudah mengambil alih lock-nya)
+func (s *ScheduleStore) Finish(ctx context.Context, name, owner string, duration time.Duration, runErr error) error {
+	db, dialect, err := s.ensureSchema(ctx)
+	if err != nil {
+		return err
+	}
+	var lastError interface{}
+	if runErr != nil {
+		lastError = runErr.Error()
+	}
+
+	a := &sqlArgs{dialect: dialect}
+	query := fmt.Sprintf("UPDATE %s SET %s = %s, %s = %s, %s = %s WHERE %s = %s",
+		dialect.QuoteIdentifier("scheduled_tasks"),
+		dialect.QuoteIdentifier("last_finished_at"), a.add(time.Now()),
+		dialect.QuoteIdentifier("last_duration_ms"), a.add(duration.Milliseconds()),
+		dialect.QuoteIdentifier("last_error"), a.add(lastError),
+		dialect.QuoteIdentifier("name"), a.add(name))
+	if _, err := db.ExecContext(ctx, query, a.values...); err != nil {
+		return err
+	}
+
+	query = fmt.Sprintf("UPDATE %s SET %s = 0, %s = NULL WHERE %s = %s AND %s = %s",
+		dialect.QuoteIdentifier("scheduled_tasks"),
+		dialect.QuoteIdentifier("locked_until"),
+		dialect.QuoteIdentifier("lock_owner"),
+		dialect.QuoteIdentifier("name"), dialect.Placeholder(1),
+		dialect.QuoteIdentifier("lock_owner"), dialect.Placeholder(2))
+	_, err = db.ExecContext(ctx, query, name, owner)
+	return err
+}
+
+// Status mengembalikan status semua tugas yang pernah berjalan, per nama
+func (s *ScheduleStore) Status(ctx context.Context) (map[string]TaskStatus, error) {
+	db, dialect, err := s.ensureSchema(ctx)
+	if err != nil {
+		return nil, err
+	}
+	query := fmt.Sprintf("SELECT %s, %s, %s, %s, %s, %s FROM %s",
+		dialect.QuoteIdentifier("name"), dialect.QuoteIdentifier("locked_until"),
+		dialect.QuoteIdentifier("last_started_at"), dialect.QuoteIdentifier("last_finished_at"),
+		dialect.QuoteIdentifier("last_duration_ms"), dialect.QuoteIdentifier("last_error"),
+		dialect.QuoteIdentifier("scheduled_tasks"))
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	now := time.Now().Unix()
+	statuses := make(map[string]TaskStatus)
+	for rows.Next() {
+		var st TaskStatus
+		var lockedUntil, durationMs int64
+		var startedAt, finishedAt interface{}
+		var lastError sql.NullString
+		if err := rows.Scan(&st.Name, &lockedUntil, &startedAt, &finishedAt, &durationMs, &lastError); err != nil {
+			return nil, err
+		}
+		st.Running = lockedUntil > now
+		st.LastStartedAt = toTime(startedAt)
+		st.LastFinishedAt = toTime(finishedAt)
+		st.LastDuration = time.Duration(durationMs) * time.Millisecond
+		st.LastError = lastError.String
+		statuses[st.Name] = st
+	}
+	return statuses, rows.Err()
+}
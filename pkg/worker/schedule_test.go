@@ -0,0 +1,175 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nextcore/zeno-go/pkg/engine"
+	"github.com/nextcore/zenoengine/pkg/dbmanager"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestScheduleStore(t *testing.T) *ScheduleStore {
+	dbMgr := dbmanager.NewDBManager()
+	if err := dbMgr.AddConnection("internal", "sqlite", ":memory:", 1, 1); err != nil {
+		t.Fatalf("Failed to create in-memory db: %v", err)
+	}
+	t.Cleanup(func() { dbMgr.Close() })
+	return NewScheduleStore(dbMgr, "internal")
+}
+
+func TestScheduleLock(t *testing.T) {
+	ctx := context.Background()
+	store := newTestScheduleStore(t)
+	tick := time.Date(2026, 3, 10, 2, 0, 0, 0, time.UTC)
+
+	// Setiap tick hanya di-claim sekali, walaupun semua instance mencobanya
+	claimed, err := store.ClaimTick(ctx, "nightly", tick)
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+	claimed, err = store.ClaimTick(ctx, "nightly", tick)
+	assert.NoError(t, err)
+	assert.False(t, claimed)
+	claimed, err = store.ClaimTick(ctx, "nightly", tick.Add(24*time.Hour))
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+
+	// Lock overlap: run kedua dilewati sampai run pertama selesai
+	started, err := store.Start(ctx, "nightly", "a", false)
+	assert.NoError(t, err)
+	assert.True(t, started)
+	started, err = store.Start(ctx, "nightly", "b", false)
+	assert.NoError(t, err)
+	assert.False(t, started)
+
+	statuses, err := store.Status(ctx)
+	assert.NoError(t, err)
+	assert.True(t, statuses["nightly"].Running)
+
+	assert.NoError(t, store.Finish(ctx, "nightly", "a", 1500*time.Millisecond, errors.New("smtp down")))
+	statuses, err = store.Status(ctx)
+	assert.NoError(t, err)
+	assert.False(t, statuses["nightly"].Running)
+	assert.Equal(t, "smtp down", statuses["nightly"].LastError)
+	assert.Equal(t, 1500*time.Millisecond, statuses["nightly"].LastDuration)
+	assert.False(t, statuses["nightly"].LastStartedAt.IsZero())
+
+	started, err = store.Start(ctx, "nightly", "b", false)
+	assert.NoError(t, err)
+	assert.True(t, started)
+
+	t.Run("run task", func(t *testing.T) {
+		eng, records, _ := newTestEngine(t)
+		task := &ScheduledTask{
+			Name: "report",
+			Spec: "0 2 * * *",
+			Body: []*engine.Node{{Name: "test.record", Value: "$schedule_name"}},
+		}
+
+		ran, err := RunTask(ctx, eng, store, task, false)
+		assert.NoError(t, err)
+		assert.True(t, ran)
+		assert.Equal(t, []string{"report"}, records())
+
+		// Lock masih dipegang run lain: dilewati, kecuali dengan force (schedule:run --force)
+		started, err := store.Start(ctx, "report", "other", false)
+		assert.NoError(t, err)
+		assert.True(t, started)
+		ran, err = RunTask(ctx, eng, store, task, false)
+		assert.NoError(t, err)
+		assert.False(t, ran)
+		ran, err = RunTask(ctx, eng, store, task, true)
+		assert.NoError(t, err)
+		assert.True(t, ran)
+		assert.Equal(t, []string{"report", "report"}, records())
+
+		failing := &ScheduledTask{Name: "broken", Spec: "5m", Body: []*engine.Node{{Name: "test.fail", Value: "'disk full'"}}}
+		ran, err = RunTask(ctx, eng, store, failing, false)
+		assert.True(t, ran)
+		assert.EqualError(t, err, "disk full")
+		statuses, err := store.Status(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, "disk full", statuses["broken"].LastError)
+		assert.False(t, statuses["broken"].Running)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		eng := engine.NewEngine()
+		started := registerWait(eng)
+		task := &ScheduledTask{Name: "slow", Spec: "1h", Timeout: 50 * time.Millisecond, Body: []*engine.Node{{Name: "test.wait"}}}
+		ran, err := RunTask(ctx, eng, store, task, false)
+		<-started
+		assert.True(t, ran)
+		assert.EqualError(t, err, "scheduled task timed out after 50ms")
+	})
+
+	t.Run("lock held until the body returns", func(t *testing.T) {
+		// Body yang mengabaikan timeout tetap memegang lock sampai benar-benar selesai
+		eng := engine.NewEngine()
+		started := make(chan struct{}, 1)
+		unblock := make(chan struct{})
+		eng.Register("test.hang", func(ctx context.Context, node *engine.Node, scope *engine.Scope) error {
+			started <- struct{}{}
+			<-unblock
+			return errors.New("still stuck")
+		}, engine.SlotMeta{})
+		task := &ScheduledTask{Name: "stuck", Spec: "1h", Timeout: 50 * time.Millisecond, Body: []*engine.Node{{Name: "test.hang"}}}
+
+		result := make(chan error, 1)
+		go func() {
+			_, err := RunTask(ctx, eng, store, task, false)
+			result <- err
+		}()
+		<-started
+		time.Sleep(200 * time.Millisecond)
+		locked, err := store.Start(ctx, "stuck", "other", false)
+		assert.NoError(t, err)
+		assert.False(t, locked, "the next run must be skipped while the timed-out body is still running")
+
+		close(unblock)
+		assert.EqualError(t, <-result, "scheduled task timed out after 50ms")
+		statuses, err := store.Status(ctx)
+		assert.NoError(t, err)
+		assert.False(t, statuses["stuck"].Running)
+	})
+}
+
+func TestScheduler(t *testing.T) {
+	store := newTestScheduleStore(t)
+	interval := SchedulerInterval
+	SchedulerInterval = 10 * time.Millisecond
+	t.Cleanup(func() { SchedulerInterval = interval })
+
+	eng, records, _ := newTestEngine(t)
+	tasks := NewScheduledTasks()
+	assert.NoError(t, tasks.Define(&ScheduledTask{
+		Name:     "every 1s",
+		Spec:     "1s",
+		Schedule: Every(time.Second),
+		Body:     []*engine.Node{{Name: "test.record", Value: "$schedule_name"}},
+	}))
+
+	// Dua instance berbagi store yang sama: setiap tick hanya dijalankan sekali
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			RunScheduler(ctx, eng, tasks, store)
+		}()
+	}
+	start := time.Now()
+	time.Sleep(2500 * time.Millisecond)
+	cancel()
+	wg.Wait()
+
+	ticks := int(time.Since(start) / time.Second)
+	runs := len(records())
+	assert.GreaterOrEqual(t, runs, 1)
+	assert.LessOrEqual(t, runs, ticks+1)
+}
@@ -0,0 +1,182 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/nextcore/zeno-go/pkg/engine"
+)
+
+// SchedulerInterval adalah seberapa sering scheduler memeriksa tugas yang jatuh tempo
+var SchedulerInterval = time.Second
+
+// errSchedulerStopped membatalkan run yang masih berjalan setelah masa tenggang shutdown
+var errSchedulerStopped = errors.New("scheduler stopped before the task finished")
+
+// RunScheduler menjalankan tugas terjadwal sampai ctx dibatalkan, lalu menunggu run yang sedang
+// berjalan paling lama DefaultShutdownGrace. Instance yang berbagi store menjalankan setiap tick sekali saja.
+// Tick yang terlewat saat tidak ada instance yang hidup tidak dikejar.
+func RunScheduler(ctx context.Context, eng *engine.Engine, tasks *ScheduledTasks, store *ScheduleStore) {
+	if tasks == nil || store == nil {
+		slog.Info("🚫 Scheduler disabled: no schedule store available")
+		return
+	}
+	slog.Info("⏰ Scheduler Started", "tasks", len(tasks.List()))
+
+	// Run tidak terikat ke ctx, agar shutdown menunggu run selesai dulu
+	runCtx, cancelRuns := context.WithCancelCause(context.Background())
+	defer cancelRuns(nil)
+	var wg sync.WaitGroup
+
+	// Waktu run berikutnya per tugas. Kuncinya memuat jadwal, sehingga tugas yang jadwalnya
+	// diubah saat main.zl dimuat ulang dihitung dari awal.
+	next := make(map[string]time.Time)
+
+	ticker := time.NewTicker(SchedulerInterval)
+	defer ticker.Stop()
+	for {
+		now := time.Now()
+		current := make(map[string]time.Time, len(next))
+		for _, task := range tasks.List() {
+			key := task.Name + "\x00" + task.Spec
+			at, seen := next[key]
+			if !seen {
+				at = task.Schedule.Next(now)
+			}
+			if !at.IsZero() && !now.Before(at) {
+				wg.Add(1)
+				go func(task *ScheduledTask, tick time.Time) {
+					defer wg.Done()
+					runTick(runCtx, eng, store, task, tick)
+				}(task, at)
+				at = task.Schedule.Next(now)
+			}
+			current[key] = at
+		}
+		next = current
+
+		select {
+		case <-ctx.Done():
+			slog.Info("⏰ Scheduler Stopping... Waiting for running tasks to finish", "grace", DefaultShutdownGrace)
+			finished := make(chan struct{})
+			go func() {
+				wg.Wait()
+				close(finished)
+			}()
+			select {
+			case <-finished:
+			case <-time.After(DefaultShutdownGrace):
+				slog.Warn("⏱️  Shutdown grace period over; cancelling running scheduled tasks")
+				cancelRuns(errSchedulerStopped)
+				<-finished
+			}
+			slog.Info("⏰ Scheduler Fully Stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runTick menjalankan tugas untuk tick tersebut bila instance ini yang berhasil meng-claim-nya
+func runTick(ctx context.Context, eng *engine.Engine, store *ScheduleStore, task *ScheduledTask, tick time.Time) {
+	claimed, err := store.ClaimTick(ctx, task.Name, tick)
+	if err != nil {
+		slog.Error("❌ Failed to claim scheduled task", "task", task.Name, "error", err)
+		return
+	}
+	if !claimed {
+		slog.Debug("Scheduled task already run by another instance", "task", task.Name, "tick", tick)
+		return
+	}
+	RunTask(ctx, eng, store, task, false)
+}
+
+// RunTask menjalankan tugas sekarang. Tanpa Overlap, run dilewati (false) selama run sebelumnya
+// masih memegang lock; force mengabaikan lock tersebut (schedule:run --force).
+func RunTask(ctx context.Context, eng *engine.Engine, store *ScheduleStore, task *ScheduledTask, force bool) (bool, error) {
+	owner := lockOwner()
+	started, err := store.Start(ctx, task.Name, owner, task.Overlap || force)
+	if err != nil {
+		slog.Error("❌ Failed to lock scheduled task", "task", task.Name, "error", err)
+		return false, err
+	}
+	if !started {
+		slog.Warn("⏭️  Scheduled task skipped: the previous run is still going", "task", task.Name)
+		return false, nil
+	}
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		scheduleHeartbeat(store, task.Name, owner, done)
+	}()
+
+	start := time.Now()
+	runErr := executeTask(ctx, eng, task)
+	close(done)
+	<-stopped
+
+	if err := store.Finish(context.Background(), task.Name, owner, time.Since(start), runErr); err != nil {
+		slog.Error("❌ Failed to record scheduled task result", "task", task.Name, "error", err)
+	}
+	return true, runErr
+}
+
+// executeTask menjalankan body tugas dengan batas waktu Timeout
+func executeTask(ctx context.Context, eng *engine.Engine, task *ScheduledTask) error {
+	start := time.Now()
+	slog.Info("⏰ Scheduled task started", "task", task.Name, "schedule", task.Spec)
+
+	if task.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, task.Timeout, fmt.Errorf("scheduled task timed out after %s", task.Timeout))
+		defer cancel()
+	}
+
+	scope := engine.NewScope(nil)
+	scope.Set("schedule_name", task.Name)
+	scope.Set("schedule_started_at", start)
+
+	panicStack, err := runScript(ctx, eng, &engine.Node{Name: "root", Children: task.Body}, scope)
+	if err != nil {
+		attrs := []any{"task", task.Name, "error", err}
+		if panicStack != "" {
+			attrs = append(attrs, "stack", panicStack)
+		}
+		slog.Error("❌ Scheduled task failed", attrs...)
+		return err
+	}
+	slog.Info("✅ Scheduled task completed", "task", task.Name, "duration", time.Since(start))
+	return nil
+}
+
+// scheduleHeartbeat memperpanjang lock setiap sepertiga lease sampai done ditutup
+func scheduleHeartbeat(store *ScheduleStore, name, owner string, done <-chan struct{}) {
+	ticker := time.NewTicker(ScheduleLockLease / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if err := store.Heartbeat(context.Background(), name, owner); err != nil {
+				slog.Error("❌ Scheduled task heartbeat failed", "task", name, "error", err)
+			}
+		}
+	}
+}
+
+var lockSeq atomic.Int64
+
+// lockOwner mengidentifikasi satu run: host, proses dan nomor urut
+func lockOwner() string {
+	host, _ := os.Hostname()
+	return fmt.Sprintf("%s:%d:%d", host, os.Getpid(), lockSeq.Add(1))
+}
@@ -0,0 +1,253 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/nextcore/zenoengine/pkg/dbmanager"
+)
+
+// queueColumn adalah satu kolom tabel queue dengan tipe per dialect
+type queueColumn struct {
+	Name string
+	Type string // Tipe generik: id, key (primary key string), string, text, int, bigint, datetime
+	Null bool
+	// Default dipakai saat kolom ditambahkan ke tabel lama
+	Default string
+}
+
+// jobsColumns adalah struktur tabel jobs. Kolom setelah processed_at ditambahkan
+// ke tabel dari versi lama (id, queue, payload, status, created_at, processed_at).
+var jobsColumns = []queueColumn{
+	{Name: "id", Type: "id"},
+	{Name: "queue", Type: "string"},
+	{Name: "payload", Type: "text"},
+	{Name: "status", Type: "string", Default: "'pending'"},
+	{Name: "created_at", Type: "datetime", Null: true},
+	{Name: "processed_at", Type: "datetime", Null: true},
+	{Name: "attempts", Type: "int", Default: "0"},
+	{Name: "max_tries", Type: "int", Default: "1"},
+	{Name: "backoff", Type: "string", Null: true},
+	{Name: "timeout", Type: "int", Default: "0"},
+	{Name: "available_at", Type: "bigint", Default: "0"}, // Unix timestamp (detik)
+	{Name: "last_error", Type: "text", Null: true},
+	{Name: "reserved_until", Type: "bigint", Default: "0"}, // Unix timestamp, 0 = tanpa reservasi
+	{Name: "unique_key", Type: "string", Null: true},
+	{Name: "rate_key", Type: "string", Null: true},
+	{Name: "cancelled", Type: "int", Default: "0"}, // 1 = dibatalkan saat berjalan, lihat DBQueue.Cancel
+}
+
+// queueIndex adalah index tambahan sebuah tabel queue
+type queueIndex struct {
+	Name    string
+	Columns []string
+}
+
+// jobsIndexes mempercepat query claim (status, queue, available_at) saat tabel jobs besar
+var jobsIndexes = []queueIndex{
+	{Name: "jobs_status_queue_available_at_index", Columns: []string{"status", "queue", "available_at"}},
+}
+
+// failedJobsColumns menyimpan job yang kehabisan percobaan, beserta opsi aslinya untuk queue:retry
+var failedJobsColumns = []queueColumn{
+	{Name: "id", Type: "id"},
+	{Name: "queue", Type: "string"},
+	{Name: "payload", Type: "text"},
+	{Name: "attempts", Type: "int", Default: "0"},
+	{Name: "max_tries", Type: "int", Default: "1"},
+	{Name: "backoff", Type: "string", Null: true},
+	{Name: "timeout", Type: "int", Default: "0"},
+	{Name: "error", Type: "text", Null: true},
+	{Name: "stack", Type: "text", Null: true},
+	{Name: "failed_at", Type: "datetime", Null: true},
+	{Name: "rate_key", Type: "string", Null: true},
+}
+
+// jobLocksColumns mengunci unique_key job. Primary key-nya membuat dua push bersamaan
+// dengan key yang sama tidak bisa sama-sama lolos.
+var jobLocksColumns = []queueColumn{
+	{Name: "unique_key", Type: "key"},
+	{Name: "expires_at", Type: "bigint", Default: "0"}, // Unix timestamp, 0 = sampai job selesai
+}
+
+// jobRateLimitsColumns menghitung job yang di-claim per rate limit dalam window yang sedang berjalan
+var jobRateLimitsColumns = []queueColumn{
+	{Name: "name", Type: "key"},
+	{Name: "window_start", Type: "bigint", Default: "0"}, // Unix milidetik
+	{Name: "claimed", Type: "int", Default: "0"},
+}
+
+func columnSQL(dialect dbmanager.Dialect, c queueColumn) string {
+	name := dialect.QuoteIdentifier(c.Name)
+	if c.Type == "id" {
+		switch dialect.Name() {
+		case "postgres":
+			return name + " BIGSERIAL PRIMARY KEY"
+		case "mysql":
+			return name + " BIGINT AUTO_INCREMENT PRIMARY KEY"
+		case "sqlserver":
+			return name + " BIGINT IDENTITY(1,1) PRIMARY KEY"
+		default:
+			return name + " INTEGER PRIMARY KEY AUTOINCREMENT"
+		}
+	}
+	if c.Type == "key" {
+		// 191 karakter agar index utf8mb4 MySQL lama tetap di bawah 767 byte
+		if dialect.Name() == "sqlserver" {
+			return name + " NVARCHAR(191) NOT NULL PRIMARY KEY"
+		}
+		return name + " VARCHAR(191) NOT NULL PRIMARY KEY"
+	}
+
+	var sqlType string
+	switch c.Type {
+	case "string":
+		sqlType = "VARCHAR(255)"
+		if dialect.Name() == "sqlserver" {
+			sqlType = "NVARCHAR(255)"
+		}
+	case "text":
+		switch dialect.Name() {
+		case "mysql":
+			sqlType = "LONGTEXT"
+		case "sqlserver":
+			sqlType = "NVARCHAR(MAX)"
+		default:
+			sqlType = "TEXT"
+		}
+	case "int":
+		sqlType = "INTEGER"
+	case "bigint":
+		sqlType = "BIGINT"
+	case "datetime":
+		switch dialect.Name() {
+		case "postgres":
+			sqlType = "TIMESTAMP"
+		case "sqlserver":
+			sqlType = "DATETIME2"
+		default:
+			sqlType = "DATETIME"
+		}
+	}
+
+	def := name + " " + sqlType
+	if c.Default != "" {
+		def += " DEFAULT " + c.Default
+	}
+	if c.Null {
+		def += " NULL"
+	} else {
+		def += " NOT NULL"
+	}
+	return def
+}
+
+// ensureQueueTable membuat tabel bila belum ada, menambahkan kolom yang belum dimiliki tabel lama,
+// lalu membuat index yang belum ada
+func ensureQueueTable(ctx context.Context, db *sql.DB, dialect dbmanager.Dialect, table string, columns []queueColumn, indexes ...queueIndex) error {
+	if err := ensureQueueColumns(ctx, db, dialect, table, columns); err != nil {
+		return err
+	}
+	for _, idx := range indexes {
+		if err := ensureQueueIndex(ctx, db, dialect, table, idx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func ensureQueueColumns(ctx context.Context, db *sql.DB, dialect dbmanager.Dialect, table string, columns []queueColumn) error {
+	existing, err := tableColumns(ctx, db, dialect, table)
+	if err != nil {
+		defs := make([]string, len(columns))
+		for i, c := range columns {
+			defs[i] = columnSQL(dialect, c)
+		}
+		query := fmt.Sprintf("CREATE TABLE %s (%s)", dialect.QuoteIdentifier(table), strings.Join(defs, ", "))
+		if _, err := db.ExecContext(ctx, query); err != nil {
+			// Proses lain bisa saja membuat tabel yang sama di saat bersamaan
+			if _, errCheck := tableColumns(ctx, db, dialect, table); errCheck == nil {
+				return nil
+			}
+			return fmt.Errorf("failed to create %s table: %w", table, err)
+		}
+		return nil
+	}
+
+	add := "ADD COLUMN"
+	if dialect.Name() == "sqlserver" {
+		add = "ADD"
+	}
+	for _, c := range columns {
+		if existing[c.Name] {
+			continue
+		}
+		query := fmt.Sprintf("ALTER TABLE %s %s %s", dialect.QuoteIdentifier(table), add, columnSQL(dialect, c))
+		if _, err := db.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("failed to add column %s.%s: %w", table, c.Name, err)
+		}
+	}
+	return nil
+}
+
+// ensureQueueIndex membuat index bila belum ada. MySQL dan SQL Server tidak mengenal
+// CREATE INDEX IF NOT EXISTS, sehingga keberadaannya diperiksa lebih dulu.
+func ensureQueueIndex(ctx context.Context, db *sql.DB, dialect dbmanager.Dialect, table string, idx queueIndex) error {
+	cols := make([]string, len(idx.Columns))
+	for i, c := range idx.Columns {
+		cols[i] = dialect.QuoteIdentifier(c)
+	}
+	target := fmt.Sprintf("%s ON %s (%s)", dialect.QuoteIdentifier(idx.Name), dialect.QuoteIdentifier(table), strings.Join(cols, ", "))
+
+	var exists string
+	switch dialect.Name() {
+	case "mysql":
+		exists = fmt.Sprintf("SELECT COUNT(*) FROM information_schema.statistics WHERE table_schema = DATABASE() AND table_name = %s AND index_name = %s",
+			dialect.Placeholder(1), dialect.Placeholder(2))
+	case "sqlserver":
+		exists = fmt.Sprintf("SELECT COUNT(*) FROM sys.indexes WHERE object_id = OBJECT_ID(%s) AND name = %s",
+			dialect.Placeholder(1), dialect.Placeholder(2))
+	default:
+		if _, err := db.ExecContext(ctx, "CREATE INDEX IF NOT EXISTS "+target); err != nil {
+			return fmt.Errorf("failed to create index %s: %w", idx.Name, err)
+		}
+		return nil
+	}
+
+	var n int
+	if err := db.QueryRowContext(ctx, exists, table, idx.Name).Scan(&n); err != nil {
+		return fmt.Errorf("failed to check index %s: %w", idx.Name, err)
+	}
+	if n > 0 {
+		return nil
+	}
+	if _, err := db.ExecContext(ctx, "CREATE INDEX "+target); err != nil {
+		// Proses lain bisa saja membuat index yang sama di saat bersamaan
+		if errCheck := db.QueryRowContext(ctx, exists, table, idx.Name).Scan(&n); errCheck == nil && n > 0 {
+			return nil
+		}
+		return fmt.Errorf("failed to create index %s: %w", idx.Name, err)
+	}
+	return nil
+}
+
+// tableColumns mengembalikan nama kolom tabel (lowercase); error berarti tabel belum ada
+func tableColumns(ctx context.Context, db *sql.DB, dialect dbmanager.Dialect, table string) (map[string]bool, error) {
+	rows, err := db.QueryContext(ctx, fmt.Sprintf("SELECT * FROM %s WHERE 1 = 0", dialect.QuoteIdentifier(table)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]bool, len(cols))
+	for _, c := range cols {
+		result[strings.ToLower(c)] = true
+	}
+	return result, nil
+}
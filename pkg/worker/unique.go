@@ -0,0 +1,95 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/nextcore/zenoengine/pkg/dbmanager"
+)
+
+// pushUnique menyimpan job bersama lock unique_key-nya dalam satu transaksi.
+// Lock dipegang sampai job selesai, atau selama UniqueFor bila diisi. Dengan ReplaceUnique,
+// job pending yang memegang key dihapus dan job baru mengambil alih lock-nya.
+func (q *DBQueue) pushUnique(ctx context.Context, db *sql.DB, dialect dbmanager.Dialect, opts JobOptions, cols []string, args []interface{}) (int64, error) {
+	now := time.Now()
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if err := releaseStaleLock(ctx, tx, dialect, opts.UniqueKey, now); err != nil {
+		return 0, err
+	}
+
+	if opts.ReplaceUnique {
+		// Job yang sudah berjalan dibiarkan selesai; hanya yang belum diambil worker yang diganti
+		query := fmt.Sprintf("DELETE FROM %s WHERE %s = %s AND %s = %s",
+			dialect.QuoteIdentifier("jobs"),
+			dialect.QuoteIdentifier("unique_key"), dialect.Placeholder(1),
+			dialect.QuoteIdentifier("status"), dialect.Placeholder(2))
+		if _, err := tx.ExecContext(ctx, query, opts.UniqueKey, "pending"); err != nil {
+			return 0, err
+		}
+		query = fmt.Sprintf("DELETE FROM %s WHERE %s = %s",
+			dialect.QuoteIdentifier("job_locks"), dialect.QuoteIdentifier("unique_key"), dialect.Placeholder(1))
+		if _, err := tx.ExecContext(ctx, query, opts.UniqueKey); err != nil {
+			return 0, err
+		}
+	}
+
+	var expiresAt int64
+	if opts.UniqueFor > 0 {
+		expiresAt = now.Add(opts.UniqueFor).Unix()
+	}
+	if err := insertRow(ctx, tx, dialect, "job_locks", []string{"unique_key", "expires_at"}, []interface{}{opts.UniqueKey, expiresAt}); err != nil {
+		// Primary key dilanggar: key masih dipegang job lain (atau baru saja diambil proses lain)
+		tx.Rollback()
+		if locked, errCheck := uniqueLocked(ctx, db, dialect, opts.UniqueKey); errCheck == nil && locked {
+			return 0, ErrDuplicateJob
+		}
+		return 0, err
+	}
+	id, err := insertRowID(ctx, tx, dialect, "jobs", cols, args)
+	if err != nil {
+		return 0, err
+	}
+	return id, tx.Commit()
+}
+
+// staleLockCond adalah kondisi lock yang sudah tidak berlaku: UniqueFor-nya lewat, atau
+// tanpa UniqueFor dan tidak ada lagi job dengan key tersebut (selesai atau pindah ke failed_jobs)
+func staleLockCond(dialect dbmanager.Dialect, a *sqlArgs, now time.Time) string {
+	locks, jobs := dialect.QuoteIdentifier("job_locks"), dialect.QuoteIdentifier("jobs")
+	return fmt.Sprintf("((%s > 0 AND %s <= %s) OR (%s = 0 AND NOT EXISTS (SELECT 1 FROM %s WHERE %s.%s = %s.%s)))",
+		dialect.QuoteIdentifier("expires_at"), dialect.QuoteIdentifier("expires_at"), a.add(now.Unix()),
+		dialect.QuoteIdentifier("expires_at"),
+		jobs, jobs, dialect.QuoteIdentifier("unique_key"), locks, dialect.QuoteIdentifier("unique_key"))
+}
+
+func releaseStaleLock(ctx context.Context, tx *sql.Tx, dialect dbmanager.Dialect, key string, now time.Time) error {
+	a := &sqlArgs{dialect: dialect}
+	query := fmt.Sprintf("DELETE FROM %s WHERE %s = %s AND ",
+		dialect.QuoteIdentifier("job_locks"), dialect.QuoteIdentifier("unique_key"), a.add(key)) + staleLockCond(dialect, a, now)
+	_, err := tx.ExecContext(ctx, query, a.values...)
+	return err
+}
+
+func uniqueLocked(ctx context.Context, db *sql.DB, dialect dbmanager.Dialect, key string) (bool, error) {
+	var count int
+	query := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s = %s",
+		dialect.QuoteIdentifier("job_locks"), dialect.QuoteIdentifier("unique_key"), dialect.Placeholder(1))
+	err := db.QueryRowContext(ctx, query, key).Scan(&count)
+	return count > 0, err
+}
+
+// purgeLocks menghapus semua lock yang sudah tidak berlaku, agar key yang tidak pernah
+// dipakai lagi tidak menumpuk di job_locks
+func purgeLocks(ctx context.Context, db *sql.DB, dialect dbmanager.Dialect) error {
+	a := &sqlArgs{dialect: dialect}
+	query := fmt.Sprintf("DELETE FROM %s WHERE ", dialect.QuoteIdentifier("job_locks")) + staleLockCond(dialect, a, time.Now())
+	_, err := db.ExecContext(ctx, query, a.values...)
+	return err
+}